@@ -0,0 +1,365 @@
+// Package anki reads and writes Anki's .apkg deck packages.
+//
+// An .apkg is a zip with a SQLite database (`collection.anki2`, the legacy schema
+// every Anki version can import), a `media` JSON file mapping numbered files
+// to their names, and the numbered media files themselves.
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const ContentType = "application/apkg"
+
+/*
+the note types we export always have the same ids,
+so importing a newer export of the same studyset updates the notes
+instead of making a copy of the note type
+*/
+const (
+	basicModelID         int64 = 1700000000001
+	basicReversedModelID int64 = 1700000000002
+)
+
+// fieldSeparator separates fields in notes.flds
+const fieldSeparator = "\x1f"
+
+// Note is one term, Front & Back are HTML
+type Note struct {
+	Front string
+	Back  string
+	// GUIDSource is hashed into the note's guid, it should be the term's id
+	// so Anki recognizes the same term in a later export
+	GUIDSource string
+	Tags       []string
+}
+
+// Deck is what gets exported, Reversed adds a back->front card for each note
+type Deck struct {
+	// IDSource is hashed into the deck's id, it should be the studyset's id
+	IDSource    string
+	Name        string
+	Description string
+	Reversed    bool
+	Notes       []Note
+}
+
+// StableID makes a positive id that fits in a javascript number from a string,
+// Anki ids are usually millisecond timestamps, so this stays above those
+func StableID(s string) int64 {
+	sum := sha1.Sum([]byte(s))
+	/* 48 bits, plus 1<<48 so it's never 0 or close to a real timestamp */
+	return int64(binary.BigEndian.Uint64(sum[:8])>>16) + (1 << 48)
+}
+
+const guidChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!#$%&()*+,-./:;<=>?@[]^_`{|}~"
+
+func stableGUID(s string) string {
+	sum := sha1.Sum([]byte(s))
+	n := binary.BigEndian.Uint64(sum[:8])
+	var guid strings.Builder
+	for i := 0; i < 10; i++ {
+		guid.WriteByte(guidChars[n%uint64(len(guidChars))])
+		n /= uint64(len(guidChars))
+	}
+	return guid.String()
+}
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTML is what Anki uses for sorting & duplicate checking
+func stripHTML(s string) string {
+	return strings.TrimSpace(htmlTagRegex.ReplaceAllString(s, ""))
+}
+
+// fieldChecksum is the first 8 hex digits of the sha1 of the stripped first field, as a number
+func fieldChecksum(s string) int64 {
+	sum := sha1.Sum([]byte(stripHTML(s)))
+	n, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+	return n
+}
+
+const schema = `
+CREATE TABLE col (
+	id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+	ver integer not null, dty integer not null, usn integer not null, ls integer not null,
+	conf text not null, models text not null, decks text not null, dconf text not null, tags text not null
+);
+CREATE TABLE notes (
+	id integer primary key, guid text not null, mid integer not null, mod integer not null,
+	usn integer not null, tags text not null, flds text not null, sfld integer not null,
+	csum integer not null, flags integer not null, data text not null
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer not null, did integer not null, ord integer not null,
+	mod integer not null, usn integer not null, type integer not null, queue integer not null,
+	due integer not null, ivl integer not null, factor integer not null, reps integer not null,
+	lapses integer not null, left integer not null, odue integer not null, odid integer not null,
+	flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+	ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null,
+	type integer not null
+);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn on notes (usn);
+CREATE INDEX ix_cards_usn on cards (usn);
+CREATE INDEX ix_revlog_usn on revlog (usn);
+CREATE INDEX ix_cards_nid on cards (nid);
+CREATE INDEX ix_cards_sched on cards (did, queue, due);
+CREATE INDEX ix_revlog_cid on revlog (cid);
+CREATE INDEX ix_notes_csum on notes (csum);
+`
+
+const css = `.card {
+	font-family: arial;
+	font-size: 20px;
+	text-align: center;
+	color: black;
+	background-color: white;
+}`
+
+func template(name string, ord int, front string, back string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  name,
+		"ord":   ord,
+		"qfmt":  "{{" + front + "}}",
+		"afmt":  "{{FrontSide}}\n\n<hr id=answer>\n\n{{" + back + "}}",
+		"did":   nil,
+		"bqfmt": "",
+		"bafmt": "",
+	}
+}
+
+func field(name string, ord int) map[string]interface{} {
+	return map[string]interface{}{
+		"name":   name,
+		"ord":    ord,
+		"sticky": false,
+		"rtl":    false,
+		"font":   "Arial",
+		"size":   20,
+		"media":  []string{},
+	}
+}
+
+func model(id int64, deckID int64, reversed bool, now int64) map[string]interface{} {
+	name := "Quizfreely Basic"
+	tmpls := []interface{}{template("Card 1", 0, "Front", "Back")}
+	req := []interface{}{[]interface{}{0, "any", []int{0}}}
+	if reversed {
+		name = "Quizfreely Basic (and reversed card)"
+		tmpls = append(tmpls, template("Card 2", 1, "Back", "Front"))
+		req = append(req, []interface{}{1, "any", []int{1}})
+	}
+	return map[string]interface{}{
+		"id":        id,
+		"name":      name,
+		"type":      0,
+		"mod":       now,
+		"usn":       -1,
+		"sortf":     0,
+		"did":       deckID,
+		"tmpls":     tmpls,
+		"flds":      []interface{}{field("Front", 0), field("Back", 1)},
+		"css":       css,
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"req":       req,
+		"tags":      []string{},
+		"vers":      []interface{}{},
+	}
+}
+
+func deck(id int64, name string, description string, now int64) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        id,
+		"name":      name,
+		"desc":      description,
+		"mod":       now,
+		"usn":       -1,
+		"lrnToday":  []int{0, 0},
+		"revToday":  []int{0, 0},
+		"newToday":  []int{0, 0},
+		"timeToday": []int{0, 0},
+		"collapsed": false,
+		"dyn":       0,
+		"conf":      1,
+		"extendNew": 10,
+		"extendRev": 50,
+	}
+}
+
+const defaultDeckConfig = `{"1": {"id": 1, "name": "Default", "replayq": true, "timer": 0, "maxTaken": 60,
+"usn": 0, "mod": 0, "autoplay": true,
+"lapse": {"leechFails": 8, "minInt": 1, "delays": [10], "leechAction": 0, "mult": 0},
+"rev": {"perDay": 100, "fuzz": 0.05, "ivlFct": 1, "maxIvl": 36500, "ease4": 1.3, "bury": true, "minSpace": 1},
+"new": {"perDay": 20, "delays": [1, 10], "separate": true, "ints": [1, 4, 7], "initialFactor": 2500, "bury": true, "order": 1}}}`
+
+// writeCollection makes collection.anki2 at path
+func writeCollection(ctx context.Context, path string, d Deck) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to create anki collection: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create anki collection: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("failed to create anki collection: %w", err)
+	}
+
+	now := time.Now()
+	nowMillis := now.UnixMilli()
+	deckID := StableID("deck:" + d.IDSource)
+	modelID := basicModelID
+	if d.Reversed {
+		modelID = basicReversedModelID
+	}
+
+	models, _ := json.Marshal(map[string]interface{}{
+		strconv.FormatInt(modelID, 10): model(modelID, deckID, d.Reversed, now.Unix()),
+	})
+	decks, _ := json.Marshal(map[string]interface{}{
+		"1":                           deck(1, "Default", "", now.Unix()),
+		strconv.FormatInt(deckID, 10): deck(deckID, d.Name, d.Description, now.Unix()),
+	})
+	conf, _ := json.Marshal(map[string]interface{}{
+		"nextPos":       len(d.Notes) + 1,
+		"estTimes":      true,
+		"activeDecks":   []int64{1},
+		"sortType":      "noteFld",
+		"timeLim":       0,
+		"sortBackwards": false,
+		"addToCur":      true,
+		"curDeck":       1,
+		"newSpread":     0,
+		"dueCounts":     true,
+		"curModel":      strconv.FormatInt(modelID, 10),
+		"collapseTime":  1200,
+	})
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		now.Unix(), nowMillis, nowMillis,
+		string(conf), string(models), string(decks), defaultDeckConfig,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write anki collection: %w", err)
+	}
+
+	noteStmt, err := tx.PrepareContext(ctx, `INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')`)
+	if err != nil {
+		return fmt.Errorf("failed to write anki notes: %w", err)
+	}
+	defer noteStmt.Close()
+	cardStmt, err := tx.PrepareContext(ctx, `INSERT INTO cards VALUES (?, ?, ?, ?, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')`)
+	if err != nil {
+		return fmt.Errorf("failed to write anki cards: %w", err)
+	}
+	defer cardStmt.Close()
+
+	/* note & card ids are millisecond timestamps in anki, they just have to be unique */
+	nextID := nowMillis
+	for i, n := range d.Notes {
+		noteID := nextID
+		nextID++
+		tags := ""
+		if len(n.Tags) > 0 {
+			tags = " " + strings.Join(n.Tags, " ") + " "
+		}
+		_, err := noteStmt.ExecContext(
+			ctx,
+			noteID, stableGUID(n.GUIDSource), modelID, now.Unix(), tags,
+			n.Front+fieldSeparator+n.Back, stripHTML(n.Front), fieldChecksum(n.Front),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write anki note: %w", err)
+		}
+
+		ords := 1
+		if d.Reversed {
+			ords = 2
+		}
+		for ord := 0; ord < ords; ord++ {
+			/* new cards are due in the order of their position */
+			if _, err := cardStmt.ExecContext(ctx, nextID, noteID, deckID, ord, now.Unix(), i+1); err != nil {
+				return fmt.Errorf("failed to write anki card: %w", err)
+			}
+			nextID++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write anki collection: %w", err)
+	}
+	return nil
+}
+
+// WriteAPKG writes d as an .apkg package to w.
+// The collection is built in a temporary file first, cause sqlite needs one,
+// then zipped straight into w.
+func WriteAPKG(ctx context.Context, w io.Writer, d Deck) error {
+	tmp, err := os.CreateTemp("", "quizfreely-export-*.anki2")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+	/* sqlite can leave a journal next to the database */
+	defer os.Remove(tmpPath + "-journal")
+
+	if err := writeCollection(ctx, tmpPath, d); err != nil {
+		return err
+	}
+
+	collection, err := os.Open(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to read anki collection: %w", err)
+	}
+	defer collection.Close()
+
+	zw := zip.NewWriter(w)
+	collectionWriter, err := zw.Create("collection.anki2")
+	if err != nil {
+		return fmt.Errorf("failed to write apkg: %w", err)
+	}
+	if _, err := io.Copy(collectionWriter, collection); err != nil {
+		return fmt.Errorf("failed to write apkg: %w", err)
+	}
+	/* images are linked by url instead of included, so there's no media */
+	mediaWriter, err := zw.Create("media")
+	if err != nil {
+		return fmt.Errorf("failed to write apkg: %w", err)
+	}
+	if _, err := mediaWriter.Write([]byte("{}")); err != nil {
+		return fmt.Errorf("failed to write apkg: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write apkg: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package anki
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	soundRegex      = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	blankLinesRegex = regexp.MustCompile(`\n{2,}`)
+	spaceRegex      = regexp.MustCompile(`\s+`)
+	markdownSpecial = strings.NewReplacer(
+		`\`, `\\`, `*`, `\*`, `_`, `\_`, `~`, `\~`, `^`, `\^`, "`", "\\`",
+	)
+)
+
+// FieldToMarkdown converts an Anki field's HTML into the markdown terms use
+// (see package markdown), formatting it doesn't support is dropped but its text is kept.
+// It also returns the filenames of images in the field, in order.
+func FieldToMarkdown(field string) (string, []string) {
+	var out strings.Builder
+	var images []string
+
+	newline := func() {
+		s := out.String()
+		if s != "" && !strings.HasSuffix(s, "\n") {
+			out.WriteString("\n")
+		}
+	}
+
+	skipDepth := 0
+	inCode := false
+	z := html.NewTokenizer(strings.NewReader(field))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		tag := tok.Data
+
+		switch tt {
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			text := soundRegex.ReplaceAllString(tok.Data, "")
+			/* line breaks in html source are just spaces */
+			text = spaceRegex.ReplaceAllString(strings.ReplaceAll(text, "\u00a0", " "), " ")
+			if !inCode {
+				text = markdownSpecial.Replace(text)
+			}
+			out.WriteString(text)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tag {
+			case "script", "style":
+				if tt == html.StartTagToken {
+					skipDepth++
+				}
+			case "b", "strong":
+				out.WriteString("**")
+			case "i", "em":
+				out.WriteString("*")
+			case "sub":
+				out.WriteString("~")
+			case "sup":
+				out.WriteString("^")
+			case "code":
+				out.WriteString("`")
+				inCode = true
+			case "br":
+				out.WriteString("\n")
+			case "div", "p", "ul", "ol", "tr", "h1", "h2", "h3", "h4", "h5", "h6":
+				newline()
+			case "li":
+				newline()
+				out.WriteString("- ")
+			case "img":
+				for _, attr := range tok.Attr {
+					if attr.Key == "src" && attr.Val != "" {
+						images = append(images, attr.Val)
+					}
+				}
+			}
+		case html.EndTagToken:
+			switch tag {
+			case "script", "style":
+				if skipDepth > 0 {
+					skipDepth--
+				}
+			case "b", "strong":
+				out.WriteString("**")
+			case "i", "em":
+				out.WriteString("*")
+			case "sub":
+				out.WriteString("~")
+			case "sup":
+				out.WriteString("^")
+			case "code":
+				out.WriteString("`")
+				inCode = false
+			case "div", "p", "li", "tr", "h1", "h2", "h3", "h4", "h5", "h6":
+				newline()
+			}
+		}
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text := blankLinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n")
+	return strings.TrimSpace(text), images
+}
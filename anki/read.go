@@ -0,0 +1,455 @@
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// MaxPackageBytes is the biggest .apkg that can be imported
+	MaxPackageBytes = 100 << 20
+	// maxCollectionBytes limits the unzipped collection,
+	// so a small zip can't fill up the disk
+	maxCollectionBytes = 512 << 20
+	// MaxMediaBytes is the biggest media file that'll be read from a package
+	MaxMediaBytes = 10 << 20
+	// DefaultMaxNotes is how many notes are read when ReadOptions.MaxNotes isn't set
+	DefaultMaxNotes = 10000
+)
+
+var (
+	ErrNotAPKG    = errors.New("file is not an anki package (.apkg)")
+	ErrTooLarge   = errors.New("anki package is too large")
+	ErrNoSuchFile = errors.New("media file not found in anki package")
+)
+
+// ReadOptions configures ReadAPKG
+type ReadOptions struct {
+	SkipMedia bool
+	MaxNotes  int
+}
+
+// ImportedNote is a note's first two fields converted to markdown (see FieldToMarkdown),
+// FrontImage & BackImage are the first image in each field, unless media was skipped
+type ImportedNote struct {
+	ID         int64
+	Front      string
+	Back       string
+	FrontImage string
+	BackImage  string
+}
+
+// ImportedDeck is a deck and the notes in it, Name is the full path like "Spanish::Unit 1"
+type ImportedDeck struct {
+	ID    int64
+	Name  string
+	Notes []ImportedNote
+}
+
+// SkippedNote is a note that couldn't be imported and why
+type SkippedNote struct {
+	ID     int64
+	Deck   string
+	Reason string
+}
+
+// Package is a read .apkg, its decks are sorted by name
+// and only include decks that have notes
+type Package struct {
+	Decks   []*ImportedDeck
+	Skipped []SkippedNote
+	// MediaUnreadable is true when the package has media in a format that can't be read
+	MediaUnreadable bool
+
+	zr    *zip.Reader
+	media map[string]string
+}
+
+// Media reads a media file from the package by the name notes use for it
+func (p *Package) Media(name string) ([]byte, error) {
+	entryName, ok := p.media[name]
+	if !ok {
+		return nil, ErrNoSuchFile
+	}
+	f, err := p.zr.Open(entryName)
+	if err != nil {
+		return nil, ErrNoSuchFile
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, MaxMediaBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media file: %w", err)
+	}
+	if len(data) > MaxMediaBytes {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+type noteType struct {
+	name  string
+	cloze bool
+}
+
+// ReadAPKG reads the decks & notes in an .apkg.
+// Newer Anki versions export a zstd compressed `collection.anki21b` with the newer schema,
+// older ones export `collection.anki21` or `collection.anki2` with the legacy schema,
+// both are supported.
+func ReadAPKG(ctx context.Context, r io.ReaderAt, size int64, opts ReadOptions) (*Package, error) {
+	if size > MaxPackageBytes {
+		return nil, ErrTooLarge
+	}
+	if opts.MaxNotes <= 0 {
+		opts.MaxNotes = DefaultMaxNotes
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrNotAPKG
+	}
+
+	path, err := extractCollection(zr)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open anki collection: %w", err)
+	}
+	defer db.Close()
+
+	var newSchema bool
+	err = db.QueryRowContext(
+		ctx,
+		"SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'notetypes'",
+	).Scan(&newSchema)
+	if err != nil {
+		return nil, ErrNotAPKG
+	}
+
+	var noteTypes map[int64]*noteType
+	var deckNames map[int64]string
+	if newSchema {
+		noteTypes, deckNames, err = readNewSchemaTypes(ctx, db)
+	} else {
+		noteTypes, deckNames, err = readLegacyTypes(ctx, db)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &Package{zr: zr}
+	if !opts.SkipMedia {
+		pkg.media, pkg.MediaUnreadable = readMediaMap(zr)
+	}
+
+	/* a note's deck is the deck of its first card */
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT n.id, n.mid, n.flds,
+	COALESCE((SELECT c.did FROM cards c WHERE c.nid = n.id ORDER BY c.ord LIMIT 1), 1)
+FROM notes n
+ORDER BY n.id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anki notes: %w", err)
+	}
+	defer rows.Close()
+
+	decks := make(map[int64]*ImportedDeck)
+	count := 0
+	for rows.Next() {
+		var id, mid, did int64
+		var flds string
+		if err := rows.Scan(&id, &mid, &flds, &did); err != nil {
+			return nil, fmt.Errorf("failed to read anki notes: %w", err)
+		}
+
+		deckName, ok := deckNames[did]
+		if !ok {
+			deckName = "Default"
+		}
+		skip := func(reason string) {
+			pkg.Skipped = append(pkg.Skipped, SkippedNote{ID: id, Deck: deckName, Reason: reason})
+		}
+
+		if count >= opts.MaxNotes {
+			skip(fmt.Sprintf("only the first %d notes can be imported at once", opts.MaxNotes))
+			continue
+		}
+		nt, ok := noteTypes[mid]
+		switch {
+		case !ok:
+			skip("note type is missing")
+			continue
+		case nt.cloze:
+			skip(fmt.Sprintf("cloze note type %q isn't supported", nt.name))
+			continue
+		}
+		fields := strings.Split(flds, fieldSeparator)
+		if len(fields) < 2 {
+			skip(fmt.Sprintf("note type %q has only one field", nt.name))
+			continue
+		}
+
+		front, frontImages := FieldToMarkdown(fields[0])
+		back, backImages := FieldToMarkdown(fields[1])
+		note := ImportedNote{ID: id, Front: front, Back: back}
+		if !opts.SkipMedia {
+			if len(frontImages) > 0 {
+				note.FrontImage = frontImages[0]
+			}
+			if len(backImages) > 0 {
+				note.BackImage = backImages[0]
+			}
+		}
+		if note.Front == "" && note.Back == "" && note.FrontImage == "" && note.BackImage == "" {
+			skip("note is blank")
+			continue
+		}
+
+		deck, ok := decks[did]
+		if !ok {
+			deck = &ImportedDeck{ID: did, Name: deckName}
+			decks[did] = deck
+		}
+		deck.Notes = append(deck.Notes, note)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read anki notes: %w", err)
+	}
+
+	for _, deck := range decks {
+		pkg.Decks = append(pkg.Decks, deck)
+	}
+	sort.Slice(pkg.Decks, func(i, j int) bool {
+		return pkg.Decks[i].Name < pkg.Decks[j].Name
+	})
+	return pkg, nil
+}
+
+// extractCollection unzips the newest collection in the package to a temporary file
+func extractCollection(zr *zip.Reader) (string, error) {
+	var collection *zip.File
+	compressed := false
+	for _, name := range []string{"collection.anki21b", "collection.anki21", "collection.anki2"} {
+		for _, f := range zr.File {
+			if f.Name == name {
+				collection = f
+				compressed = name == "collection.anki21b"
+				break
+			}
+		}
+		if collection != nil {
+			break
+		}
+	}
+	if collection == nil {
+		return "", ErrNotAPKG
+	}
+
+	rc, err := collection.Open()
+	if err != nil {
+		return "", ErrNotAPKG
+	}
+	defer rc.Close()
+
+	var src io.Reader = rc
+	if compressed {
+		dec, err := zstd.NewReader(rc)
+		if err != nil {
+			return "", ErrNotAPKG
+		}
+		defer dec.Close()
+		src = dec
+	}
+
+	tmp, err := os.CreateTemp("", "quizfreely-import-*.anki2")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	n, err := io.Copy(tmp, io.LimitReader(src, maxCollectionBytes+1))
+	tmp.Close()
+	if err != nil || n > maxCollectionBytes {
+		os.Remove(tmp.Name())
+		if err == nil {
+			return "", ErrTooLarge
+		}
+		return "", ErrNotAPKG
+	}
+	return tmp.Name(), nil
+}
+
+func readLegacyTypes(ctx context.Context, db *sql.DB) (map[int64]*noteType, map[int64]string, error) {
+	var modelsJSON, decksJSON string
+	err := db.QueryRowContext(ctx, "SELECT models, decks FROM col LIMIT 1").Scan(&modelsJSON, &decksJSON)
+	if err != nil {
+		return nil, nil, ErrNotAPKG
+	}
+
+	var models map[string]struct {
+		Name string `json:"name"`
+		Type int    `json:"type"`
+	}
+	var decks map[string]struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal([]byte(modelsJSON), &models) != nil || json.Unmarshal([]byte(decksJSON), &decks) != nil {
+		return nil, nil, ErrNotAPKG
+	}
+
+	noteTypes := make(map[int64]*noteType, len(models))
+	for id, m := range models {
+		idInt, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		noteTypes[idInt] = &noteType{name: m.Name, cloze: m.Type == 1}
+	}
+	deckNames := make(map[int64]string, len(decks))
+	for id, d := range decks {
+		idInt, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		deckNames[idInt] = d.Name
+	}
+	return noteTypes, deckNames, nil
+}
+
+func readNewSchemaTypes(ctx context.Context, db *sql.DB) (map[int64]*noteType, map[int64]string, error) {
+	noteTypes := make(map[int64]*noteType)
+	rows, err := db.QueryContext(
+		ctx,
+		"SELECT id, name, config FROM notetypes",
+	)
+	if err != nil {
+		return nil, nil, ErrNotAPKG
+	}
+	for rows.Next() {
+		var id int64
+		var name string
+		var config []byte
+		if err := rows.Scan(&id, &name, &config); err != nil {
+			rows.Close()
+			return nil, nil, ErrNotAPKG
+		}
+		/* the note type's kind is field 1 of its protobuf config, 1 means cloze */
+		kind, _ := protobufVarint(config, 1)
+		noteTypes[id] = &noteType{name: name, cloze: kind == 1}
+	}
+	rows.Close()
+
+	deckNames := make(map[int64]string)
+	rows, err = db.QueryContext(ctx, "SELECT id, name FROM decks")
+	if err != nil {
+		return nil, nil, ErrNotAPKG
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, nil, ErrNotAPKG
+		}
+		/* the new schema separates deck names with \x1f instead of :: */
+		deckNames[id] = strings.ReplaceAll(name, "\x1f", "::")
+	}
+	return noteTypes, deckNames, rows.Err()
+}
+
+// protobufVarint finds a top-level varint field in a protobuf message,
+// it's just enough protobuf to read a note type's kind
+func protobufVarint(msg []byte, field uint64) (uint64, bool) {
+	readVarint := func() (uint64, bool) {
+		var v uint64
+		for shift := uint(0); shift < 64; shift += 7 {
+			if len(msg) == 0 {
+				return 0, false
+			}
+			b := msg[0]
+			msg = msg[1:]
+			v |= uint64(b&0x7f) << shift
+			if b < 0x80 {
+				return v, true
+			}
+		}
+		return 0, false
+	}
+
+	for len(msg) > 0 {
+		key, ok := readVarint()
+		if !ok {
+			return 0, false
+		}
+		switch key & 7 {
+		case 0:
+			v, ok := readVarint()
+			if !ok {
+				return 0, false
+			}
+			if key>>3 == field {
+				return v, true
+			}
+		case 1:
+			if len(msg) < 8 {
+				return 0, false
+			}
+			msg = msg[8:]
+		case 2:
+			l, ok := readVarint()
+			if !ok || uint64(len(msg)) < l {
+				return 0, false
+			}
+			msg = msg[l:]
+		case 5:
+			if len(msg) < 4 {
+				return 0, false
+			}
+			msg = msg[4:]
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// readMediaMap reads the `media` file, which maps numbered zip entries to filenames.
+// Newer packages use a compressed protobuf for it instead of JSON,
+// those report the media as unreadable and are imported without it.
+func readMediaMap(zr *zip.Reader) (map[string]string, bool) {
+	media := make(map[string]string)
+	f, err := zr.Open("media")
+	if err != nil {
+		/* no media file means no media */
+		return media, false
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, 10<<20))
+	if err != nil {
+		return media, true
+	}
+	var numbered map[string]string
+	if err := json.Unmarshal(data, &numbered); err != nil {
+		return media, true
+	}
+	for entry, name := range numbered {
+		media[name] = entry
+	}
+	return media, false
+}
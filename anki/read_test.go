@@ -0,0 +1,127 @@
+package anki
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// readFixture reads testdata/fixture.apkg, a legacy schema package with:
+//
+//	Spanish::Unit 1  1001 **gato** / cat + cat.png, 1002 perro / *dog* + an extra field
+//	Spanish::Unit 2  1003 big.png (over MaxMediaBytes) / huge image,
+//	                 1004 cloze, 1005 one field, 1006 blank, 1007 missing note type
+//	(no cards)       1008 no cards / default deck
+func readFixture(t *testing.T, opts ReadOptions) *Package {
+	t.Helper()
+	data, err := os.ReadFile("testdata/fixture.apkg")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	pkg, err := ReadAPKG(context.Background(), bytes.NewReader(data), int64(len(data)), opts)
+	if err != nil {
+		t.Fatalf("ReadAPKG() error = %v", err)
+	}
+	return pkg
+}
+
+func TestReadAPKG(t *testing.T) {
+	pkg := readFixture(t, ReadOptions{})
+
+	wantDecks := "[" +
+		"{ID:1 Name:Default Notes:[{ID:1008 Front:no cards Back:default deck FrontImage: BackImage:}]} " +
+		"{ID:10 Name:Spanish::Unit 1 Notes:[{ID:1001 Front:**gato** Back:cat FrontImage: BackImage:cat.png} {ID:1002 Front:perro Back:*dog* FrontImage: BackImage:}]} " +
+		"{ID:20 Name:Spanish::Unit 2 Notes:[{ID:1003 Front: Back:huge image FrontImage:big.png BackImage:}]}" +
+		"]"
+	var decks []ImportedDeck
+	for _, deck := range pkg.Decks {
+		decks = append(decks, *deck)
+	}
+	if got := fmt.Sprintf("%+v", decks); got != wantDecks {
+		t.Errorf("ReadAPKG() decks =\n%s\nwant\n%s", got, wantDecks)
+	}
+
+	wantSkipped := []SkippedNote{
+		{1004, "Spanish::Unit 2", `cloze note type "Cloze" isn't supported`},
+		{1005, "Spanish::Unit 2", `note type "Front only" has only one field`},
+		{1006, "Spanish::Unit 2", "note is blank"},
+		{1007, "Spanish::Unit 2", "note type is missing"},
+	}
+	if fmt.Sprint(pkg.Skipped) != fmt.Sprint(wantSkipped) {
+		t.Errorf("ReadAPKG() skipped = %+v, want %+v", pkg.Skipped, wantSkipped)
+	}
+	if pkg.MediaUnreadable {
+		t.Error("ReadAPKG() media is unreadable")
+	}
+}
+
+func TestReadAPKGLimits(t *testing.T) {
+	ctx := context.Background()
+	data, err := os.ReadFile("testdata/fixture.apkg")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if _, err := ReadAPKG(ctx, bytes.NewReader(data), MaxPackageBytes+1, ReadOptions{}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("ReadAPKG() of a package over the limit error = %v, want %v", err, ErrTooLarge)
+	}
+	notZip := []byte("not a zip file")
+	if _, err := ReadAPKG(ctx, bytes.NewReader(notZip), int64(len(notZip)), ReadOptions{}); !errors.Is(err, ErrNotAPKG) {
+		t.Errorf("ReadAPKG() of a non-zip error = %v, want %v", err, ErrNotAPKG)
+	}
+
+	/* once MaxNotes notes are read, the rest are skipped whatever else is wrong with them */
+	pkg := readFixture(t, ReadOptions{MaxNotes: 2})
+	if len(pkg.Decks) != 1 || len(pkg.Decks[0].Notes) != 2 {
+		t.Errorf("ReadAPKG() with MaxNotes 2 = %+v, want the 2 notes in Spanish::Unit 1", pkg.Decks)
+	}
+	if len(pkg.Skipped) != 6 || pkg.Skipped[0].Reason != "only the first 2 notes can be imported at once" {
+		t.Errorf("ReadAPKG() with MaxNotes 2 skipped = %+v, want 6 over the limit", pkg.Skipped)
+	}
+}
+
+func TestPackageMedia(t *testing.T) {
+	pkg := readFixture(t, ReadOptions{})
+	if data, err := pkg.Media("cat.png"); err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Errorf("Media(cat.png) = %d bytes, %v, want a png", len(data), err)
+	}
+	if _, err := pkg.Media("big.png"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Media(big.png) error = %v, want %v", err, ErrTooLarge)
+	}
+	if _, err := pkg.Media("missing.png"); !errors.Is(err, ErrNoSuchFile) {
+		t.Errorf("Media(missing.png) error = %v, want %v", err, ErrNoSuchFile)
+	}
+
+	/* without media, notes don't have images and nothing can be read */
+	pkg = readFixture(t, ReadOptions{SkipMedia: true})
+	if image := pkg.Decks[1].Notes[0].BackImage; image != "" {
+		t.Errorf("ReadAPKG() with SkipMedia kept image %q", image)
+	}
+	if _, err := pkg.Media("cat.png"); !errors.Is(err, ErrNoSuchFile) {
+		t.Errorf("Media(cat.png) with SkipMedia error = %v, want %v", err, ErrNoSuchFile)
+	}
+}
+
+func TestFieldToMarkdown(t *testing.T) {
+	tests := []struct {
+		field      string
+		want       string
+		wantImages []string
+	}{
+		{"plain", "plain", nil},
+		{"<b>bold</b> <i>it</i> H<sub>2</sub>O x<sup>2</sup> <code>a*b</code>", "**bold** *it* H~2~O x^2^ `a*b`", nil},
+		{"a<br>b<div>c</div>", "a\nb\nc", nil},
+		{"<ul><li>one</li><li>two</li></ul>", "- one\n- two", nil},
+		{"2*3 snake_case", `2\*3 snake\_case`, nil},
+		{"word[sound:word.mp3]<script>alert(1)</script>", "word", nil},
+		{`<img src="a.png"> and <img src="b.jpg">`, "and", []string{"a.png", "b.jpg"}},
+	}
+	for _, tt := range tests {
+		got, images := FieldToMarkdown(tt.field)
+		if got != tt.want || fmt.Sprint(images) != fmt.Sprint(tt.wantImages) {
+			t.Errorf("FieldToMarkdown(%q) = %q, %q, want %q, %q", tt.field, got, images, tt.want, tt.wantImages)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Package contentfilter checks public studysets' titles & terms against word lists
+// and spam heuristics, and decides whether to allow them, queue them for review,
+// force them private, or reject them.
+//
+// Word lists are plain text files, one word or phrase per line, in a directory like:
+//
+//	any/reject.txt     checked for every language
+//	en/review.txt      only checked for text in English
+//	es/private.txt
+//	domains.txt        links to these domains are rejected
+//
+// Blank lines and lines starting with # are ignored, and a word ending with *
+// matches every word starting with it. Lists can be loaded from any fs.FS,
+// so they can be checked without a database or network.
+package contentfilter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"quizfreely/api/language"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Action is what should happen to content, from least to most severe
+type Action int
+
+const (
+	Allow Action = iota
+	Review
+	ForcePrivate
+	Reject
+)
+
+func (a Action) String() string {
+	switch a {
+	case Review:
+		return "review"
+	case ForcePrivate:
+		return "private"
+	case Reject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+/* list files are named after the action they cause */
+var listActions = map[string]Action{
+	"review.txt":  Review,
+	"private.txt": ForcePrivate,
+	"reject.txt":  Reject,
+}
+
+// AnyLanguage is the language key of lists that are checked for every language
+const AnyLanguage = "any"
+
+const (
+	DefaultMaxLinks = 3
+	/* a studyset with at least this many terms where most of them are the same is spam */
+	minRepeatedTexts = 10
+	maxRepeatedRun   = 20
+)
+
+var (
+	ErrNoLists = errors.New("content filter has no lists to reload")
+
+	linkRegex = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()]+|\b(?:[a-z0-9-]+\.)+(?:com|net|org|io|co|ru|cn|xyz|top|info|biz|click|link|shop|site|online|live|app|me|ly|gg)\b(?:/[^\s<>()]*)?`)
+	hostRegex = regexp.MustCompile(`(?i)^(?:https?://)?([^/\s:?#]+)`)
+)
+
+// Lists are the word lists & settings a Filter checks content with
+type Lists struct {
+	/* language (a base language like "en", or AnyLanguage) -> action -> words & phrases */
+	Words          map[string]map[Action][]string
+	BlockedDomains []string
+	/* more links than this across a whole studyset gets it reviewed, 0 means DefaultMaxLinks */
+	MaxLinks int
+}
+
+// Text is one piece of content to check, like a title or a term,
+// with the language it's in ("" if unknown)
+type Text struct {
+	Content  string
+	Language string
+}
+
+// Match is one reason content got flagged
+type Match struct {
+	Action Action
+	Rule   string
+	Match  string
+}
+
+// Verdict is the result of checking content, its Action is the most severe of its matches
+type Verdict struct {
+	Action  Action
+	Matches []Match
+}
+
+func (v *Verdict) add(m Match) {
+	v.Matches = append(v.Matches, m)
+	if m.Action > v.Action {
+		v.Action = m.Action
+	}
+}
+
+type wordEntry struct {
+	action Action
+	rule   string
+	/* normalized words, joined by spaces */
+	text   string
+	prefix bool
+}
+
+type compiled struct {
+	/* base language -> entries */
+	words          map[string][]wordEntry
+	blockedDomains []string
+	maxLinks       int
+}
+
+// Filter checks content, it's safe to use from many goroutines while it's being reloaded
+type Filter struct {
+	mu       sync.RWMutex
+	compiled *compiled
+	fsys     fs.FS
+}
+
+// New makes a Filter from lists in memory
+func New(lists Lists) *Filter {
+	return &Filter{compiled: compile(lists)}
+}
+
+// Load makes a Filter from list files (see the package docs),
+// it can be reloaded from the same files with Reload
+func Load(fsys fs.FS) (*Filter, error) {
+	lists, err := readLists(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{compiled: compile(lists), fsys: fsys}, nil
+}
+
+// Reload re-reads the list files the Filter was loaded from,
+// if they can't be read, the Filter keeps using the lists it already had
+func (f *Filter) Reload() error {
+	if f.fsys == nil {
+		return ErrNoLists
+	}
+	lists, err := readLists(f.fsys)
+	if err != nil {
+		return err
+	}
+	c := compile(lists)
+	f.mu.Lock()
+	f.compiled = c
+	f.mu.Unlock()
+	return nil
+}
+
+func readLists(fsys fs.FS) (Lists, error) {
+	lists := Lists{Words: make(map[string]map[Action][]string)}
+
+	domains, err := readListFile(fsys, "domains.txt")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return lists, err
+	}
+	lists.BlockedDomains = domains
+
+	dirs, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return lists, fmt.Errorf("failed to read content filter lists: %w", err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		lang := strings.ToLower(dir.Name())
+		if lang != AnyLanguage {
+			lang = language.Base(lang)
+			if lang == "" {
+				return lists, fmt.Errorf("content filter list directory %q isn't a language", dir.Name())
+			}
+		}
+		for name, action := range listActions {
+			words, err := readListFile(fsys, path.Join(dir.Name(), name))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return lists, err
+			}
+			if lists.Words[lang] == nil {
+				lists.Words[lang] = make(map[Action][]string)
+			}
+			lists.Words[lang][action] = append(lists.Words[lang][action], words...)
+		}
+	}
+	return lists, nil
+}
+
+func readListFile(fsys fs.FS, name string) ([]string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return lines, nil
+}
+
+func compile(lists Lists) *compiled {
+	c := &compiled{
+		words:    make(map[string][]wordEntry),
+		maxLinks: lists.MaxLinks,
+	}
+	if c.maxLinks <= 0 {
+		c.maxLinks = DefaultMaxLinks
+	}
+
+	for lang, byAction := range lists.Words {
+		if lang != AnyLanguage {
+			lang = language.Base(lang)
+		}
+		for action, words := range byAction {
+			rule := fmt.Sprintf("%s/%s", lang, action)
+			for _, w := range words {
+				prefix := strings.HasSuffix(w, "*")
+				normalized := strings.Join(Normalize(strings.TrimSuffix(w, "*")), " ")
+				if normalized == "" {
+					continue
+				}
+				c.words[lang] = append(c.words[lang], wordEntry{
+					action: action,
+					rule:   rule,
+					text:   normalized,
+					prefix: prefix,
+				})
+			}
+		}
+	}
+
+	for _, d := range lists.BlockedDomains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" {
+			c.blockedDomains = append(c.blockedDomains, d)
+		}
+	}
+	return c
+}
+
+// Check checks all of a studyset's content together,
+// so spam spread across many terms is still caught
+func (f *Filter) Check(texts []Text) Verdict {
+	f.mu.RLock()
+	c := f.compiled
+	f.mu.RUnlock()
+
+	var verdict Verdict
+	seen := make(map[Match]bool)
+	add := func(m Match) {
+		if !seen[m] {
+			seen[m] = true
+			verdict.add(m)
+		}
+	}
+
+	links := 0
+	repeated := make(map[string]int)
+	nonEmpty := 0
+	for _, text := range texts {
+		if strings.TrimSpace(text.Content) == "" {
+			continue
+		}
+		nonEmpty++
+
+		words := Normalize(text.Content)
+		joined := strings.Join(words, " ")
+		repeated[joined]++
+
+		lang := language.Base(text.Language)
+		spaceless := language.Spaceless(lang)
+		for _, key := range []string{AnyLanguage, lang} {
+			if key == "" {
+				continue
+			}
+			for _, entry := range c.words[key] {
+				if entryMatches(entry, words, joined, spaceless) {
+					add(Match{Action: entry.action, Rule: entry.rule, Match: entry.text})
+				}
+			}
+		}
+
+		for _, link := range linkRegex.FindAllString(text.Content, -1) {
+			links++
+			host := strings.ToLower(hostRegex.FindStringSubmatch(link)[1])
+			host = strings.TrimPrefix(host, "www.")
+			for _, blocked := range c.blockedDomains {
+				if host == blocked || strings.HasSuffix(host, "."+blocked) {
+					add(Match{Action: Reject, Rule: "blocked domain", Match: blocked})
+				}
+			}
+		}
+
+		if longestRun(text.Content) >= maxRepeatedRun {
+			add(Match{Action: Review, Rule: "repeated characters"})
+		}
+	}
+
+	if links > c.maxLinks {
+		add(Match{Action: Review, Rule: "too many links", Match: fmt.Sprintf("%d links", links)})
+	}
+	if nonEmpty >= minRepeatedTexts {
+		for text, count := range repeated {
+			if text != "" && count*2 > nonEmpty {
+				add(Match{Action: Review, Rule: "repeated content", Match: text})
+			}
+		}
+	}
+
+	sort.SliceStable(verdict.Matches, func(i, j int) bool {
+		return verdict.Matches[i].Action > verdict.Matches[j].Action
+	})
+	return verdict
+}
+
+func entryMatches(entry wordEntry, words []string, joined string, spaceless bool) bool {
+	if spaceless {
+		/* words aren't separated, so any part of the text can be a word */
+		return strings.Contains(strings.ReplaceAll(joined, " ", ""), strings.ReplaceAll(entry.text, " ", ""))
+	}
+	if !strings.Contains(entry.text, " ") {
+		for _, w := range words {
+			if w == entry.text || (entry.prefix && strings.HasPrefix(w, entry.text)) {
+				return true
+			}
+		}
+		return false
+	}
+	/* phrases have to match whole words, so "ass" doesn't match "class" */
+	padded := " " + joined + " "
+	if entry.prefix {
+		return strings.Contains(padded, " "+entry.text)
+	}
+	return strings.Contains(padded, " "+entry.text+" ")
+}
+
+func longestRun(s string) int {
+	longest, run := 0, 0
+	var prev rune
+	for i, r := range s {
+		if i > 0 && r == prev && r != ' ' {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		prev = r
+	}
+	return longest
+}
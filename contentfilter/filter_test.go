@@ -0,0 +1,147 @@
+package contentfilter
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func testLists() fstest.MapFS {
+	return fstest.MapFS{
+		"any/reject.txt": {Data: []byte("# checked for every language\n\nbadword\nscam*\n")},
+		"en/review.txt":  {Data: []byte("free money\n")},
+		"es/private.txt": {Data: []byte("tonto\n")},
+		"ja/reject.txt":  {Data: []byte("バカ\n")},
+		"domains.txt":    {Data: []byte("evil.com\n")},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	filter, err := Load(testLists())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		texts []Text
+		want  Action
+	}{
+		{"clean", []Text{{Content: "the mitochondria is the powerhouse of the cell", Language: "en"}}, Allow},
+		{"empty", []Text{{Content: "   "}}, Allow},
+		{"any language reject", []Text{{Content: "this is a badword", Language: "en"}}, Reject},
+		{"any language reject without a language", []Text{{Content: "badword"}}, Reject},
+		{"reject with confusables", []Text{{Content: "bаdwоrd", Language: "en"}}, Reject},
+		{"reject with leetspeak", []Text{{Content: "b4dw0rd", Language: "en"}}, Reject},
+		{"prefix", []Text{{Content: "scammers everywhere", Language: "en"}}, Reject},
+		{"prefix doesn't match the middle of words", []Text{{Content: "descamisado", Language: "es"}}, Allow},
+		{"english review", []Text{{Content: "get free money now", Language: "en"}}, Review},
+		{"english review with a region", []Text{{Content: "get free money now", Language: "en-US"}}, Review},
+		{"english list isn't checked for spanish", []Text{{Content: "get free money now", Language: "es"}}, Allow},
+		{"phrases match whole words", []Text{{Content: "carefree moneylender", Language: "en"}}, Allow},
+		{"spanish private", []Text{{Content: "eres tonto", Language: "es"}}, ForcePrivate},
+		{"spanish list isn't checked for english", []Text{{Content: "eres tonto", Language: "en"}}, Allow},
+		{"spaceless language", []Text{{Content: "あなたはバカです", Language: "ja"}}, Reject},
+		{"blocked domain", []Text{{Content: "see https://spam.evil.com/page", Language: "en"}}, Reject},
+		{"too many links", []Text{
+			{Content: "a.com b.com", Language: "en"},
+			{Content: "c.com d.com", Language: "en"},
+		}, Review},
+		{"repeated characters", []Text{{Content: "aaaaaaaaaaaaaaaaaaaaaaaa"}}, Review},
+		{"most severe match wins", []Text{
+			{Content: "free money", Language: "en"},
+			{Content: "eres tonto", Language: "es"},
+			{Content: "badword", Language: "en"},
+		}, Reject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := filter.Check(tt.texts)
+			if verdict.Action != tt.want {
+				t.Errorf("Check() = %s (%+v), want %s", verdict.Action, verdict.Matches, tt.want)
+			}
+			if tt.want == Allow && len(verdict.Matches) > 0 {
+				t.Errorf("Check() allowed with matches %+v", verdict.Matches)
+			}
+		})
+	}
+}
+
+func TestCheckRepeatedContent(t *testing.T) {
+	filter := New(Lists{})
+
+	texts := make([]Text, 0, minRepeatedTexts)
+	for range minRepeatedTexts {
+		texts = append(texts, Text{Content: "buy now"})
+	}
+	if verdict := filter.Check(texts); verdict.Action != Review {
+		t.Errorf("Check() = %s, want %s", verdict.Action, Review)
+	}
+
+	if verdict := filter.Check(texts[:minRepeatedTexts-1]); verdict.Action != Allow {
+		t.Errorf("Check() with %d texts = %s, want %s", minRepeatedTexts-1, verdict.Action, Allow)
+	}
+}
+
+func TestCheckMatchesSortedBySeverity(t *testing.T) {
+	filter, err := Load(testLists())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	verdict := filter.Check([]Text{{Content: "free money badword", Language: "en"}})
+	if len(verdict.Matches) != 2 {
+		t.Fatalf("Check() matches = %+v, want 2", verdict.Matches)
+	}
+	if verdict.Matches[0].Action != Reject || verdict.Matches[1].Action != Review {
+		t.Errorf("Check() matches = %+v, want reject before review", verdict.Matches)
+	}
+	if verdict.Matches[0].Rule != "any/reject" || verdict.Matches[1].Rule != "en/review" {
+		t.Errorf("Check() rules = %q, %q", verdict.Matches[0].Rule, verdict.Matches[1].Rule)
+	}
+}
+
+func TestLoadInvalidLanguage(t *testing.T) {
+	_, err := Load(fstest.MapFS{
+		"not a language/reject.txt": {Data: []byte("badword\n")},
+	})
+	if err == nil {
+		t.Error("Load() with a directory that isn't a language succeeded")
+	}
+}
+
+func TestReload(t *testing.T) {
+	lists := testLists()
+	filter, err := Load(lists)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	texts := []Text{{Content: "newword", Language: "en"}}
+	if verdict := filter.Check(texts); verdict.Action != Allow {
+		t.Fatalf("Check() before reload = %s, want %s", verdict.Action, Allow)
+	}
+
+	lists["en/reject.txt"] = &fstest.MapFile{Data: []byte("newword\n")}
+	if err := filter.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if verdict := filter.Check(texts); verdict.Action != Reject {
+		t.Errorf("Check() after reload = %s, want %s", verdict.Action, Reject)
+	}
+
+	/* lists that can't be read leave the old ones in place */
+	lists["bad language!/reject.txt"] = &fstest.MapFile{Data: []byte("x\n")}
+	if err := filter.Reload(); err == nil {
+		t.Error("Reload() with an invalid directory succeeded")
+	}
+	if verdict := filter.Check(texts); verdict.Action != Reject {
+		t.Errorf("Check() after failed reload = %s, want %s", verdict.Action, Reject)
+	}
+}
+
+func TestReloadWithoutFiles(t *testing.T) {
+	if err := New(Lists{}).Reload(); !errors.Is(err, ErrNoLists) {
+		t.Errorf("Reload() = %v, want %v", err, ErrNoLists)
+	}
+}
@@ -0,0 +1,117 @@
+package contentfilter
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+confusables are letters from other scripts that look like Latin letters,
+like Cyrillic "а" or Greek "ο", which spammers use to get around word lists.
+it's the common ones from Unicode's confusables.txt, not all of them,
+NFKC already handles full-width letters, math letters, ligatures, etc
+*/
+var confusables = map[rune]rune{
+	/* Cyrillic */
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ї': 'i',
+	'ј': 'j', 'ԁ': 'd', 'һ': 'h', 'ԛ': 'q', 'ԝ': 'w', 'ɡ': 'g',
+	/* Greek */
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'γ': 'y', 'ω': 'w',
+	/* Latin lookalikes */
+	'ı': 'i', 'ł': 'l', 'ø': 'o', 'đ': 'd', 'ħ': 'h', 'ŀ': 'l',
+}
+
+/* leet is only applied in words that also have letters, so numbers like "2024" stay numbers */
+var leet = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '!': 'i', '|': 'l',
+}
+
+// Normalize folds text into the form word lists are matched against:
+// NFKC, lowercase, no invisible characters, confusable & accented Latin letters folded to plain ones,
+// leetspeak undone, and letters repeated 3+ times collapsed ("fuuun" is "fun").
+// It returns the words in the text, in order.
+// Letters from non-Latin scripts are kept as-is, other than lowercasing,
+// so their marks (like Devanagari vowel signs) aren't lost
+func Normalize(text string) []string {
+	text = strings.ToLower(norm.NFKC.String(text))
+
+	var folded []rune
+	var prev rune
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Cf, r) {
+			/* zero width spaces, joiners, direction marks, etc */
+			continue
+		}
+		if unicode.Is(unicode.Mn, r) && prev < 0x250 {
+			/* accents on Latin letters */
+			continue
+		}
+		if c, ok := confusables[r]; ok {
+			r = c
+		}
+		folded = append(folded, r)
+		prev = r
+	}
+
+	var words []string
+	var word []rune
+	hasLetter := false
+	flush := func() {
+		if len(word) > 0 && hasLetter {
+			for i, r := range word {
+				if l, ok := leet[r]; ok {
+					word[i] = l
+				}
+			}
+			words = append(words, collapseRepeats(word))
+		} else if len(word) > 0 {
+			words = append(words, string(word))
+		}
+		word = word[:0]
+		hasLetter = false
+	}
+	for i, r := range folded {
+		nextIsLetter := i+1 < len(folded) && unicode.IsLetter(folded[i+1])
+		switch {
+		case unicode.IsLetter(r) || unicode.Is(unicode.M, r):
+			hasLetter = true
+			word = append(word, r)
+		case unicode.IsDigit(r):
+			word = append(word, r)
+		case (r == '$' || r == '@') && (len(word) > 0 || nextIsLetter):
+			/* like "a$$" or "@ss" */
+			word = append(word, r)
+		case (r == '!' || r == '|') && len(word) > 0 && nextIsLetter:
+			/* only in the middle of a word, like "sh!t", so "hi!" is still "hi" */
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func collapseRepeats(word []rune) string {
+	var b strings.Builder
+	for i := 0; i < len(word); i++ {
+		j := i
+		for j+1 < len(word) && word[j+1] == word[i] {
+			j++
+		}
+		if j-i >= 2 {
+			b.WriteRune(word[i])
+		} else {
+			for k := i; k <= j; k++ {
+				b.WriteRune(word[k])
+			}
+		}
+		i = j
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package contentfilter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"lowercase & punctuation", "Hello, World!", []string{"hello", "world"}},
+		{"cyrillic confusables", "Ѕрам", []string{"spam"}},
+		{"greek confusables", "sοurce", []string{"source"}},
+		{"mixed confusables", "frее mоnеy", []string{"free", "money"}},
+		{"accents", "café naïve", []string{"cafe", "naive"}},
+		{"full width", "ＳＰＡＭ", []string{"spam"}},
+		{"zero width characters", "sp\u200bam", []string{"spam"}},
+		{"leetspeak", "h3ll0 w0rld", []string{"hello", "world"}},
+		{"leetspeak symbols", "a$$ @ss sh!t", []string{"ass", "ass", "shit"}},
+		{"numbers stay numbers", "in 2024", []string{"in", "2024"}},
+		{"exclamation at the end", "hi!", []string{"hi"}},
+		{"repeated letters", "fuuuun", []string{"fun"}},
+		{"double letters stay", "hello", []string{"hello"}},
+		{"non-latin marks", "नमस्ते", []string{"नमस्ते"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
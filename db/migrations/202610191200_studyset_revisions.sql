@@ -0,0 +1,20 @@
+-- migrate:up
+create table studyset_revisions (
+    id uuid primary key default gen_random_uuid(),
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    user_id uuid references auth.users (id) on delete set null,
+    created_at timestamptz not null default now(),
+    title text not null,
+    private boolean not null,
+    terms jsonb not null default '[]'::jsonb
+);
+
+create index studyset_revisions_studyset_id_idx
+    on studyset_revisions (studyset_id, created_at desc);
+
+grant select on studyset_revisions to quizfreely_api;
+grant insert on studyset_revisions to quizfreely_api;
+grant update on studyset_revisions to quizfreely_api;
+grant delete on studyset_revisions to quizfreely_api;
+
+-- migrate:down
@@ -0,0 +1,14 @@
+-- migrate:up
+alter table studysets
+    add column deleted_at timestamptz;
+
+alter table terms
+    add column deleted_at timestamptz;
+
+create index studysets_deleted_at_idx on studysets (deleted_at)
+    where deleted_at is not null;
+
+create index terms_deleted_at_idx on terms (deleted_at)
+    where deleted_at is not null;
+
+-- migrate:down
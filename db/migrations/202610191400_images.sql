@@ -0,0 +1,28 @@
+-- migrate:up
+create table images (
+    id uuid primary key default gen_random_uuid(),
+    user_id uuid references auth.users (id) on delete set null,
+    storage_key text not null,
+    thumbnail_key text not null,
+    content_type text not null,
+    width int not null,
+    height int not null,
+    size_bytes int not null,
+    created_at timestamptz not null default now()
+);
+
+grant select on images to quizfreely_api;
+grant insert on images to quizfreely_api;
+grant update on images to quizfreely_api;
+grant delete on images to quizfreely_api;
+
+alter table terms
+    add column term_image_id uuid references images (id) on delete set null,
+    add column def_image_id uuid references images (id) on delete set null;
+
+create index terms_term_image_id_idx on terms (term_image_id)
+    where term_image_id is not null;
+create index terms_def_image_id_idx on terms (def_image_id)
+    where def_image_id is not null;
+
+-- migrate:down
@@ -0,0 +1,19 @@
+-- migrate:up
+alter table terms
+    add column term_html text,
+    add column def_html text,
+    add column term_plain text,
+    add column def_plain text;
+
+-- existing terms were plain text,
+-- their html is rendered by the api when it's null
+update terms set term_plain = term, def_plain = def;
+
+alter table terms
+    add column tsvector_plain tsvector generated always as (
+        to_tsvector('english', coalesce(term_plain, '') || ' ' || coalesce(def_plain, ''))
+    ) stored;
+
+create index textsearch_terms_plain_idx on terms using GIN (tsvector_plain);
+
+-- migrate:down
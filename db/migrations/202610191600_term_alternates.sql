@@ -0,0 +1,6 @@
+-- migrate:up
+alter table terms
+    add column term_alternates text[] not null default '{}',
+    add column def_alternates text[] not null default '{}';
+
+-- migrate:down
@@ -0,0 +1,20 @@
+-- migrate:up
+alter table terms
+    add column hint text;
+
+create table term_notes (
+    id uuid primary key default gen_random_uuid(),
+    term_id uuid not null references terms (id) on delete cascade,
+    user_id uuid not null references auth.users (id) on delete cascade,
+    note text not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    constraint term_notes_unique unique (term_id, user_id)
+);
+
+grant select on term_notes to quizfreely_api;
+grant insert on term_notes to quizfreely_api;
+grant update on term_notes to quizfreely_api;
+grant delete on term_notes to quizfreely_api;
+
+-- migrate:down
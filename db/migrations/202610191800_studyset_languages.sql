@@ -0,0 +1,36 @@
+-- migrate:up
+-- term_language & def_language are BCP-47 tags like "es" or "zh-Hant",
+-- the search configs are picked from them by the api (see language.SearchConfig)
+alter table public.studysets
+    add column term_language text,
+    add column def_language text,
+    add column term_search_config regconfig not null default 'english',
+    add column def_search_config regconfig not null default 'english';
+
+-- titles are usually in the same language as the definitions,
+-- like "Spanish Unit 3 Vocab" with spanish terms & english defs
+drop index textsearch_title_idx;
+alter table public.studysets drop column tsvector_title;
+alter table public.studysets
+    add column tsvector_title tsvector generated always as (
+        to_tsvector(def_search_config, title)
+    ) stored;
+create index textsearch_title_idx on public.studysets using GIN (tsvector_title);
+
+-- generated columns can't look at the studyset,
+-- so terms have their own copy of its search configs
+alter table terms
+    add column term_search_config regconfig not null default 'english',
+    add column def_search_config regconfig not null default 'english';
+
+drop index textsearch_terms_plain_idx;
+alter table terms drop column tsvector_plain;
+alter table terms
+    add column tsvector_plain tsvector generated always as (
+        to_tsvector(term_search_config, coalesce(term_plain, '')) ||
+        to_tsvector(def_search_config, coalesce(def_plain, ''))
+    ) stored;
+create index textsearch_terms_plain_idx on terms using GIN (tsvector_plain);
+
+-- migrate:down
+
@@ -0,0 +1,11 @@
+-- migrate:up
+-- versions go up by 1 on every edit, clients send the version they last saw
+-- so edits based on an old version can be rejected instead of overwriting newer ones
+alter table public.studysets
+    add column version int not null default 1;
+
+alter table terms
+    add column version int not null default 1;
+
+-- migrate:down
+
@@ -0,0 +1,28 @@
+-- migrate:up
+-- renumber every studyset's terms to 0, 1, 2, ... so there are no duplicates or gaps
+-- (trashed terms keep their old sort_order, so they go back where they were when restored)
+update terms t
+set sort_order = numbered.new_sort_order
+from (
+    select id,
+        row_number() over (
+            partition by studyset_id
+            order by sort_order, created_at, id
+        ) - 1 as new_sort_order
+    from terms
+    where deleted_at is null
+) numbered
+where t.id = numbered.id
+    and t.sort_order <> numbered.new_sort_order;
+
+-- no two terms in a studyset can have the same sort_order,
+-- it's checked at commit so terms can swap places in one transaction,
+-- and it's an exclusion constraint (not unique) so it can skip trashed terms
+alter table terms
+    add constraint terms_studyset_id_sort_order_excl
+    exclude using btree (studyset_id with =, sort_order with =)
+    where (deleted_at is null)
+    deferrable initially deferred;
+
+-- migrate:down
+
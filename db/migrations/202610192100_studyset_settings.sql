@@ -0,0 +1,20 @@
+-- migrate:up
+-- studyset_settings was in the init schema but got dropped with the old jsonb progress,
+-- it's back with one row per user & studyset.
+-- settings are validated and given defaults by the api, so the jsonb can be partial
+create table studyset_settings (
+    id uuid primary key default gen_random_uuid(),
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    user_id uuid not null references auth.users (id) on delete cascade,
+    settings jsonb not null default '{}'::jsonb,
+    updated_at timestamptz not null default now(),
+    constraint studyset_settings_unique unique (studyset_id, user_id)
+);
+
+grant select on studyset_settings to quizfreely_api;
+grant insert on studyset_settings to quizfreely_api;
+grant update on studyset_settings to quizfreely_api;
+grant delete on studyset_settings to quizfreely_api;
+
+-- migrate:down
+
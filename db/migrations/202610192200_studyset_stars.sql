@@ -0,0 +1,20 @@
+-- migrate:up
+create table studyset_stars (
+    id uuid primary key default gen_random_uuid(),
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    user_id uuid not null references auth.users (id) on delete cascade,
+    created_at timestamptz not null default now(),
+    constraint studyset_stars_unique unique (studyset_id, user_id)
+);
+
+-- for listing a user's saved studysets, (studyset_id, user_id) covers star counts
+create index studyset_stars_user_id_idx
+    on studyset_stars (user_id, created_at desc);
+
+grant select on studyset_stars to quizfreely_api;
+grant insert on studyset_stars to quizfreely_api;
+grant update on studyset_stars to quizfreely_api;
+grant delete on studyset_stars to quizfreely_api;
+
+-- migrate:down
+
@@ -0,0 +1,47 @@
+-- migrate:up
+-- raw view & study events, one per actor per studyset per day (so refreshing doesn't count twice),
+-- actor is 'u:' + a user id, or 'v:' + a signed-out visitor's id.
+-- they're rolled up into studyset_daily_stats and deleted after a day or two
+create table studyset_events (
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    kind text not null check (kind in ('view', 'study')),
+    actor text not null,
+    day date not null default current_date,
+    primary key (studyset_id, day, kind, actor)
+);
+
+create table studyset_daily_stats (
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    day date not null,
+    views int not null default 0,
+    studies int not null default 0,
+    primary key (studyset_id, day)
+);
+
+create index studyset_daily_stats_day_idx on studyset_daily_stats (day);
+
+-- everyone who has ever studied a studyset, for learner counts
+create table studyset_learners (
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    user_id uuid not null references auth.users (id) on delete cascade,
+    first_studied_at timestamptz not null default now(),
+    primary key (studyset_id, user_id)
+);
+
+grant select on studyset_events to quizfreely_api;
+grant insert on studyset_events to quizfreely_api;
+grant update on studyset_events to quizfreely_api;
+grant delete on studyset_events to quizfreely_api;
+
+grant select on studyset_daily_stats to quizfreely_api;
+grant insert on studyset_daily_stats to quizfreely_api;
+grant update on studyset_daily_stats to quizfreely_api;
+grant delete on studyset_daily_stats to quizfreely_api;
+
+grant select on studyset_learners to quizfreely_api;
+grant insert on studyset_learners to quizfreely_api;
+grant update on studyset_learners to quizfreely_api;
+grant delete on studyset_learners to quizfreely_api;
+
+-- migrate:down
+
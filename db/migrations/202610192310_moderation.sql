@@ -0,0 +1,68 @@
+-- migrate:up
+alter table auth.users
+    add column moderator boolean not null default false;
+
+-- while a studyset is held by moderation it stays private, even if its owner tries to make it public
+alter table studysets
+    add column moderation_hold boolean not null default false;
+
+create table studyset_reports (
+    id uuid primary key default gen_random_uuid(),
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    reporter_id uuid not null references auth.users (id) on delete cascade,
+    reason text not null check (reason in ('SPAM', 'INAPPROPRIATE', 'HARASSMENT', 'COPYRIGHT', 'MISLEADING', 'OTHER')),
+    details text,
+    status text not null default 'OPEN' check (status in ('OPEN', 'DISMISSED', 'ACTIONED')),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    resolved_at timestamptz,
+    resolved_by uuid references auth.users (id) on delete set null,
+    constraint studyset_reports_unique unique (studyset_id, reporter_id)
+);
+
+create index studyset_reports_open_idx on studyset_reports (studyset_id)
+    where status = 'OPEN';
+
+create table moderation_decisions (
+    id uuid primary key default gen_random_uuid(),
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    -- null for automatic decisions, like hiding a studyset with too many reports
+    moderator_id uuid references auth.users (id) on delete set null,
+    action text not null check (action in ('DISMISS', 'HIDE', 'REMOVE', 'AUTO_HIDE')),
+    note text,
+    created_at timestamptz not null default now()
+);
+
+create index moderation_decisions_studyset_id_idx
+    on moderation_decisions (studyset_id, created_at desc);
+
+-- things users should know about, like moderation decisions on their studysets
+create table notifications (
+    id uuid primary key default gen_random_uuid(),
+    user_id uuid not null references auth.users (id) on delete cascade,
+    kind text not null,
+    data jsonb not null default '{}'::jsonb,
+    created_at timestamptz not null default now(),
+    read_at timestamptz
+);
+
+create index notifications_user_id_idx
+    on notifications (user_id, created_at desc);
+
+grant select on studyset_reports to quizfreely_api;
+grant insert on studyset_reports to quizfreely_api;
+grant update on studyset_reports to quizfreely_api;
+grant delete on studyset_reports to quizfreely_api;
+
+grant select on moderation_decisions to quizfreely_api;
+grant insert on moderation_decisions to quizfreely_api;
+grant update on moderation_decisions to quizfreely_api;
+grant delete on moderation_decisions to quizfreely_api;
+
+grant select on notifications to quizfreely_api;
+grant insert on notifications to quizfreely_api;
+grant update on notifications to quizfreely_api;
+grant delete on notifications to quizfreely_api;
+
+-- migrate:down
+
@@ -0,0 +1,10 @@
+-- migrate:up
+-- reports made by the content filter instead of a user have no reporter
+alter table studyset_reports
+    alter column reporter_id drop not null;
+
+-- one open automatic report per studyset, it's updated when the studyset changes again
+create unique index studyset_reports_automatic_open_idx on studyset_reports (studyset_id)
+    where reporter_id is null and status = 'OPEN';
+
+-- migrate:down
@@ -0,0 +1,24 @@
+-- migrate:up
+alter table auth.users
+    add column bio text,
+    add column avatar_image_id uuid references images (id) on delete set null,
+    add column created_at timestamptz not null default now(),
+    -- private profiles only show their username & display name to other people
+    add column profile_private boolean not null default false,
+    add column profile_show_studysets boolean not null default true,
+    add column profile_show_joined_at boolean not null default true;
+
+-- users from before created_at existed joined (at the latest) when they made their first studyset
+update auth.users u
+set created_at = s.first_created_at
+from (
+    select user_id, min(updated_at) as first_created_at
+    from public.studysets
+    group by user_id
+) s
+where s.user_id = u.id and s.first_created_at < u.created_at;
+
+create index studysets_user_id_updated_at_idx on public.studysets (user_id, updated_at desc)
+    where deleted_at is null;
+
+-- migrate:down
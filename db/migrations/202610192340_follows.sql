@@ -0,0 +1,37 @@
+-- migrate:up
+create table user_follows (
+    follower_id uuid not null references auth.users (id) on delete cascade,
+    followee_id uuid not null references auth.users (id) on delete cascade,
+    created_at timestamptz not null default now(),
+    primary key (follower_id, followee_id),
+    constraint user_follows_not_self check (follower_id <> followee_id)
+);
+
+create index user_follows_followee_id_idx on user_follows (followee_id);
+
+grant select on user_follows to quizfreely_api;
+grant insert on user_follows to quizfreely_api;
+grant update on user_follows to quizfreely_api;
+grant delete on user_follows to quizfreely_api;
+
+-- studysets being published or substantially updated, recorded when it happens,
+-- so feeds don't have to scan every studyset.
+-- ids only go up, so they're used as feed cursors
+create table studyset_feed_events (
+    id bigserial primary key,
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    user_id uuid not null references auth.users (id) on delete cascade,
+    kind text not null check (kind in ('PUBLISHED', 'UPDATED')),
+    created_at timestamptz not null default now()
+);
+
+create index studyset_feed_events_user_id_idx on studyset_feed_events (user_id, id desc);
+create index studyset_feed_events_studyset_id_idx on studyset_feed_events (studyset_id, created_at desc);
+
+grant select on studyset_feed_events to quizfreely_api;
+grant insert on studyset_feed_events to quizfreely_api;
+grant update on studyset_feed_events to quizfreely_api;
+grant delete on studyset_feed_events to quizfreely_api;
+grant usage, select on studyset_feed_events_id_seq to quizfreely_api;
+
+-- migrate:down
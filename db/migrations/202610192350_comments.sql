@@ -0,0 +1,32 @@
+-- migrate:up
+-- comments on public studysets, or on one of their terms.
+-- they're only shown while the studyset is public,
+-- and deleting an account deletes its comments (and replies to them)
+create table studyset_comments (
+    id uuid primary key default gen_random_uuid(),
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    term_id uuid references terms (id) on delete cascade,
+    parent_id uuid references studyset_comments (id) on delete cascade,
+    user_id uuid not null references auth.users (id) on delete cascade,
+    -- empty for deleted comments that are kept so their replies stay in their thread
+    body text not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    edited_at timestamptz,
+    deleted_at timestamptz
+);
+
+create index studyset_comments_studyset_id_idx on studyset_comments (studyset_id, created_at)
+    where term_id is null and parent_id is null;
+create index studyset_comments_term_id_idx on studyset_comments (term_id, created_at)
+    where term_id is not null and parent_id is null;
+create index studyset_comments_parent_id_idx on studyset_comments (parent_id, created_at)
+    where parent_id is not null;
+create index studyset_comments_user_id_idx on studyset_comments (user_id);
+
+grant select on studyset_comments to quizfreely_api;
+grant insert on studyset_comments to quizfreely_api;
+grant update on studyset_comments to quizfreely_api;
+grant delete on studyset_comments to quizfreely_api;
+
+-- migrate:down
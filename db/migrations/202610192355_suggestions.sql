@@ -0,0 +1,31 @@
+-- migrate:up
+-- edits to a public studyset's terms suggested by someone other than its owner,
+-- edits & removals keep the version each term was at when it was suggested,
+-- so accepting can tell if the term changed since
+create table studyset_suggestions (
+    id uuid primary key default gen_random_uuid(),
+    studyset_id uuid not null references studysets (id) on delete cascade,
+    user_id uuid not null references auth.users (id) on delete cascade,
+    message text,
+    -- [{termId, baseVersion, term, def, termAlternates, defAlternates, hint}, ...]
+    edits jsonb not null default '[]'::jsonb,
+    -- [{term, def, termAlternates, defAlternates, hint}, ...]
+    additions jsonb not null default '[]'::jsonb,
+    -- [{termId, baseVersion}, ...]
+    removals jsonb not null default '[]'::jsonb,
+    status text not null default 'OPEN' check (status in ('OPEN', 'ACCEPTED', 'REJECTED')),
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    resolved_at timestamptz,
+    resolved_by uuid references auth.users (id) on delete set null
+);
+
+create index studyset_suggestions_studyset_id_idx on studyset_suggestions (studyset_id, created_at desc);
+create index studyset_suggestions_user_id_idx on studyset_suggestions (user_id, created_at desc);
+
+grant select on studyset_suggestions to quizfreely_api;
+grant insert on studyset_suggestions to quizfreely_api;
+grant update on studyset_suggestions to quizfreely_api;
+grant delete on studyset_suggestions to quizfreely_api;
+
+-- migrate:down
@@ -0,0 +1,18 @@
+-- migrate:up
+-- kinds of notifications a user doesn't want, they aren't added at all while muted
+create table notification_mutes (
+    user_id uuid not null references auth.users (id) on delete cascade,
+    kind text not null,
+    created_at timestamptz not null default now(),
+    primary key (user_id, kind)
+);
+
+grant select on notification_mutes to quizfreely_api;
+grant insert on notification_mutes to quizfreely_api;
+grant update on notification_mutes to quizfreely_api;
+grant delete on notification_mutes to quizfreely_api;
+
+create index notifications_user_id_unread_idx on notifications (user_id)
+    where read_at is null;
+
+-- migrate:down
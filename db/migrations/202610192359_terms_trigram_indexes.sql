@@ -0,0 +1,6 @@
+-- migrate:up
+-- so finding near duplicate terms can use the % operator with these instead of comparing every pair of terms
+create index terms_term_plain_trgm_idx on terms using gin (term_plain gin_trgm_ops);
+create index terms_def_plain_trgm_idx on terms using gin (def_plain gin_trgm_ops);
+
+-- migrate:down
@@ -136,7 +136,71 @@ CREATE TABLE auth.users (
     display_name text NOT NULL,
     auth_type public.auth_type_enum NOT NULL,
     oauth_google_sub text,
-    oauth_google_email text
+    oauth_google_email text,
+    moderator boolean DEFAULT false NOT NULL,
+    bio text,
+    avatar_image_id uuid,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    profile_private boolean DEFAULT false NOT NULL,
+    profile_show_studysets boolean DEFAULT true NOT NULL,
+    profile_show_joined_at boolean DEFAULT true NOT NULL
+);
+
+
+--
+-- Name: images; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.images (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid,
+    storage_key text NOT NULL,
+    thumbnail_key text NOT NULL,
+    content_type text NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    size_bytes integer NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: moderation_decisions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.moderation_decisions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    studyset_id uuid NOT NULL,
+    moderator_id uuid,
+    action text NOT NULL,
+    note text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT moderation_decisions_action_check CHECK ((action = ANY (ARRAY['DISMISS'::text, 'HIDE'::text, 'REMOVE'::text, 'AUTO_HIDE'::text])))
+);
+
+
+--
+-- Name: notification_mutes; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notification_mutes (
+    user_id uuid NOT NULL,
+    kind text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: notifications; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notifications (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    kind text NOT NULL,
+    data jsonb DEFAULT '{}'::jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    read_at timestamp with time zone
 );
 
 
@@ -174,6 +238,174 @@ CREATE TABLE public.search_queries (
 );
 
 
+--
+-- Name: studyset_comments; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_comments (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    studyset_id uuid NOT NULL,
+    term_id uuid,
+    parent_id uuid,
+    user_id uuid NOT NULL,
+    body text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    edited_at timestamp with time zone,
+    deleted_at timestamp with time zone
+);
+
+
+--
+-- Name: studyset_daily_stats; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_daily_stats (
+    studyset_id uuid NOT NULL,
+    day date NOT NULL,
+    views integer DEFAULT 0 NOT NULL,
+    studies integer DEFAULT 0 NOT NULL
+);
+
+
+--
+-- Name: studyset_events; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_events (
+    studyset_id uuid NOT NULL,
+    kind text NOT NULL,
+    actor text NOT NULL,
+    day date DEFAULT CURRENT_DATE NOT NULL,
+    CONSTRAINT studyset_events_kind_check CHECK ((kind = ANY (ARRAY['view'::text, 'study'::text])))
+);
+
+
+--
+-- Name: studyset_feed_events; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_feed_events (
+    id bigint NOT NULL,
+    studyset_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    kind text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT studyset_feed_events_kind_check CHECK ((kind = ANY (ARRAY['PUBLISHED'::text, 'UPDATED'::text])))
+);
+
+
+--
+-- Name: studyset_feed_events_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.studyset_feed_events_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: studyset_feed_events_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.studyset_feed_events_id_seq OWNED BY public.studyset_feed_events.id;
+
+
+--
+-- Name: studyset_learners; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_learners (
+    studyset_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    first_studied_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: studyset_reports; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_reports (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    studyset_id uuid NOT NULL,
+    reporter_id uuid,
+    reason text NOT NULL,
+    details text,
+    status text DEFAULT 'OPEN'::text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    resolved_at timestamp with time zone,
+    resolved_by uuid,
+    CONSTRAINT studyset_reports_reason_check CHECK ((reason = ANY (ARRAY['SPAM'::text, 'INAPPROPRIATE'::text, 'HARASSMENT'::text, 'COPYRIGHT'::text, 'MISLEADING'::text, 'OTHER'::text]))),
+    CONSTRAINT studyset_reports_status_check CHECK ((status = ANY (ARRAY['OPEN'::text, 'DISMISSED'::text, 'ACTIONED'::text])))
+);
+
+
+--
+-- Name: studyset_revisions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_revisions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    studyset_id uuid NOT NULL,
+    user_id uuid,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    title text NOT NULL,
+    private boolean NOT NULL,
+    terms jsonb DEFAULT '[]'::jsonb NOT NULL
+);
+
+
+--
+-- Name: studyset_settings; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_settings (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    studyset_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    settings jsonb DEFAULT '{}'::jsonb NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: studyset_stars; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_stars (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    studyset_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: studyset_suggestions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.studyset_suggestions (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    studyset_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    message text,
+    edits jsonb DEFAULT '[]'::jsonb NOT NULL,
+    additions jsonb DEFAULT '[]'::jsonb NOT NULL,
+    removals jsonb DEFAULT '[]'::jsonb NOT NULL,
+    status text DEFAULT 'OPEN'::text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    resolved_at timestamp with time zone,
+    resolved_by uuid,
+    CONSTRAINT studyset_suggestions_status_check CHECK ((status = ANY (ARRAY['OPEN'::text, 'ACCEPTED'::text, 'REJECTED'::text])))
+);
+
+
 --
 -- Name: studysets; Type: TABLE; Schema: public; Owner: -
 --
@@ -186,7 +418,14 @@ CREATE TABLE public.studysets (
     updated_at timestamp with time zone DEFAULT now(),
     terms_count integer,
     featured boolean DEFAULT false,
-    tsvector_title tsvector GENERATED ALWAYS AS (to_tsvector('english'::regconfig, title)) STORED
+    deleted_at timestamp with time zone,
+    term_language text,
+    def_language text,
+    term_search_config regconfig DEFAULT 'english'::regconfig NOT NULL,
+    def_search_config regconfig DEFAULT 'english'::regconfig NOT NULL,
+    tsvector_title tsvector GENERATED ALWAYS AS (to_tsvector(def_search_config, title)) STORED,
+    version integer DEFAULT 1 NOT NULL,
+    moderation_hold boolean DEFAULT false NOT NULL
 );
 
 
@@ -205,6 +444,20 @@ CREATE TABLE public.term_confusion_pairs (
 );
 
 
+--
+-- Name: term_notes; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.term_notes (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    term_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    note text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: term_progress; Type: TABLE; Schema: public; Owner: -
 --
@@ -239,10 +492,43 @@ CREATE TABLE public.terms (
     studyset_id uuid NOT NULL,
     sort_order integer NOT NULL,
     created_at timestamp with time zone DEFAULT now(),
-    updated_at timestamp with time zone DEFAULT now()
+    updated_at timestamp with time zone DEFAULT now(),
+    deleted_at timestamp with time zone,
+    term_image_id uuid,
+    def_image_id uuid,
+    term_html text,
+    def_html text,
+    term_plain text,
+    def_plain text,
+    term_alternates text[] DEFAULT '{}'::text[] NOT NULL,
+    def_alternates text[] DEFAULT '{}'::text[] NOT NULL,
+    hint text,
+    term_search_config regconfig DEFAULT 'english'::regconfig NOT NULL,
+    def_search_config regconfig DEFAULT 'english'::regconfig NOT NULL,
+    tsvector_plain tsvector GENERATED ALWAYS AS ((to_tsvector(term_search_config, COALESCE(term_plain, ''::text)) || to_tsvector(def_search_config, COALESCE(def_plain, ''::text)))) STORED,
+    version integer DEFAULT 1 NOT NULL
 );
 
 
+--
+-- Name: user_follows; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_follows (
+    follower_id uuid NOT NULL,
+    followee_id uuid NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT user_follows_not_self CHECK ((follower_id <> followee_id))
+);
+
+
+--
+-- Name: studyset_feed_events id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_feed_events ALTER COLUMN id SET DEFAULT nextval('public.studyset_feed_events_id_seq'::regclass);
+
+
 --
 -- Name: sessions sessions_pkey; Type: CONSTRAINT; Schema: auth; Owner: -
 --
@@ -283,6 +569,38 @@ ALTER TABLE ONLY public.term_confusion_pairs
     ADD CONSTRAINT confusion_pairs_unique UNIQUE (user_id, term_id, confused_term_id);
 
 
+--
+-- Name: images images_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.images
+    ADD CONSTRAINT images_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: moderation_decisions moderation_decisions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.moderation_decisions
+    ADD CONSTRAINT moderation_decisions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: notification_mutes notification_mutes_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_mutes
+    ADD CONSTRAINT notification_mutes_pkey PRIMARY KEY (user_id, kind);
+
+
+--
+-- Name: notifications notifications_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notifications
+    ADD CONSTRAINT notifications_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: practice_tests practice_tests_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -308,122 +626,705 @@ ALTER TABLE ONLY public.search_queries
 
 
 --
--- Name: studysets studysets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_comments studyset_comments_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.studysets
-    ADD CONSTRAINT studysets_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.studyset_comments
+    ADD CONSTRAINT studyset_comments_pkey PRIMARY KEY (id);
 
 
 --
--- Name: term_confusion_pairs term_confusion_pairs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_daily_stats studyset_daily_stats_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.term_confusion_pairs
-    ADD CONSTRAINT term_confusion_pairs_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.studyset_daily_stats
+    ADD CONSTRAINT studyset_daily_stats_pkey PRIMARY KEY (studyset_id, day);
 
 
 --
--- Name: term_progress term_progress_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_events studyset_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.term_progress
-    ADD CONSTRAINT term_progress_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.studyset_events
+    ADD CONSTRAINT studyset_events_pkey PRIMARY KEY (studyset_id, day, kind, actor);
 
 
 --
--- Name: term_progress term_progress_unique; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_feed_events studyset_feed_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.term_progress
-    ADD CONSTRAINT term_progress_unique UNIQUE (term_id, user_id);
+ALTER TABLE ONLY public.studyset_feed_events
+    ADD CONSTRAINT studyset_feed_events_pkey PRIMARY KEY (id);
 
 
 --
--- Name: terms terms_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_learners studyset_learners_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.terms
-    ADD CONSTRAINT terms_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.studyset_learners
+    ADD CONSTRAINT studyset_learners_pkey PRIMARY KEY (studyset_id, user_id);
 
 
 --
--- Name: textsearch_title_idx; Type: INDEX; Schema: public; Owner: -
+-- Name: studyset_reports studyset_reports_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-CREATE INDEX textsearch_title_idx ON public.studysets USING gin (tsvector_title);
+ALTER TABLE ONLY public.studyset_reports
+    ADD CONSTRAINT studyset_reports_pkey PRIMARY KEY (id);
 
 
 --
--- Name: practice_tests practice_tests_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_reports studyset_reports_unique; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.practice_tests
-    ADD CONSTRAINT practice_tests_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.studyset_reports
+    ADD CONSTRAINT studyset_reports_unique UNIQUE (studyset_id, reporter_id);
 
 
 --
--- Name: practice_tests practice_tests_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_revisions studyset_revisions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.practice_tests
-    ADD CONSTRAINT practice_tests_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.studyset_revisions
+    ADD CONSTRAINT studyset_revisions_pkey PRIMARY KEY (id);
 
 
 --
--- Name: studysets studysets_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: studyset_settings studyset_settings_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_settings
+    ADD CONSTRAINT studyset_settings_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: studyset_settings studyset_settings_unique; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_settings
+    ADD CONSTRAINT studyset_settings_unique UNIQUE (studyset_id, user_id);
+
+
+--
+-- Name: studyset_stars studyset_stars_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_stars
+    ADD CONSTRAINT studyset_stars_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: studyset_stars studyset_stars_unique; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_stars
+    ADD CONSTRAINT studyset_stars_unique UNIQUE (studyset_id, user_id);
+
+
+--
+-- Name: studyset_suggestions studyset_suggestions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_suggestions
+    ADD CONSTRAINT studyset_suggestions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: studysets studysets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
 ALTER TABLE ONLY public.studysets
-    ADD CONSTRAINT studysets_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+    ADD CONSTRAINT studysets_pkey PRIMARY KEY (id);
 
 
 --
--- Name: term_confusion_pairs term_confusion_pairs_confused_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: term_confusion_pairs term_confusion_pairs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
 ALTER TABLE ONLY public.term_confusion_pairs
-    ADD CONSTRAINT term_confusion_pairs_confused_term_id_fkey FOREIGN KEY (confused_term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+    ADD CONSTRAINT term_confusion_pairs_pkey PRIMARY KEY (id);
 
 
 --
--- Name: term_confusion_pairs term_confusion_pairs_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: term_notes term_notes_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.term_confusion_pairs
-    ADD CONSTRAINT term_confusion_pairs_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.term_notes
+    ADD CONSTRAINT term_notes_pkey PRIMARY KEY (id);
 
 
 --
--- Name: term_confusion_pairs term_confusion_pairs_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: term_notes term_notes_unique; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY public.term_confusion_pairs
-    ADD CONSTRAINT term_confusion_pairs_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+ALTER TABLE ONLY public.term_notes
+    ADD CONSTRAINT term_notes_unique UNIQUE (term_id, user_id);
 
 
 --
--- Name: term_progress term_progress_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: term_progress term_progress_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
 ALTER TABLE ONLY public.term_progress
-    ADD CONSTRAINT term_progress_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+    ADD CONSTRAINT term_progress_pkey PRIMARY KEY (id);
 
 
 --
--- Name: term_progress term_progress_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: term_progress term_progress_unique; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
 ALTER TABLE ONLY public.term_progress
-    ADD CONSTRAINT term_progress_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+    ADD CONSTRAINT term_progress_unique UNIQUE (term_id, user_id);
 
 
 --
--- Name: terms terms_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: terms terms_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
 
 ALTER TABLE ONLY public.terms
-    ADD CONSTRAINT terms_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+    ADD CONSTRAINT terms_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: terms terms_studyset_id_sort_order_excl; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.terms
+    ADD CONSTRAINT terms_studyset_id_sort_order_excl EXCLUDE USING btree (studyset_id WITH =, sort_order WITH =) WHERE ((deleted_at IS NULL)) DEFERRABLE INITIALLY DEFERRED;
+
+
+--
+-- Name: user_follows user_follows_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_follows
+    ADD CONSTRAINT user_follows_pkey PRIMARY KEY (follower_id, followee_id);
+
+
+--
+-- Name: moderation_decisions_studyset_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX moderation_decisions_studyset_id_idx ON public.moderation_decisions USING btree (studyset_id, created_at DESC);
+
+
+--
+-- Name: notifications_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notifications_user_id_idx ON public.notifications USING btree (user_id, created_at DESC);
+
+
+--
+-- Name: notifications_user_id_unread_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notifications_user_id_unread_idx ON public.notifications USING btree (user_id) WHERE (read_at IS NULL);
+
+
+--
+-- Name: studyset_comments_parent_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_comments_parent_id_idx ON public.studyset_comments USING btree (parent_id, created_at) WHERE (parent_id IS NOT NULL);
+
+
+--
+-- Name: studyset_comments_studyset_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_comments_studyset_id_idx ON public.studyset_comments USING btree (studyset_id, created_at) WHERE ((term_id IS NULL) AND (parent_id IS NULL));
+
+
+--
+-- Name: studyset_comments_term_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_comments_term_id_idx ON public.studyset_comments USING btree (term_id, created_at) WHERE ((term_id IS NOT NULL) AND (parent_id IS NULL));
+
+
+--
+-- Name: studyset_comments_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_comments_user_id_idx ON public.studyset_comments USING btree (user_id);
+
+
+--
+-- Name: studyset_daily_stats_day_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_daily_stats_day_idx ON public.studyset_daily_stats USING btree (day);
+
+
+--
+-- Name: studyset_feed_events_studyset_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_feed_events_studyset_id_idx ON public.studyset_feed_events USING btree (studyset_id, created_at DESC);
+
+
+--
+-- Name: studyset_feed_events_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_feed_events_user_id_idx ON public.studyset_feed_events USING btree (user_id, id DESC);
+
+
+--
+-- Name: studyset_reports_automatic_open_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX studyset_reports_automatic_open_idx ON public.studyset_reports USING btree (studyset_id) WHERE ((reporter_id IS NULL) AND (status = 'OPEN'::text));
+
+
+--
+-- Name: studyset_reports_open_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_reports_open_idx ON public.studyset_reports USING btree (studyset_id) WHERE (status = 'OPEN'::text);
+
+
+--
+-- Name: studyset_revisions_studyset_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_revisions_studyset_id_idx ON public.studyset_revisions USING btree (studyset_id, created_at DESC);
+
+
+--
+-- Name: studyset_stars_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_stars_user_id_idx ON public.studyset_stars USING btree (user_id, created_at DESC);
+
+
+--
+-- Name: studyset_suggestions_studyset_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_suggestions_studyset_id_idx ON public.studyset_suggestions USING btree (studyset_id, created_at DESC);
+
+
+--
+-- Name: studyset_suggestions_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studyset_suggestions_user_id_idx ON public.studyset_suggestions USING btree (user_id, created_at DESC);
+
+
+--
+-- Name: studysets_deleted_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studysets_deleted_at_idx ON public.studysets USING btree (deleted_at) WHERE (deleted_at IS NOT NULL);
+
+
+--
+-- Name: studysets_user_id_updated_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX studysets_user_id_updated_at_idx ON public.studysets USING btree (user_id, updated_at DESC) WHERE (deleted_at IS NULL);
+
+
+--
+-- Name: terms_def_image_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX terms_def_image_id_idx ON public.terms USING btree (def_image_id) WHERE (def_image_id IS NOT NULL);
+
+
+--
+-- Name: terms_def_plain_trgm_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX terms_def_plain_trgm_idx ON public.terms USING gin (def_plain public.gin_trgm_ops);
+
+
+--
+-- Name: terms_deleted_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX terms_deleted_at_idx ON public.terms USING btree (deleted_at) WHERE (deleted_at IS NOT NULL);
+
+
+--
+-- Name: terms_term_image_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX terms_term_image_id_idx ON public.terms USING btree (term_image_id) WHERE (term_image_id IS NOT NULL);
+
+
+--
+-- Name: terms_term_plain_trgm_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX terms_term_plain_trgm_idx ON public.terms USING gin (term_plain public.gin_trgm_ops);
+
+
+--
+-- Name: textsearch_terms_plain_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX textsearch_terms_plain_idx ON public.terms USING gin (tsvector_plain);
+
+
+--
+-- Name: textsearch_title_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX textsearch_title_idx ON public.studysets USING gin (tsvector_title);
+
+
+--
+-- Name: user_follows_followee_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX user_follows_followee_id_idx ON public.user_follows USING btree (followee_id);
+
+
+--
+-- Name: users users_avatar_image_id_fkey; Type: FK CONSTRAINT; Schema: auth; Owner: -
+--
+
+ALTER TABLE ONLY auth.users
+    ADD CONSTRAINT users_avatar_image_id_fkey FOREIGN KEY (avatar_image_id) REFERENCES public.images(id) ON DELETE SET NULL;
+
+
+--
+-- Name: images images_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.images
+    ADD CONSTRAINT images_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: moderation_decisions moderation_decisions_moderator_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.moderation_decisions
+    ADD CONSTRAINT moderation_decisions_moderator_id_fkey FOREIGN KEY (moderator_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: moderation_decisions moderation_decisions_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.moderation_decisions
+    ADD CONSTRAINT moderation_decisions_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: notification_mutes notification_mutes_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_mutes
+    ADD CONSTRAINT notification_mutes_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: notifications notifications_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notifications
+    ADD CONSTRAINT notifications_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: practice_tests practice_tests_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.practice_tests
+    ADD CONSTRAINT practice_tests_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: practice_tests practice_tests_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.practice_tests
+    ADD CONSTRAINT practice_tests_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_comments studyset_comments_parent_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_comments
+    ADD CONSTRAINT studyset_comments_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES public.studyset_comments(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_comments studyset_comments_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_comments
+    ADD CONSTRAINT studyset_comments_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_comments studyset_comments_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_comments
+    ADD CONSTRAINT studyset_comments_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_comments studyset_comments_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_comments
+    ADD CONSTRAINT studyset_comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_daily_stats studyset_daily_stats_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_daily_stats
+    ADD CONSTRAINT studyset_daily_stats_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_events studyset_events_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_events
+    ADD CONSTRAINT studyset_events_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_feed_events studyset_feed_events_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_feed_events
+    ADD CONSTRAINT studyset_feed_events_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_feed_events studyset_feed_events_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_feed_events
+    ADD CONSTRAINT studyset_feed_events_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_learners studyset_learners_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_learners
+    ADD CONSTRAINT studyset_learners_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_learners studyset_learners_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_learners
+    ADD CONSTRAINT studyset_learners_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_reports studyset_reports_reporter_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_reports
+    ADD CONSTRAINT studyset_reports_reporter_id_fkey FOREIGN KEY (reporter_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_reports studyset_reports_resolved_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_reports
+    ADD CONSTRAINT studyset_reports_resolved_by_fkey FOREIGN KEY (resolved_by) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: studyset_reports studyset_reports_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_reports
+    ADD CONSTRAINT studyset_reports_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_revisions studyset_revisions_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_revisions
+    ADD CONSTRAINT studyset_revisions_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_revisions studyset_revisions_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_revisions
+    ADD CONSTRAINT studyset_revisions_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: studyset_settings studyset_settings_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_settings
+    ADD CONSTRAINT studyset_settings_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_settings studyset_settings_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_settings
+    ADD CONSTRAINT studyset_settings_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_stars studyset_stars_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_stars
+    ADD CONSTRAINT studyset_stars_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_stars studyset_stars_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_stars
+    ADD CONSTRAINT studyset_stars_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_suggestions studyset_suggestions_resolved_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_suggestions
+    ADD CONSTRAINT studyset_suggestions_resolved_by_fkey FOREIGN KEY (resolved_by) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: studyset_suggestions studyset_suggestions_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_suggestions
+    ADD CONSTRAINT studyset_suggestions_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studyset_suggestions studyset_suggestions_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studyset_suggestions
+    ADD CONSTRAINT studyset_suggestions_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: studysets studysets_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.studysets
+    ADD CONSTRAINT studysets_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: term_confusion_pairs term_confusion_pairs_confused_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.term_confusion_pairs
+    ADD CONSTRAINT term_confusion_pairs_confused_term_id_fkey FOREIGN KEY (confused_term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: term_confusion_pairs term_confusion_pairs_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.term_confusion_pairs
+    ADD CONSTRAINT term_confusion_pairs_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: term_confusion_pairs term_confusion_pairs_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.term_confusion_pairs
+    ADD CONSTRAINT term_confusion_pairs_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: term_notes term_notes_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.term_notes
+    ADD CONSTRAINT term_notes_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: term_notes term_notes_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.term_notes
+    ADD CONSTRAINT term_notes_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: term_progress term_progress_term_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.term_progress
+    ADD CONSTRAINT term_progress_term_id_fkey FOREIGN KEY (term_id) REFERENCES public.terms(id) ON DELETE CASCADE;
+
+
+--
+-- Name: term_progress term_progress_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.term_progress
+    ADD CONSTRAINT term_progress_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: terms terms_def_image_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.terms
+    ADD CONSTRAINT terms_def_image_id_fkey FOREIGN KEY (def_image_id) REFERENCES public.images(id) ON DELETE SET NULL;
+
+
+--
+-- Name: terms terms_studyset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.terms
+    ADD CONSTRAINT terms_studyset_id_fkey FOREIGN KEY (studyset_id) REFERENCES public.studysets(id) ON DELETE CASCADE;
+
+
+--
+-- Name: terms terms_term_image_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.terms
+    ADD CONSTRAINT terms_term_image_id_fkey FOREIGN KEY (term_image_id) REFERENCES public.images(id) ON DELETE SET NULL;
+
+
+--
+-- Name: user_follows user_follows_followee_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_follows
+    ADD CONSTRAINT user_follows_followee_id_fkey FOREIGN KEY (followee_id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: user_follows user_follows_follower_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_follows
+    ADD CONSTRAINT user_follows_follower_id_fkey FOREIGN KEY (follower_id) REFERENCES auth.users(id) ON DELETE CASCADE;
 
 
 --
@@ -442,4 +1343,24 @@ INSERT INTO public.schema_migrations (version) VALUES
     ('202508191404'),
     ('202508201847'),
     ('202508202155'),
-    ('202508211445');
+    ('202508211445'),
+    ('202610191200'),
+    ('202610191300'),
+    ('202610191400'),
+    ('202610191500'),
+    ('202610191600'),
+    ('202610191700'),
+    ('202610191800'),
+    ('202610191900'),
+    ('202610192000'),
+    ('202610192100'),
+    ('202610192200'),
+    ('202610192300'),
+    ('202610192310'),
+    ('202610192320'),
+    ('202610192330'),
+    ('202610192340'),
+    ('202610192350'),
+    ('202610192355'),
+    ('202610192358'),
+    ('202610192359');
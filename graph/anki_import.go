@@ -0,0 +1,218 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"quizfreely/api/anki"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/images"
+	"quizfreely/api/importer"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// maxAnkiImportImages is how many images one anki import can upload,
+// every image gets decoded & resized, so this keeps one import from hogging the server
+const maxAnkiImportImages = 500
+
+// ankiStudyset is what one studyset of an anki import will be made from
+type ankiStudyset struct {
+	title string
+	notes []anki.ImportedNote
+}
+
+// groupAnkiDecks turns decks into studysets, either one per deck
+// (with titles like "Spanish / Unit 1"), or one per top-level deck with its subdecks merged in
+func groupAnkiDecks(decks []*anki.ImportedDeck, preserveHierarchy bool) []*ankiStudyset {
+	var studysets []*ankiStudyset
+	byTitle := make(map[string]*ankiStudyset)
+	for _, deck := range decks {
+		path := strings.Split(deck.Name, "::")
+		for i := range path {
+			path[i] = strings.TrimSpace(path[i])
+		}
+		title := path[0]
+		if preserveHierarchy {
+			title = strings.Join(path, " / ")
+		}
+
+		studyset, ok := byTitle[title]
+		if !ok {
+			studyset = &ankiStudyset{title: title}
+			byTitle[title] = studyset
+			studysets = append(studysets, studyset)
+		}
+		studyset.notes = append(studyset.notes, deck.Notes...)
+	}
+	return studysets
+}
+
+// readAnkiUpload reads an uploaded .apkg, using the upload as an io.ReaderAt when it is one
+// (multipart files are) so big packages don't have to be copied into memory
+func readAnkiUpload(ctx context.Context, file graphql.Upload, skipMedia bool) (*anki.Package, error) {
+	if file.Size > anki.MaxPackageBytes {
+		return nil, anki.ErrTooLarge
+	}
+
+	readerAt, ok := file.File.(io.ReaderAt)
+	size := file.Size
+	if !ok {
+		data, err := io.ReadAll(io.LimitReader(file.File, anki.MaxPackageBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read anki package: %w", err)
+		}
+		readerAt = bytes.NewReader(data)
+		size = int64(len(data))
+	}
+
+	pkg, err := anki.ReadAPKG(ctx, readerAt, size, anki.ReadOptions{SkipMedia: skipMedia})
+	if err != nil {
+		if errors.Is(err, anki.ErrNotAPKG) || errors.Is(err, anki.ErrTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read anki package: %w", err)
+	}
+	return pkg, nil
+}
+
+// importAnkiPackage reads an .apkg and (unless dryRun) creates a studyset for each deck,
+// all in one transaction, so a failed import doesn't leave half of the decks behind
+func (r *Resolver) importAnkiPackage(ctx context.Context, userID *string, file graphql.Upload, options model.AnkiImportOptions, dryRun bool) (*model.AnkiImportResult, error) {
+	skipMedia := options.SkipMedia != nil && *options.SkipMedia
+	preserveHierarchy := options.PreserveDeckHierarchy != nil && *options.PreserveDeckHierarchy
+	private := options.Private != nil && *options.Private
+
+	pkg, err := readAnkiUpload(ctx, file, skipMedia)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaSkipped := skipMedia || pkg.MediaUnreadable
+	result := &model.AnkiImportResult{
+		Decks:        make([]*model.AnkiImportedDeck, 0),
+		SkippedNotes: make([]*model.AnkiSkippedNote, 0),
+		SkippedMedia: make([]string, 0),
+		MediaSkipped: &mediaSkipped,
+	}
+	skipNote := func(id int64, deck string, reason string) {
+		noteID := strconv.FormatInt(id, 10)
+		deckName, reasonText := deck, reason
+		result.SkippedNotes = append(result.SkippedNotes, &model.AnkiSkippedNote{
+			NoteID: &noteID,
+			Deck:   &deckName,
+			Reason: &reasonText,
+		})
+	}
+	for _, skipped := range pkg.Skipped {
+		skipNote(skipped.ID, skipped.Deck, skipped.Reason)
+	}
+
+	/* images are saved before the studysets, like normal uploads,
+	so if the import fails they're orphans that get cleaned up later */
+	imageIDs := make(map[string]*string)
+	imageID := func(name string) *string {
+		if name == "" || mediaSkipped || dryRun {
+			return nil
+		}
+		if id, ok := imageIDs[name]; ok {
+			return id
+		}
+		imageIDs[name] = nil
+		if len(imageIDs) > maxAnkiImportImages {
+			result.SkippedMedia = append(result.SkippedMedia, name)
+			return nil
+		}
+
+		data, err := pkg.Media(name)
+		if err != nil {
+			result.SkippedMedia = append(result.SkippedMedia, name)
+			return nil
+		}
+		processed, err := images.Process(data)
+		if err != nil {
+			result.SkippedMedia = append(result.SkippedMedia, name)
+			return nil
+		}
+		image, err := images.Save(ctx, r.DB, r.Storage, userID, processed)
+		if err != nil {
+			result.SkippedMedia = append(result.SkippedMedia, name)
+			return nil
+		}
+		imageIDs[name] = image.ID
+		return image.ID
+	}
+
+	type plannedStudyset struct {
+		input model.StudysetInput
+		terms []*model.NewTermInput
+	}
+	var planned []plannedStudyset
+	for _, group := range groupAnkiDecks(pkg.Decks, preserveHierarchy) {
+		terms := make([]*model.NewTermInput, 0, len(group.notes))
+		for _, note := range group.notes {
+			if len(terms) >= importer.MaxTerms {
+				skipNote(note.ID, group.title, fmt.Sprintf("a studyset can only have %d imported terms", importer.MaxTerms))
+				continue
+			}
+			newTerm := &model.NewTermInput{
+				SortOrder:   int32(len(terms)),
+				TermImageID: imageID(note.FrontImage),
+				DefImageID:  imageID(note.BackImage),
+			}
+			if note.Front != "" {
+				front := note.Front
+				newTerm.Term = &front
+			}
+			if note.Back != "" {
+				back := note.Back
+				newTerm.Def = &back
+			}
+			terms = append(terms, newTerm)
+		}
+
+		title := group.title
+		if len([]rune(title)) >= 200 {
+			title = string([]rune(title)[:199])
+		}
+		result.Decks = append(result.Decks, &model.AnkiImportedDeck{
+			Name:  &title,
+			Terms: terms,
+		})
+		planned = append(planned, plannedStudyset{
+			input: model.StudysetInput{Title: title, Private: private},
+			terms: terms,
+		})
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if len(planned) == 0 {
+		return nil, fmt.Errorf("no notes to import")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, p := range planned {
+		studyset, err := r.createStudysetTx(ctx, tx, userID, p.input, p.terms)
+		if err != nil {
+			return nil, err
+		}
+		result.Studysets = append(result.Studysets, studyset)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
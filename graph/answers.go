@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/language"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+// answerMatches checks a typed answer against a term/def's plain text
+// (not the markdown source, so `H~2~O` is answered with "H2O")
+// and any of its alternate accepted answers,
+// using the normalization rules for the answer's language (see language.NormalizeAnswer)
+func answerMatches(answered string, expectedPlain *string, alternates []string, lang string) bool {
+	normalized := language.NormalizeAnswer(answered, lang)
+	if normalized == "" {
+		return false
+	}
+	if expectedPlain != nil && normalized == language.NormalizeAnswer(*expectedPlain, lang) {
+		return true
+	}
+	for _, a := range alternates {
+		if normalized == language.NormalizeAnswer(a, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedAnswer is what counts as a correct answer for one side of a term,
+// and the language it's in
+func acceptedAnswer(term *model.Term, answerWith *model.AnswerWith) (*string, []string, string) {
+	if answerWith != nil && *answerWith == model.AnswerWithTerm {
+		return term.TermPlain, term.TermAlternates, stringOrEmpty(term.TermLanguage)
+	}
+	return term.DefPlain, term.DefAlternates, stringOrEmpty(term.DefLanguage)
+}
+
+// gradePracticeTest checks practice test answers on the server instead of trusting the client.
+// Free response answers are compared to the accepted answers (unless the user marked theirs correct),
+// and multiple choice/matching answers are also correct when the chosen term
+// is a different term with an accepted answer, like a duplicate term.
+// Each question's correct is set, and it returns how many questions were correct.
+func gradePracticeTest(ctx context.Context, db pgxscan.Querier, questions []*model.QuestionInput) (int32, error) {
+	termIDs := make([]string, 0)
+	for _, q := range questions {
+		if q == nil {
+			continue
+		}
+		if q.FrqInput != nil && q.FrqInput.Term != nil {
+			termIDs = append(termIDs, q.FrqInput.Term.ID)
+		}
+		if q.McqInput != nil && q.McqInput.Term != nil && q.McqInput.AnsweredTerm != nil {
+			termIDs = append(termIDs, q.McqInput.Term.ID, q.McqInput.AnsweredTerm.ID)
+		}
+		if q.MatchQuestionInput != nil && q.MatchQuestionInput.Term != nil && q.MatchQuestionInput.AnsweredTerm != nil {
+			termIDs = append(termIDs, q.MatchQuestionInput.Term.ID, q.MatchQuestionInput.AnsweredTerm.ID)
+		}
+	}
+
+	termsByID := make(map[string]*model.Term, len(termIDs))
+	if len(termIDs) > 0 {
+		var terms []*model.Term
+		err := pgxscan.Select(
+			ctx,
+			db,
+			&terms,
+			`SELECT t.id,
+	COALESCE(t.term_plain, t.term) AS term_plain, COALESCE(t.def_plain, t.def) AS def_plain,
+	t.term_alternates, t.def_alternates,
+	s.term_language, s.def_language
+FROM terms t
+JOIN public.studysets s ON s.id = t.studyset_id
+WHERE t.id = ANY($1::uuid[])`,
+			termIDs,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch terms for grading: %w", err)
+		}
+		for _, t := range terms {
+			termsByID[*t.ID] = t
+		}
+	}
+
+	/* a chosen term is also correct if its text is an accepted answer for the question's term */
+	chosenMatches := func(termID string, answeredTermID string, answerWith *model.AnswerWith) bool {
+		if termID == answeredTermID {
+			return true
+		}
+		term, answered := termsByID[termID], termsByID[answeredTermID]
+		if term == nil || answered == nil {
+			return false
+		}
+		answeredText, _, _ := acceptedAnswer(answered, answerWith)
+		if answeredText == nil {
+			return false
+		}
+		expected, alternates, lang := acceptedAnswer(term, answerWith)
+		return answerMatches(*answeredText, expected, alternates, lang)
+	}
+
+	var questionsCorrect int32
+	for _, q := range questions {
+		if q == nil {
+			continue
+		}
+		var correct *bool
+		switch {
+		case q.FrqInput != nil:
+			frq := q.FrqInput
+			if frq.Term != nil {
+				if term, ok := termsByID[frq.Term.ID]; ok {
+					isCorrect := frq.UserMarkedCorrect != nil && *frq.UserMarkedCorrect
+					if !isCorrect && frq.AnsweredString != nil {
+						expected, alternates, lang := acceptedAnswer(term, frq.AnswerWith)
+						isCorrect = answerMatches(*frq.AnsweredString, expected, alternates, lang)
+					}
+					frq.Correct = &isCorrect
+				}
+			}
+			correct = frq.Correct
+		case q.McqInput != nil:
+			mcq := q.McqInput
+			if mcq.Term != nil && mcq.AnsweredTerm != nil {
+				isCorrect := chosenMatches(mcq.Term.ID, mcq.AnsweredTerm.ID, mcq.AnswerWith)
+				mcq.Correct = &isCorrect
+			}
+			correct = mcq.Correct
+		case q.MatchQuestionInput != nil:
+			match := q.MatchQuestionInput
+			if match.Term != nil && match.AnsweredTerm != nil {
+				isCorrect := chosenMatches(match.Term.ID, match.AnsweredTerm.ID, match.AnswerWith)
+				match.Correct = &isCorrect
+			}
+			correct = match.Correct
+		case q.TrueFalseQuestionInput != nil:
+			correct = q.TrueFalseQuestionInput.Correct
+		}
+		if correct != nil && *correct {
+			questionsCorrect++
+		}
+	}
+
+	return questionsCorrect, nil
+}
@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"quizfreely/api/graph/model"
+	"testing"
+)
+
+func TestAnswerMatches(t *testing.T) {
+	plain := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		answered   string
+		expected   *string
+		alternates []string
+		lang       string
+		want       bool
+	}{
+		{"exact", "photosynthesis", plain("photosynthesis"), nil, "en", true},
+		{"case & spacing", "  Photo  Synthesis ", plain("photo synthesis"), nil, "en", true},
+		{"plain text of markdown", "H2O", plain("H2O"), nil, "", true},
+		{"wrong", "respiration", plain("photosynthesis"), nil, "en", false},
+		{"alternate", "color", plain("colour"), []string{"color"}, "en", true},
+		{"alternate without a def", "color", nil, []string{"colour", "color"}, "en", true},
+		{"blank answer", "  ", plain(""), []string{""}, "en", false},
+		{"turkish casing", "ISPARTA", plain("ısparta"), nil, "tr", true},
+		{"turkish casing only in turkish", "ISPARTA", plain("ısparta"), nil, "en", false},
+		{"german sharp s", "Strasse", plain("Straße"), nil, "de", true},
+		{"japanese spaces", "東京 タワー", plain("東京タワー"), nil, "ja", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := answerMatches(tt.answered, tt.expected, tt.alternates, tt.lang); got != tt.want {
+				t.Errorf("answerMatches(%q) = %v, want %v", tt.answered, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceptedAnswer(t *testing.T) {
+	termPlain, defPlain := "gato", "cat"
+	termLanguage, defLanguage := "es", "en"
+	term := &model.Term{
+		TermPlain:      &termPlain,
+		DefPlain:       &defPlain,
+		TermAlternates: []string{"el gato"},
+		DefAlternates:  []string{"kitty"},
+		TermLanguage:   &termLanguage,
+		DefLanguage:    &defLanguage,
+	}
+
+	withTerm := model.AnswerWithTerm
+	expected, alternates, lang := acceptedAnswer(term, &withTerm)
+	if *expected != "gato" || alternates[0] != "el gato" || lang != "es" {
+		t.Errorf("acceptedAnswer(TERM) = %q, %q, %q", *expected, alternates, lang)
+	}
+
+	/* answering with the def is the default */
+	expected, alternates, lang = acceptedAnswer(term, nil)
+	if *expected != "cat" || alternates[0] != "kitty" || lang != "en" {
+		t.Errorf("acceptedAnswer(nil) = %q, %q, %q", *expected, alternates, lang)
+	}
+}
+
+func TestCleanAlternates(t *testing.T) {
+	got := cleanAlternates([]string{" Color ", "color", "", "COLOR!", "İstanbul", "istanbul"}, "tr")
+	want := []string{"Color", "İstanbul"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("cleanAlternates() = %q, want %q", got, want)
+	}
+
+	/* in english, a dotted capital I isn't the same letter as i */
+	if got := cleanAlternates([]string{"İstanbul", "istanbul"}, "en"); len(got) != 2 {
+		t.Errorf("cleanAlternates() in english = %q, want both", got)
+	}
+
+	if got := cleanAlternates(nil, ""); got == nil {
+		t.Error("cleanAlternates(nil) = nil, want an empty list")
+	}
+	if got := updatedAlternates(nil, ""); got != nil {
+		t.Errorf("updatedAlternates(nil) = %q, want nil", got)
+	}
+}
@@ -0,0 +1,263 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const maxCommentLength = 2000
+
+// commentColumns are what a Comment is scanned from,
+// deleted comments are shown without their body or author
+const commentColumns = `id, studyset_id, term_id, parent_id,
+	CASE WHEN deleted_at IS NULL THEN user_id END AS user_id,
+	CASE WHEN deleted_at IS NULL THEN body END AS body,
+	deleted_at IS NOT NULL AS deleted,
+	to_char(edited_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as edited_at,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at`
+
+func validateCommentBody(body string) (string, error) {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return "", fmt.Errorf("comment can't be empty")
+	}
+	if utf8.RuneCountInString(trimmed) > maxCommentLength {
+		return "", fmt.Errorf("comment must be at most %d characters", maxCommentLength)
+	}
+	return trimmed, nil
+}
+
+// createComment comments on a public studyset, one of its terms, or replies to another comment.
+// Replies are always on the same studyset & term as the comment they reply to
+func (r *Resolver) createComment(ctx context.Context, userID *string, studysetID string, termID *string, parentID *string, body string) (*model.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.filterCommentContent(body); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID *string
+	var title string
+	err = tx.QueryRow(
+		ctx,
+		`SELECT user_id, title FROM public.studysets
+WHERE id = $1 AND private = false AND deleted_at IS NULL`,
+		studysetID,
+	).Scan(&ownerID, &title)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not found")
+		}
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	var parentAuthorID *string
+	if parentID != nil {
+		var parentTermID *string
+		err = tx.QueryRow(
+			ctx,
+			`SELECT user_id, term_id FROM studyset_comments
+WHERE id = $1 AND studyset_id = $2 AND deleted_at IS NULL`,
+			parentID,
+			studysetID,
+		).Scan(&parentAuthorID, &parentTermID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("comment not found")
+			}
+			return nil, fmt.Errorf("failed to create comment: %w", err)
+		}
+		if termID != nil && (parentTermID == nil || *parentTermID != *termID) {
+			return nil, fmt.Errorf("replies must be on the same term as the comment they reply to")
+		}
+		termID = parentTermID
+	} else if termID != nil {
+		var termExists bool
+		err = tx.QueryRow(
+			ctx,
+			"SELECT EXISTS (SELECT 1 FROM terms WHERE id = $1 AND studyset_id = $2 AND deleted_at IS NULL)",
+			termID,
+			studysetID,
+		).Scan(&termExists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create comment: %w", err)
+		}
+		if !termExists {
+			return nil, fmt.Errorf("term not found")
+		}
+	}
+
+	var comment model.Comment
+	err = pgxscan.Get(
+		ctx,
+		tx,
+		&comment,
+		`INSERT INTO studyset_comments (studyset_id, term_id, parent_id, user_id, body)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING `+commentColumns,
+		studysetID,
+		termID,
+		parentID,
+		userID,
+		body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	notification := &model.CommentNotification{
+		StudysetID: &studysetID,
+		Title:      &title,
+		TermID:     termID,
+		CommentID:  comment.ID,
+		UserID:     userID,
+	}
+	/* the studyset's owner hears about every comment, other people only hear about replies to them */
+	if ownerID != nil && (userID == nil || *ownerID != *userID) {
+		if err := notify(ctx, tx, *ownerID, model.NotificationKindStudysetComment, notification); err != nil {
+			return nil, err
+		}
+	}
+	if parentAuthorID != nil && (userID == nil || *parentAuthorID != *userID) && (ownerID == nil || *parentAuthorID != *ownerID) {
+		if err := notify(ctx, tx, *parentAuthorID, model.NotificationKindCommentReply, notification); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &comment, nil
+}
+
+// updateComment changes the body of a user's own comment,
+// while its studyset is public
+func (r *Resolver) updateComment(ctx context.Context, userID *string, commentID string, body string) (*model.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.filterCommentContent(body); err != nil {
+		return nil, err
+	}
+
+	var comment model.Comment
+	err = pgxscan.Get(
+		ctx,
+		r.DB,
+		&comment,
+		`UPDATE studyset_comments
+SET body = $3, edited_at = now(), updated_at = now()
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	AND EXISTS (
+		SELECT 1 FROM public.studysets s
+		WHERE s.id = studyset_comments.studyset_id
+			AND s.private = false
+			AND s.deleted_at IS NULL
+	)
+RETURNING `+commentColumns,
+		commentID,
+		userID,
+		body,
+	)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("comment not found")
+		}
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return &comment, nil
+}
+
+// deleteComment deletes a comment, if userID wrote it or is a moderator.
+// Comments with replies are only emptied, so their replies stay in their thread,
+// and emptied comments are deleted once their last reply is
+func (r *Resolver) deleteComment(ctx context.Context, userID *string, moderator bool, commentID string) (*string, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var authorID string
+	var parentID *string
+	var hasReplies bool
+	err = tx.QueryRow(
+		ctx,
+		`SELECT user_id, parent_id,
+	EXISTS (SELECT 1 FROM studyset_comments r WHERE r.parent_id = c.id)
+FROM studyset_comments c
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE`,
+		commentID,
+	).Scan(&authorID, &parentID, &hasReplies)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment not found")
+		}
+		return nil, fmt.Errorf("failed to delete comment: %w", err)
+	}
+	if !moderator && (userID == nil || *userID != authorID) {
+		return nil, fmt.Errorf("comment not found")
+	}
+
+	if hasReplies {
+		_, err = tx.Exec(
+			ctx,
+			"UPDATE studyset_comments SET body = '', deleted_at = now(), updated_at = now() WHERE id = $1",
+			commentID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete comment: %w", err)
+		}
+	} else {
+		_, err = tx.Exec(ctx, "DELETE FROM studyset_comments WHERE id = $1", commentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete comment: %w", err)
+		}
+
+		/* emptied comments above it that don't have replies anymore */
+		for parentID != nil {
+			var nextParentID *string
+			err = tx.QueryRow(
+				ctx,
+				`DELETE FROM studyset_comments c
+WHERE c.id = $1 AND c.deleted_at IS NOT NULL
+	AND NOT EXISTS (SELECT 1 FROM studyset_comments r WHERE r.parent_id = c.id)
+RETURNING c.parent_id`,
+				parentID,
+			).Scan(&nextParentID)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					break
+				}
+				return nil, fmt.Errorf("failed to delete comment: %w", err)
+			}
+			parentID = nextParentID
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &commentID, nil
+}
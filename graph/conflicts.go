@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"quizfreely/api/graph/model"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ConflictCode is the error code (in the error's extensions) for edits
+// that were based on an old version of a studyset or term
+const ConflictCode = "CONFLICT"
+
+// checkStudysetVersions locks a studyset (so other edits wait for this one)
+// and checks that it & its terms are still at the versions the client last saw.
+// A nil expected version skips that check, so older clients still work.
+// When something changed, it returns a CONFLICT error with the studyset's
+// current state, so the client can merge its edits into it and try again
+func checkStudysetVersions(ctx context.Context, tx pgx.Tx, studysetID string, userID *string, expectedVersion *int32, terms []*model.TermInput) error {
+	var currentVersion int32
+	err := tx.QueryRow(
+		ctx,
+		`SELECT version FROM public.studysets
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+FOR UPDATE`,
+		studysetID,
+		userID,
+	).Scan(&currentVersion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("studyset not found")
+		}
+		return fmt.Errorf("failed to check studyset version: %w", err)
+	}
+
+	var termIDs []string
+	var expectedTermVersions []int32
+	for _, term := range terms {
+		if term != nil && term.ExpectedVersion != nil {
+			termIDs = append(termIDs, term.ID)
+			expectedTermVersions = append(expectedTermVersions, *term.ExpectedVersion)
+		}
+	}
+
+	conflictingTermIDs := make([]string, 0)
+	if len(termIDs) > 0 {
+		/* terms that were deleted (or moved) since the client saw them conflict too */
+		rows, err := tx.Query(
+			ctx,
+			`SELECT input.id::text
+FROM unnest($1::uuid[], $2::int[]) AS input(id, expected_version)
+LEFT JOIN terms t
+	ON t.id = input.id
+	AND t.studyset_id = $3
+	AND t.deleted_at IS NULL
+WHERE t.version IS DISTINCT FROM input.expected_version`,
+			termIDs,
+			expectedTermVersions,
+			studysetID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check term versions: %w", err)
+		}
+		conflictingTermIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("failed to check term versions: %w", err)
+		}
+	}
+
+	if (expectedVersion == nil || *expectedVersion == currentVersion) && len(conflictingTermIDs) == 0 {
+		return nil
+	}
+
+	current, err := currentStudysetState(ctx, tx, studysetID)
+	if err != nil {
+		return err
+	}
+	return &gqlerror.Error{
+		Message: "studyset was changed since it was loaded",
+		Extensions: map[string]interface{}{
+			"code":               ConflictCode,
+			"currentVersion":     currentVersion,
+			"conflictingTermIds": conflictingTermIDs,
+			"studyset":           current,
+		},
+	}
+}
+
+// currentStudysetState gets a studyset with all of its terms,
+// for the server state that conflict errors include
+func currentStudysetState(ctx context.Context, tx pgx.Tx, studysetID string) (*model.Studyset, error) {
+	var studyset model.Studyset
+	err := pgxscan.Get(
+		ctx,
+		tx,
+		&studyset,
+		`SELECT id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM public.studysets
+WHERE id = $1`,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current studyset: %w", err)
+	}
+
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&studyset.Terms,
+		`SELECT t.id, t.studyset_id, t.term, t.def, t.sort_order,
+	t.term_image_id, t.def_image_id,
+	t.term_html, t.def_html, t.term_plain, t.def_plain,
+	t.term_alternates, t.def_alternates, t.hint, t.version,
+	to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(t.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM terms t
+WHERE t.studyset_id = $1 AND t.deleted_at IS NULL
+ORDER BY t.sort_order`,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current terms: %w", err)
+	}
+
+	return &studyset, nil
+}
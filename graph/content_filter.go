@@ -0,0 +1,183 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"quizfreely/api/contentfilter"
+	"quizfreely/api/graph/model"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ContentRejectedCode is the error code (in the error's extensions)
+// for public studysets the content filter won't allow
+const ContentRejectedCode = "CONTENT_REJECTED"
+
+// filterStudysetContent checks a public studyset's title & terms with the content filter,
+// after they're changed but before the change is committed.
+// Rejected content is an error (so the transaction gets rolled back),
+// other content can be forced private or queued for moderators to review.
+// It returns true if the studyset was forced private, so callers can update what they return.
+// Private studysets and a Resolver without a ContentFilter skip filtering
+func (r *Resolver) filterStudysetContent(ctx context.Context, tx pgx.Tx, studysetID string) (bool, error) {
+	if r.ContentFilter == nil {
+		return false, nil
+	}
+
+	var title string
+	var private bool
+	var termLanguage, defLanguage *string
+	err := tx.QueryRow(
+		ctx,
+		`SELECT title, private, term_language, def_language
+FROM public.studysets
+WHERE id = $1 AND deleted_at IS NULL`,
+		studysetID,
+	).Scan(&title, &private, &termLanguage, &defLanguage)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, fmt.Errorf("studyset not found")
+		}
+		return false, fmt.Errorf("failed to check studyset content: %w", err)
+	}
+	if private {
+		return false, nil
+	}
+
+	termLang, defLang := "", ""
+	if termLanguage != nil {
+		termLang = *termLanguage
+	}
+	if defLanguage != nil {
+		defLang = *defLanguage
+	}
+
+	texts := []contentfilter.Text{{Content: title}}
+	rows, err := tx.Query(
+		ctx,
+		`SELECT COALESCE(term, ''), COALESCE(def, '')
+FROM terms
+WHERE studyset_id = $1 AND deleted_at IS NULL
+ORDER BY sort_order`,
+		studysetID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to check studyset content: %w", err)
+	}
+	for rows.Next() {
+		var term, def string
+		if err := rows.Scan(&term, &def); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to check studyset content: %w", err)
+		}
+		texts = append(texts,
+			contentfilter.Text{Content: term, Language: termLang},
+			contentfilter.Text{Content: def, Language: defLang},
+		)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("failed to check studyset content: %w", err)
+	}
+
+	verdict := r.ContentFilter.Check(texts)
+	switch verdict.Action {
+	case contentfilter.Reject:
+		return false, contentRejectedError("studyset can't be public, some of its content isn't allowed", verdict, contentfilter.Reject)
+	case contentfilter.ForcePrivate:
+		_, err := tx.Exec(ctx, "UPDATE public.studysets SET private = true WHERE id = $1", studysetID)
+		if err != nil {
+			return false, fmt.Errorf("failed to make studyset private: %w", err)
+		}
+		if err := removeHiddenStars(ctx, tx, studysetID); err != nil {
+			return false, err
+		}
+		return true, nil
+	case contentfilter.Review:
+		if err := reportFilteredStudyset(ctx, tx, studysetID, verdict); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// filterCommentContent checks a comment with the content filter,
+// comments can't be made private, so anything that would make a studyset private is rejected
+func (r *Resolver) filterCommentContent(body string) error {
+	if r.ContentFilter == nil {
+		return nil
+	}
+
+	verdict := r.ContentFilter.Check([]contentfilter.Text{{Content: body}})
+	if verdict.Action >= contentfilter.ForcePrivate {
+		return contentRejectedError("some of this comment isn't allowed", verdict, contentfilter.ForcePrivate)
+	}
+	return nil
+}
+
+// contentRejectedError is a CONTENT_REJECTED error,
+// with the matches that are at least minAction as its reasons
+func contentRejectedError(message string, verdict contentfilter.Verdict, minAction contentfilter.Action) error {
+	reasons := make([]map[string]interface{}, 0, len(verdict.Matches))
+	for _, m := range verdict.Matches {
+		if m.Action >= minAction {
+			reasons = append(reasons, map[string]interface{}{
+				"rule":  m.Rule,
+				"match": m.Match,
+			})
+		}
+	}
+	return &gqlerror.Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"code":    ContentRejectedCode,
+			"reasons": reasons,
+		},
+	}
+}
+
+// reportFilteredStudyset adds (or updates) the content filter's own report on a studyset,
+// so it shows up in the moderation queue like reports from users
+func reportFilteredStudyset(ctx context.Context, tx pgx.Tx, studysetID string, verdict contentfilter.Verdict) error {
+	reason := model.ReportReasonSpam
+	var details []string
+	for _, m := range verdict.Matches {
+		if m.Action != contentfilter.Review {
+			continue
+		}
+		/* word list rules are named like "en/review", the heuristics are spam */
+		if strings.Contains(m.Rule, "/") {
+			reason = model.ReportReasonInappropriate
+		}
+		if m.Match != "" {
+			details = append(details, fmt.Sprintf("%s: %s", m.Rule, m.Match))
+		} else {
+			details = append(details, m.Rule)
+		}
+	}
+
+	text := "content filter: " + strings.Join(details, ", ")
+	if runes := []rune(text); len(runes) > maxReportDetailsLength {
+		text = string(runes[:maxReportDetailsLength])
+	}
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO studyset_reports (studyset_id, reporter_id, reason, details)
+VALUES ($1, NULL, $2, $3)
+ON CONFLICT (studyset_id) WHERE reporter_id IS NULL AND status = 'OPEN' DO UPDATE SET
+	reason = EXCLUDED.reason,
+	details = EXCLUDED.details,
+	updated_at = now()`,
+		studysetID,
+		reason,
+		text,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to report studyset for review: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"strconv"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultDuplicateThreshold is how similar (pg_trgm similarity, 0 to 1)
+// a term's term or def has to be to another's for them to be near duplicates
+const DefaultDuplicateThreshold = 0.6
+
+// findDuplicateTerms finds pairs of terms in a studyset whose terms or defs are
+// at least threshold similar, and groups terms that are connected by pairs,
+// so A ~ B and B ~ C is one group of A, B, and C. Groups are in the order of their first term.
+// It sets pg_trgm's similarity threshold for the rest of tx, so the % operator can use the trigram indexes
+func findDuplicateTerms(ctx context.Context, tx pgx.Tx, studysetID string, threshold float64) ([]*model.DuplicateTermGroup, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be more than 0 and at most 1")
+	}
+
+	_, err := tx.Exec(
+		ctx,
+		`SELECT set_config('pg_trgm.similarity_threshold', $1, true)`,
+		strconv.FormatFloat(threshold, 'f', -1, 64),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set similarity threshold: %w", err)
+	}
+
+	var pairs []*model.DuplicateTermPair
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&pairs,
+		`WITH candidates AS (
+	SELECT a.id AS term_id, b.id AS duplicate_term_id
+	FROM terms a
+	JOIN terms b ON b.term_plain % a.term_plain
+	WHERE a.studyset_id = $1 AND a.deleted_at IS NULL
+		AND b.studyset_id = $1 AND b.deleted_at IS NULL
+		AND (b.sort_order, b.id) > (a.sort_order, a.id)
+	UNION
+	SELECT a.id, b.id
+	FROM terms a
+	JOIN terms b ON b.def_plain % a.def_plain
+	WHERE a.studyset_id = $1 AND a.deleted_at IS NULL
+		AND b.studyset_id = $1 AND b.deleted_at IS NULL
+		AND (b.sort_order, b.id) > (a.sort_order, a.id)
+	UNION
+	SELECT a.id, b.id
+	FROM terms a
+	JOIN terms b ON lower(trim(coalesce(a.term_plain, ''))) = lower(trim(coalesce(b.term_plain, '')))
+		AND lower(trim(coalesce(a.def_plain, ''))) = lower(trim(coalesce(b.def_plain, '')))
+		AND a.term_image_id IS NOT DISTINCT FROM b.term_image_id
+		AND a.def_image_id IS NOT DISTINCT FROM b.def_image_id
+	WHERE a.studyset_id = $1 AND a.deleted_at IS NULL
+		AND b.studyset_id = $1 AND b.deleted_at IS NULL
+		AND (b.sort_order, b.id) > (a.sort_order, a.id)
+)
+SELECT term_id, duplicate_term_id, term_similarity, def_similarity,
+	greatest(term_similarity, def_similarity) AS score,
+	exact
+FROM (
+	SELECT c.term_id, c.duplicate_term_id,
+		a.sort_order, b.sort_order AS duplicate_sort_order,
+		similarity(coalesce(a.term_plain, ''), coalesce(b.term_plain, ''))::float8 AS term_similarity,
+		similarity(coalesce(a.def_plain, ''), coalesce(b.def_plain, ''))::float8 AS def_similarity,
+		lower(trim(coalesce(a.term_plain, ''))) = lower(trim(coalesce(b.term_plain, '')))
+			AND lower(trim(coalesce(a.def_plain, ''))) = lower(trim(coalesce(b.def_plain, '')))
+			AND a.term_image_id IS NOT DISTINCT FROM b.term_image_id
+			AND a.def_image_id IS NOT DISTINCT FROM b.def_image_id AS exact
+	FROM candidates c
+	JOIN terms a ON a.id = c.term_id
+	JOIN terms b ON b.id = c.duplicate_term_id
+) p
+ORDER BY sort_order, duplicate_sort_order`,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate terms: %w", err)
+	}
+
+	return groupDuplicatePairs(pairs), nil
+}
+
+// groupDuplicatePairs groups terms connected by pairs, pairs have to be in the order of their first term
+func groupDuplicatePairs(pairs []*model.DuplicateTermPair) []*model.DuplicateTermGroup {
+	/* union-find, pairs are ordered so the first term of a group is its root */
+	parents := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		parent, ok := parents[id]
+		if !ok {
+			parents[id] = id
+			return id
+		}
+		if parent == id {
+			return id
+		}
+		root := find(parent)
+		parents[id] = root
+		return root
+	}
+	order := make([]string, 0)
+	for _, p := range pairs {
+		for _, id := range []string{*p.TermID, *p.DuplicateTermID} {
+			if _, ok := parents[id]; !ok {
+				order = append(order, id)
+			}
+		}
+		a, b := find(*p.TermID), find(*p.DuplicateTermID)
+		if a != b {
+			parents[b] = a
+		}
+	}
+
+	groups := make([]*model.DuplicateTermGroup, 0)
+	groupsByRoot := make(map[string]*model.DuplicateTermGroup)
+	for _, id := range order {
+		root := find(id)
+		group, ok := groupsByRoot[root]
+		if !ok {
+			group = &model.DuplicateTermGroup{Pairs: []*model.DuplicateTermPair{}}
+			groupsByRoot[root] = group
+			groups = append(groups, group)
+		}
+		group.TermIDs = append(group.TermIDs, id)
+	}
+	for _, p := range pairs {
+		group := groupsByRoot[find(*p.TermID)]
+		group.Pairs = append(group.Pairs, p)
+	}
+
+	return groups
+}
@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"quizfreely/api/graph/model"
+	"slices"
+	"testing"
+)
+
+func TestGroupDuplicatePairs(t *testing.T) {
+	pair := func(a string, b string) *model.DuplicateTermPair {
+		return &model.DuplicateTermPair{TermID: &a, DuplicateTermID: &b}
+	}
+
+	tests := []struct {
+		name       string
+		pairs      []*model.DuplicateTermPair
+		wantGroups [][]string
+		wantPairs  []int
+	}{
+		{"no pairs", nil, [][]string{}, []int{}},
+		{"one pair", []*model.DuplicateTermPair{pair("a", "b")}, [][]string{{"a", "b"}}, []int{1}},
+		{
+			"chain is one group",
+			[]*model.DuplicateTermPair{pair("a", "b"), pair("b", "c")},
+			[][]string{{"a", "b", "c"}},
+			[]int{2},
+		},
+		{
+			"separate groups stay in order",
+			[]*model.DuplicateTermPair{pair("a", "b"), pair("c", "d"), pair("a", "e")},
+			[][]string{{"a", "b", "e"}, {"c", "d"}},
+			[]int{2, 1},
+		},
+		{
+			"later pair joins two groups",
+			[]*model.DuplicateTermPair{pair("a", "b"), pair("c", "d"), pair("b", "d")},
+			[][]string{{"a", "b", "c", "d"}},
+			[]int{3},
+		},
+		{
+			"triangle",
+			[]*model.DuplicateTermPair{pair("a", "b"), pair("a", "c"), pair("b", "c")},
+			[][]string{{"a", "b", "c"}},
+			[]int{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupDuplicatePairs(tt.pairs)
+			if len(groups) != len(tt.wantGroups) {
+				t.Fatalf("groupDuplicatePairs() = %d groups, want %d", len(groups), len(tt.wantGroups))
+			}
+			for i, group := range groups {
+				if !slices.Equal(group.TermIDs, tt.wantGroups[i]) {
+					t.Errorf("group %d = %q, want %q", i, group.TermIDs, tt.wantGroups[i])
+				}
+				if len(group.Pairs) != tt.wantPairs[i] {
+					t.Errorf("group %d has %d pairs, want %d", i, len(group.Pairs), tt.wantPairs[i])
+				}
+			}
+		})
+	}
+}
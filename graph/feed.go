@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"strconv"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// an update is substantial (and shows up in followers' feeds) if it changes
+// at least this many terms, or at least a quarter of the studyset's terms
+const minSubstantialTermChanges = 5
+
+// recordFeedEvent adds a studyset to its owner's followers' feeds,
+// if it's public. A studyset is only PUBLISHED once,
+// and only gets one feed event a day, so editing it a lot doesn't flood feeds
+func recordFeedEvent(ctx context.Context, tx pgx.Tx, studysetID string, kind model.FeedEventKind) error {
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO studyset_feed_events (studyset_id, user_id, kind)
+SELECT s.id, s.user_id, $2::text
+FROM public.studysets s
+WHERE s.id = $1
+	AND s.private = false
+	AND s.deleted_at IS NULL
+	AND s.user_id IS NOT NULL
+	AND NOT EXISTS (
+		SELECT 1 FROM studyset_feed_events e
+		WHERE e.studyset_id = s.id
+			AND ((e.kind = 'PUBLISHED' AND $2::text = 'PUBLISHED') OR e.created_at > now() - interval '1 day')
+	)`,
+		studysetID,
+		kind,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record feed event: %w", err)
+	}
+	return nil
+}
+
+// recordStudysetUpdateEvent records a feed event for an updated studyset:
+// PUBLISHED if it was private before, or UPDATED if enough of its terms changed
+func recordStudysetUpdateEvent(ctx context.Context, tx pgx.Tx, studysetID string, wasPrivate bool, changedTerms int) error {
+	if wasPrivate {
+		return recordFeedEvent(ctx, tx, studysetID, model.FeedEventKindPublished)
+	}
+	if changedTerms == 0 {
+		return nil
+	}
+
+	if changedTerms < minSubstantialTermChanges {
+		var termsCount int
+		err := tx.QueryRow(
+			ctx,
+			"SELECT count(*) FROM terms WHERE studyset_id = $1 AND deleted_at IS NULL",
+			studysetID,
+		).Scan(&termsCount)
+		if err != nil {
+			return fmt.Errorf("failed to count terms: %w", err)
+		}
+		if changedTerms*4 < termsCount {
+			return nil
+		}
+	}
+
+	return recordFeedEvent(ctx, tx, studysetID, model.FeedEventKindUpdated)
+}
+
+func encodeFeedCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("feed:" + strconv.FormatInt(id, 10)))
+}
+
+func decodeFeedCursor(cursor string) (int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(decoded) < 6 || string(decoded[:5]) != "feed:" {
+		return 0, errors.New("invalid cursor")
+	}
+	id, err := strconv.ParseInt(string(decoded[5:]), 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	return id, nil
+}
+
+// myFeed lists feed events from users that userID follows, newest first.
+// Studysets that were made private, deleted, or given away since their event are left out
+func (r *Resolver) myFeed(ctx context.Context, userID *string, limit int, after *string) (*model.StudysetFeed, error) {
+	var afterID *int64
+	if after != nil && *after != "" {
+		id, err := decodeFeedCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		afterID = &id
+	}
+
+	var items []*model.StudysetFeedItem
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&items,
+		`SELECT e.id, e.kind, e.studyset_id,
+	to_char(e.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at
+FROM studyset_feed_events e
+JOIN user_follows uf ON uf.followee_id = e.user_id AND uf.follower_id = $1
+JOIN public.studysets s ON s.id = e.studyset_id
+WHERE ($2::bigint IS NULL OR e.id < $2)
+	AND s.private = false
+	AND s.deleted_at IS NULL
+	AND s.user_id = e.user_id
+ORDER BY e.id DESC
+LIMIT $3`,
+		userID,
+		afterID,
+		/* one extra, to know if there's a next page */
+		limit+1,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	hasNextPage := len(items) > limit
+	if hasNextPage {
+		items = items[:limit]
+	}
+	feed := &model.StudysetFeed{
+		Items:       items,
+		HasNextPage: &hasNextPage,
+	}
+	if len(items) == 0 {
+		feed.Items = []*model.StudysetFeedItem{}
+		return feed, nil
+	}
+
+	studysetIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		cursor := encodeFeedCursor(item.ID)
+		item.Cursor = &cursor
+		studysetIDs = append(studysetIDs, *item.StudysetID)
+	}
+	feed.EndCursor = items[len(items)-1].Cursor
+
+	var studysets []*model.Studyset
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&studysets,
+		`SELECT id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM public.studysets
+WHERE id = ANY($1)`,
+		studysetIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed studysets: %w", err)
+	}
+	byID := make(map[string]*model.Studyset, len(studysets))
+	for _, s := range studysets {
+		byID[*s.ID] = s
+	}
+	for _, item := range items {
+		item.Studyset = byID[*item.StudysetID]
+	}
+
+	return feed, nil
+}
+
+// followUser makes followerID follow followeeID, following again does nothing
+// (and doesn't notify them again)
+func (r *Resolver) followUser(ctx context.Context, followerID *string, followeeID string) (*model.User, error) {
+	if followerID != nil && *followerID == followeeID {
+		return nil, fmt.Errorf("you can't follow yourself")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var followedID string
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO user_follows (follower_id, followee_id)
+SELECT $1, u.id FROM auth.users u WHERE u.id = $2
+ON CONFLICT (follower_id, followee_id) DO NOTHING
+RETURNING followee_id`,
+		followerID,
+		followeeID,
+	).Scan(&followedID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to follow user: %w", err)
+	}
+	if err == nil {
+		err = notify(ctx, tx, followedID, model.NotificationKindNewFollower, &model.FollowNotification{
+			UserID: followerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.getProfile(ctx, "id", followeeID)
+}
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// checkImagesOwned makes sure every image id was uploaded by userID,
+// so nobody can attach someone else's uploads to their terms
+func checkImagesOwned(ctx context.Context, tx pgx.Tx, userID *string, imageIDs []string) error {
+	if len(imageIDs) == 0 {
+		return nil
+	}
+
+	var notOwnedCount int
+	err := tx.QueryRow(
+		ctx,
+		`SELECT count(*)
+FROM unnest($1::uuid[]) AS input(id)
+LEFT JOIN images i ON i.id = input.id AND i.user_id = $2
+WHERE i.id IS NULL`,
+		imageIDs,
+		userID,
+	).Scan(&notOwnedCount)
+	if err != nil {
+		return fmt.Errorf("failed to check images: %w", err)
+	}
+	if notOwnedCount > 0 {
+		return fmt.Errorf("image not found")
+	}
+	return nil
+}
+
+func checkNewTermImagesOwned(ctx context.Context, tx pgx.Tx, userID *string, terms []*model.NewTermInput) error {
+	imageIDs := make([]string, 0)
+	for _, t := range terms {
+		if t.TermImageID != nil {
+			imageIDs = append(imageIDs, *t.TermImageID)
+		}
+		if t.DefImageID != nil {
+			imageIDs = append(imageIDs, *t.DefImageID)
+		}
+	}
+	return checkImagesOwned(ctx, tx, userID, imageIDs)
+}
+
+// checkTermImagesOwned is checkImagesOwned for terms being updated,
+// images that are already on the same term can stay even if someone else uploaded them,
+// like the ones copied in by merging someone else's studyset
+func checkTermImagesOwned(ctx context.Context, tx pgx.Tx, userID *string, studysetID string, terms []*model.TermInput) error {
+	termIDs := make([]string, 0)
+	imageIDs := make([]string, 0)
+	for _, t := range terms {
+		if t.TermImageID != nil {
+			termIDs = append(termIDs, t.ID)
+			imageIDs = append(imageIDs, *t.TermImageID)
+		}
+		if t.DefImageID != nil {
+			termIDs = append(termIDs, t.ID)
+			imageIDs = append(imageIDs, *t.DefImageID)
+		}
+	}
+	if len(imageIDs) == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT input.image_id::text
+FROM unnest($1::uuid[], $2::uuid[]) AS input(term_id, image_id)
+WHERE NOT EXISTS (
+	SELECT 1 FROM terms t
+	WHERE t.id = input.term_id AND t.studyset_id = $3
+		AND input.image_id IN (t.term_image_id, t.def_image_id)
+)`,
+		termIDs,
+		imageIDs,
+		studysetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check images: %w", err)
+	}
+	newImageIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to check images: %w", err)
+	}
+	return checkImagesOwned(ctx, tx, userID, newImageIDs)
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/importer"
+	"strings"
+	"unicode/utf8"
+)
+
+// importOptions turns an ImportStudysetInput into importer.Options,
+// uploaded .csv files default to CSV, everything else defaults to tab separated lines
+func importOptions(input model.ImportStudysetInput) importer.Options {
+	var opts importer.Options
+	if input.File != nil &&
+		(strings.HasSuffix(strings.ToLower(input.File.Filename), ".csv") || input.File.ContentType == "text/csv") {
+		opts = importer.CSVOptions
+	}
+	if input.TermDefSeparator != nil {
+		opts.TermDefSeparator = *input.TermDefSeparator
+	}
+	if input.CardSeparator != nil {
+		opts.CardSeparator = *input.CardSeparator
+	}
+	if input.Quote != nil {
+		opts.Quote = *input.Quote
+	}
+	if input.HeaderRow != nil {
+		opts.HeaderRow = *input.HeaderRow
+	}
+	return opts
+}
+
+// readImportText gets the text to import from either the pasted text or the uploaded file
+func readImportText(input model.ImportStudysetInput) (string, error) {
+	var text string
+	switch {
+	case input.Text != nil && input.File != nil:
+		return "", fmt.Errorf("import either text or a file, not both")
+	case input.Text != nil:
+		text = *input.Text
+	case input.File != nil:
+		data, err := io.ReadAll(io.LimitReader(input.File.File, importer.MaxTextBytes+1))
+		if err != nil {
+			return "", fmt.Errorf("failed to read import file: %w", err)
+		}
+		text = string(data)
+	default:
+		return "", fmt.Errorf("nothing to import, text or file is required")
+	}
+
+	if len(text) > importer.MaxTextBytes {
+		return "", fmt.Errorf("import is too large, the limit is %d MB", importer.MaxTextBytes>>20)
+	}
+	if !utf8.ValidString(text) {
+		return "", fmt.Errorf("import must be UTF-8 text")
+	}
+	return text, nil
+}
+
+// parseImport parses an import into the NewTermInputs it would create
+func parseImport(input model.ImportStudysetInput) ([]*model.NewTermInput, []*model.ImportWarning, error) {
+	text, err := readImportText(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cards, warnings, err := importer.Parse(text, importOptions(input))
+	if err != nil {
+		if errors.Is(err, importer.ErrSameSeparators) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to parse import: %w", err)
+	}
+
+	terms := make([]*model.NewTermInput, 0, len(cards))
+	for i, card := range cards {
+		newTerm := &model.NewTermInput{SortOrder: int32(i)}
+		if card.Term != "" {
+			term := card.Term
+			newTerm.Term = &term
+		}
+		if card.Def != "" {
+			def := card.Def
+			newTerm.Def = &def
+		}
+		terms = append(terms, newTerm)
+	}
+
+	importWarnings := make([]*model.ImportWarning, 0, len(warnings))
+	for _, w := range warnings {
+		line := int32(w.Line)
+		message := w.Message
+		importWarnings = append(importWarnings, &model.ImportWarning{
+			Line:    &line,
+			Message: &message,
+		})
+	}
+
+	return terms, importWarnings, nil
+}
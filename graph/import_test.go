@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"quizfreely/api/graph/model"
+	"quizfreely/api/importer"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+func TestImportOptions(t *testing.T) {
+	upload := func(filename string, contentType string) *graphql.Upload {
+		return &graphql.Upload{File: strings.NewReader(""), Filename: filename, ContentType: contentType}
+	}
+	comma := ","
+
+	tests := []struct {
+		name  string
+		input model.ImportStudysetInput
+		want  importer.Options
+	}{
+		{"pasted text uses the defaults", model.ImportStudysetInput{}, importer.Options{}},
+		{".csv file", model.ImportStudysetInput{File: upload("Cards.CSV", "")}, importer.CSVOptions},
+		{"csv content type", model.ImportStudysetInput{File: upload("cards", "text/csv")}, importer.CSVOptions},
+		{"other files use the defaults", model.ImportStudysetInput{File: upload("cards.txt", "text/plain")}, importer.Options{}},
+		{
+			"separators override the file type",
+			model.ImportStudysetInput{File: upload("cards.tsv", ""), TermDefSeparator: &comma},
+			importer.Options{TermDefSeparator: ","},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := importOptions(tt.input); got != tt.want {
+				t.Errorf("importOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImport(t *testing.T) {
+	text := "cat\tgato\n\ndog\tperro\nbird\n"
+	terms, warnings, err := parseImport(model.ImportStudysetInput{Text: &text})
+	if err != nil {
+		t.Fatalf("parseImport() error = %v", err)
+	}
+	/* a dry run returns these as-is, so the counts are what the preview shows */
+	if len(terms) != 3 || len(warnings) != 1 {
+		t.Fatalf("parseImport() = %d terms & %d warnings, want 3 & 1", len(terms), len(warnings))
+	}
+	if *terms[2].Term != "bird" || terms[2].Def != nil || terms[2].SortOrder != 2 {
+		t.Errorf("parseImport() last term = %+v, want bird without a def", terms[2])
+	}
+	if *warnings[0].Line != 4 {
+		t.Errorf("parseImport() warning line = %d, want 4", *warnings[0].Line)
+	}
+
+	file := &graphql.Upload{File: strings.NewReader("a,b"), Filename: "cards.csv"}
+	for name, input := range map[string]model.ImportStudysetInput{
+		"nothing":       {},
+		"text and file": {Text: &text, File: file},
+		"too large":     {File: &graphql.Upload{File: strings.NewReader(strings.Repeat("a", importer.MaxTextBytes+1))}},
+		"not utf-8":     {File: &graphql.Upload{File: strings.NewReader("\xff\xfe")}},
+	} {
+		if _, _, err := parseImport(input); err == nil {
+			t.Errorf("parseImport() with %s succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/language"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// studysetLanguages are a StudysetInput's validated languages & their text search configs,
+// nil means the language wasn't in the input (so it shouldn't change),
+// and "" means it was cleared
+type studysetLanguages struct {
+	termLanguage     *string
+	defLanguage      *string
+	termSearchConfig *string
+	defSearchConfig  *string
+}
+
+// allSearchConfigs are every text search config a studyset or term can have,
+// searches parse their query once for each of them
+var allSearchConfigs = language.SearchConfigs()
+
+func parseLanguage(tag *string) (*string, *string, error) {
+	if tag == nil {
+		return nil, nil, nil
+	}
+	normalized, err := language.Normalize(*tag)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := language.SearchConfig(normalized)
+	return &normalized, &config, nil
+}
+
+func parseStudysetLanguages(input model.StudysetInput) (*studysetLanguages, error) {
+	var langs studysetLanguages
+	var err error
+	langs.termLanguage, langs.termSearchConfig, err = parseLanguage(input.TermLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid term language")
+	}
+	langs.defLanguage, langs.defSearchConfig, err = parseLanguage(input.DefLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid def language")
+	}
+	return &langs, nil
+}
+
+// syncTermSearchConfigs copies a studyset's text search configs to its terms,
+// for after its languages change or terms get put back in it
+func syncTermSearchConfigs(ctx context.Context, tx pgx.Tx, studysetID string) error {
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE terms t
+SET term_search_config = s.term_search_config, def_search_config = s.def_search_config
+FROM public.studysets s
+WHERE s.id = $1 AND t.studyset_id = s.id
+	AND (t.term_search_config <> s.term_search_config OR t.def_search_config <> s.def_search_config)`,
+		studysetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update terms' search configs: %w", err)
+	}
+	return nil
+}
+
+// maxDetectTerms is how many terms are looked at to suggest languages,
+// more than this doesn't make the guess any better
+const maxDetectTerms = 100
+
+// detectStudysetLanguages suggests languages for terms & defs (see language.Detect),
+// languages that couldn't be guessed are nil
+func detectStudysetLanguages(terms []string, defs []string) *model.StudysetLanguages {
+	detect := func(texts []string) *string {
+		if len(texts) > maxDetectTerms {
+			texts = texts[:maxDetectTerms]
+		}
+		tag := language.Detect(strings.Join(texts, "\n"))
+		if tag == "" {
+			return nil
+		}
+		return &tag
+	}
+	return &model.StudysetLanguages{
+		TermLanguage: detect(terms),
+		DefLanguage:  detect(defs),
+	}
+}
+
+// languageFilter validates a language to filter studysets by,
+// returning its base language so "es" also matches "es-MX"
+func languageFilter(tag *string) (*string, error) {
+	if tag == nil || strings.TrimSpace(*tag) == "" {
+		return nil, nil
+	}
+	normalized, err := language.Normalize(*tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid language")
+	}
+	base := language.Base(normalized)
+	return &base, nil
+}
+
+// answerLanguages is a studyset's saved term & def languages, for comparing answers,
+// "" when they're not set
+func answerLanguages(ctx context.Context, tx pgx.Tx, studysetID string) (string, string, error) {
+	var termLanguage, defLanguage *string
+	err := tx.QueryRow(
+		ctx,
+		"SELECT term_language, def_language FROM public.studysets WHERE id = $1",
+		studysetID,
+	).Scan(&termLanguage, &defLanguage)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch studyset languages: %w", err)
+	}
+	return stringOrEmpty(termLanguage), stringOrEmpty(defLanguage), nil
+}
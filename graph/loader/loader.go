@@ -3,6 +3,7 @@ package loader
 // import vikstrous/dataloadgen with your other imports
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"time"
 	"quizfreely/api/auth"
@@ -32,7 +33,9 @@ func (dr *dataReader) getUsers(ctx context.Context, userIDs []string) ([]*model.
 		ctx,
 		dr.db,
 		&users,
-		`SELECT u.id, u.username, u.display_name
+		`SELECT u.id, u.username, u.display_name, u.bio, u.avatar_image_id,
+	to_char(u.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as joined_at,
+	u.profile_private, u.profile_show_studysets, u.profile_show_joined_at
 FROM unnest($1::uuid[]) WITH ORDINALITY AS input(id, og_order)
 LEFT JOIN auth.users u ON u.id = input.id
 ORDER BY input.og_order`,
@@ -53,11 +56,19 @@ func (dr *dataReader) getTermsByIDs(ctx context.Context, ids []string) ([]*model
 		dr.db,
 		&terms,
 		`SELECT t.id, t.studyset_id, t.term, t.def, t.sort_order,
+	t.term_image_id, t.def_image_id,
+	t.term_html, t.def_html, t.term_plain, t.def_plain,
+	t.term_alternates, t.def_alternates, t.hint, t.version,
 	to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
 	to_char(t.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
 FROM unnest($1::uuid[]) WITH ORDINALITY AS input(id, og_order)
 LEFT JOIN terms t
 	ON t.id = input.id
+	AND t.deleted_at IS NULL
+	AND EXISTS (
+		SELECT 1 FROM public.studysets s
+		WHERE s.id = t.studyset_id AND s.deleted_at IS NULL
+	)
 ORDER BY input.og_order`,
 		ids,
 	)
@@ -68,6 +79,28 @@ ORDER BY input.og_order`,
 	return terms, nil
 }
 
+func (dr *dataReader) getImagesByIDs(ctx context.Context, ids []string) ([]*model.Image, []error) {
+	var images []*model.Image
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&images,
+		`SELECT i.id, i.storage_key, i.thumbnail_key, i.content_type,
+	i.width, i.height, i.size_bytes,
+	to_char(i.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(id, og_order)
+LEFT JOIN images i ON i.id = input.id
+ORDER BY input.og_order`,
+		ids,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return images, nil
+}
+
 func (dr *dataReader) getTermsByStudysetIDs(ctx context.Context, studysetIDs []string) ([][]*model.Term, []error) {
 	var terms []*model.Term
 
@@ -76,10 +109,13 @@ func (dr *dataReader) getTermsByStudysetIDs(ctx context.Context, studysetIDs []s
 		dr.db,
 		&terms,
 		`SELECT t.id, t.studyset_id, t.term, t.def, t.sort_order,
+	t.term_image_id, t.def_image_id,
+	t.term_html, t.def_html, t.term_plain, t.def_plain,
+	t.term_alternates, t.def_alternates, t.hint, t.version,
 	to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
 	to_char(t.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
 FROM terms t
-WHERE t.studyset_id = ANY($1::uuid[])
+WHERE t.studyset_id = ANY($1::uuid[]) AND t.deleted_at IS NULL
 ORDER BY t.studyset_id, t.sort_order`,
 		studysetIDs,
 	)
@@ -118,7 +154,7 @@ func (dr *dataReader) getTermsCountByStudysetIDs(ctx context.Context, studysetID
         &results,
         `SELECT studyset_id, COUNT(*) AS term_count
          FROM terms
-         WHERE studyset_id = ANY($1::uuid[])
+         WHERE studyset_id = ANY($1::uuid[]) AND deleted_at IS NULL
          GROUP BY studyset_id`,
         studysetIDs,
     )
@@ -146,6 +182,221 @@ func (dr *dataReader) getTermsCountByStudysetIDs(ctx context.Context, studysetID
     return orderedCounts, nil
 }
 
+func (dr *dataReader) getStarCountsByStudysetIDs(ctx context.Context, studysetIDs []string) ([]*int32, []error) {
+	var counts []*int32
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&counts,
+		`SELECT (
+	SELECT COUNT(*) FROM studyset_stars ss WHERE ss.studyset_id = input.studyset_id
+)::int AS star_count
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(studyset_id, og_order)
+ORDER BY input.og_order`,
+		studysetIDs,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return counts, nil
+}
+
+func (dr *dataReader) getViewCountsByStudysetIDs(ctx context.Context, studysetIDs []string) ([]*int32, []error) {
+	var counts []*int32
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&counts,
+		`SELECT (
+	SELECT COALESCE(sum(ds.views), 0) FROM studyset_daily_stats ds
+	WHERE ds.studyset_id = input.studyset_id
+)::int AS view_count
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(studyset_id, og_order)
+ORDER BY input.og_order`,
+		studysetIDs,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return counts, nil
+}
+
+func (dr *dataReader) getLearnerCountsByStudysetIDs(ctx context.Context, studysetIDs []string) ([]*int32, []error) {
+	var counts []*int32
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&counts,
+		`SELECT (
+	SELECT COUNT(*) FROM studyset_learners sl
+	WHERE sl.studyset_id = input.studyset_id
+)::int AS learner_count
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(studyset_id, og_order)
+ORDER BY input.og_order`,
+		studysetIDs,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return counts, nil
+}
+
+func (dr *dataReader) getFollowerCounts(ctx context.Context, userIDs []string) ([]*int32, []error) {
+	var counts []*int32
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&counts,
+		`SELECT (
+	SELECT COUNT(*) FROM user_follows uf
+	WHERE uf.followee_id = input.user_id
+)::int AS follower_count
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(user_id, og_order)
+ORDER BY input.og_order`,
+		userIDs,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return counts, nil
+}
+
+func (dr *dataReader) getFollowingCounts(ctx context.Context, userIDs []string) ([]*int32, []error) {
+	var counts []*int32
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&counts,
+		`SELECT (
+	SELECT COUNT(*) FROM user_follows uf
+	WHERE uf.follower_id = input.user_id
+)::int AS following_count
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(user_id, og_order)
+ORDER BY input.og_order`,
+		userIDs,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return counts, nil
+}
+
+// CommentsPage is a page of the comments on a studyset, on a term, or replying to a comment
+type CommentsPage struct {
+	ID     string
+	Limit  int
+	Offset int
+}
+
+/* comments are only shown while their studyset is public,
+deleted comments are kept (without their body or author) while they have replies */
+func (dr *dataReader) getComments(ctx context.Context, where string, pages []CommentsPage) ([][]*model.Comment, []error) {
+	ids := make([]string, len(pages))
+	limits := make([]int32, len(pages))
+	offsets := make([]int32, len(pages))
+	for i, p := range pages {
+		ids[i] = p.ID
+		limits[i] = int32(p.Limit)
+		offsets[i] = int32(p.Offset)
+	}
+
+	type commentRow struct {
+		model.Comment
+		OgOrder int64 `db:"og_order"`
+	}
+	var rows []*commentRow
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&rows,
+		`SELECT c.id, c.studyset_id, c.term_id, c.parent_id,
+	CASE WHEN c.deleted_at IS NULL THEN c.user_id END AS user_id,
+	CASE WHEN c.deleted_at IS NULL THEN c.body END AS body,
+	c.deleted_at IS NOT NULL AS deleted,
+	to_char(c.edited_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as edited_at,
+	to_char(c.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(c.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at,
+	c.og_order
+FROM (
+	SELECT c.*, input.og_order, input.page_limit, input.page_offset,
+		ROW_NUMBER() OVER (
+			PARTITION BY input.og_order
+			ORDER BY c.created_at, c.id
+		) AS rn
+	FROM unnest($1::uuid[], $2::int[], $3::int[]) WITH ORDINALITY AS input(id, page_limit, page_offset, og_order)
+	JOIN studyset_comments c ON `+where+`
+	JOIN public.studysets s ON s.id = c.studyset_id
+		AND s.private = false
+		AND s.deleted_at IS NULL
+) c
+WHERE c.rn > c.page_offset AND c.rn <= c.page_offset + c.page_limit
+ORDER BY c.og_order, c.rn`,
+		ids,
+		limits,
+		offsets,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	ordered := make([][]*model.Comment, len(pages))
+	for _, row := range rows {
+		i := row.OgOrder - 1
+		comment := row.Comment
+		ordered[i] = append(ordered[i], &comment)
+	}
+
+	return ordered, nil
+}
+
+func (dr *dataReader) getStudysetsComments(ctx context.Context, pages []CommentsPage) ([][]*model.Comment, []error) {
+	return dr.getComments(ctx, "c.studyset_id = input.id AND c.term_id IS NULL AND c.parent_id IS NULL", pages)
+}
+
+func (dr *dataReader) getTermsComments(ctx context.Context, pages []CommentsPage) ([][]*model.Comment, []error) {
+	return dr.getComments(ctx, "c.term_id = input.id AND c.parent_id IS NULL", pages)
+}
+
+func (dr *dataReader) getCommentsReplies(ctx context.Context, pages []CommentsPage) ([][]*model.Comment, []error) {
+	return dr.getComments(ctx, "c.parent_id = input.id", pages)
+}
+
+func (dr *dataReader) getStarredByMe(ctx context.Context, studysetIDs []string) ([]*bool, []error) {
+	authedUser := auth.AuthedUserContext(ctx)
+
+	var starred []*bool
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&starred,
+		`SELECT EXISTS (
+	SELECT 1 FROM studyset_stars ss
+	WHERE ss.studyset_id = input.studyset_id AND ss.user_id = $2
+) AS starred
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(studyset_id, og_order)
+ORDER BY input.og_order`,
+		studysetIDs,
+		authedUser.ID,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return starred, nil
+}
+
 func (dr *dataReader) getTermsProgress(ctx context.Context, termIDs []string) ([]*model.TermProgress, []error) {
 	authedUser := auth.AuthedUserContext(ctx)
 
@@ -180,6 +431,84 @@ ORDER BY input.og_order`,
 	return termsProgress, nil
 }
 
+func (dr *dataReader) getTermsNotes(ctx context.Context, termIDs []string) ([]*model.TermNote, []error) {
+	authedUser := auth.AuthedUserContext(ctx)
+
+	var termsNotes []*model.TermNote
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&termsNotes,
+		`SELECT tn.id, tn.term_id, tn.note,
+	to_char(tn.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(tn.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(term_id, og_order)
+LEFT JOIN term_notes tn
+	ON tn.term_id = input.term_id
+	AND tn.user_id = $2
+ORDER BY input.og_order`,
+		termIDs,
+		authedUser.ID,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	/* terms without a note should resolve to null, not an empty note */
+	for i, tn := range termsNotes {
+		if tn != nil && tn.ID == nil {
+			termsNotes[i] = nil
+		}
+	}
+
+	return termsNotes, nil
+}
+
+func (dr *dataReader) getStudysetsSettings(ctx context.Context, studysetIDs []string) ([]*model.StudysetSettings, []error) {
+	authedUser := auth.AuthedUserContext(ctx)
+
+	var rows []struct {
+		Settings  []byte
+		UpdatedAt *string
+	}
+
+	err := pgxscan.Select(
+		ctx,
+		dr.db,
+		&rows,
+		`SELECT ss.settings,
+	to_char(ss.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(studyset_id, og_order)
+LEFT JOIN studyset_settings ss
+	ON ss.studyset_id = input.studyset_id
+	AND ss.user_id = $2
+ORDER BY input.og_order`,
+		studysetIDs,
+		authedUser.ID,
+	)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	/* studysets without saved (or readable) settings resolve to nil,
+	defaults are applied by the resolver */
+	settings := make([]*model.StudysetSettings, len(studysetIDs))
+	for i, row := range rows {
+		if row.Settings == nil {
+			continue
+		}
+		var s model.StudysetSettings
+		if err := json.Unmarshal(row.Settings, &s); err != nil {
+			continue
+		}
+		s.UpdatedAt = row.UpdatedAt
+		settings[i] = &s
+	}
+
+	return settings, nil
+}
+
 func (dr *dataReader) getTermsTopConfusionPairs(ctx context.Context, termIDs []string) ([][]*model.TermConfusionPair, []error) {
 	authedUser := auth.AuthedUserContext(ctx)
 
@@ -326,9 +655,21 @@ ORDER BY input.og_order ASC, pt.timestamp DESC`,
 type Loaders struct {
 	UserLoader *dataloadgen.Loader[string, *model.User]
 	TermByIDLoader *dataloadgen.Loader[string, *model.Term]
+	ImageByIDLoader *dataloadgen.Loader[string, *model.Image]
 	TermByStudysetIDLoader *dataloadgen.Loader[string, []*model.Term]
 	TermsCountByStudysetIDLoader *dataloadgen.Loader[string, *int32]
 	TermProgressLoader *dataloadgen.Loader[string, *model.TermProgress]
+	TermNoteLoader *dataloadgen.Loader[string, *model.TermNote]
+	StudysetSettingsLoader *dataloadgen.Loader[string, *model.StudysetSettings]
+	StarCountByStudysetIDLoader *dataloadgen.Loader[string, *int32]
+	StarredByMeLoader *dataloadgen.Loader[string, *bool]
+	ViewCountByStudysetIDLoader *dataloadgen.Loader[string, *int32]
+	LearnerCountByStudysetIDLoader *dataloadgen.Loader[string, *int32]
+	FollowerCountLoader *dataloadgen.Loader[string, *int32]
+	FollowingCountLoader *dataloadgen.Loader[string, *int32]
+	StudysetCommentsLoader *dataloadgen.Loader[CommentsPage, []*model.Comment]
+	TermCommentsLoader *dataloadgen.Loader[CommentsPage, []*model.Comment]
+	CommentRepliesLoader *dataloadgen.Loader[CommentsPage, []*model.Comment]
 	TermTopConfusionPairsLoader *dataloadgen.Loader[string, []*model.TermConfusionPair]
 	TermTopReverseConfusionPairsLoader *dataloadgen.Loader[string, []*model.TermConfusionPair]
 	PracticeTestByStudysetIDLoader *dataloadgen.Loader[string, []*model.PracticeTest]
@@ -341,9 +682,21 @@ func NewLoaders(db *pgxpool.Pool) *Loaders {
 	return &Loaders{
 		UserLoader: dataloadgen.NewLoader(dr.getUsers, dataloadgen.WithWait(time.Millisecond)),
 		TermByIDLoader: dataloadgen.NewLoader(dr.getTermsByIDs, dataloadgen.WithWait(time.Millisecond)),
+		ImageByIDLoader: dataloadgen.NewLoader(dr.getImagesByIDs, dataloadgen.WithWait(time.Millisecond)),
 		TermByStudysetIDLoader: dataloadgen.NewLoader(dr.getTermsByStudysetIDs, dataloadgen.WithWait(time.Millisecond)),
 		TermsCountByStudysetIDLoader: dataloadgen.NewLoader(dr.getTermsCountByStudysetIDs, dataloadgen.WithWait(time.Millisecond)),
 		TermProgressLoader: dataloadgen.NewLoader(dr.getTermsProgress, dataloadgen.WithWait(time.Millisecond)),
+		TermNoteLoader: dataloadgen.NewLoader(dr.getTermsNotes, dataloadgen.WithWait(time.Millisecond)),
+		StudysetSettingsLoader: dataloadgen.NewLoader(dr.getStudysetsSettings, dataloadgen.WithWait(time.Millisecond)),
+		StarCountByStudysetIDLoader: dataloadgen.NewLoader(dr.getStarCountsByStudysetIDs, dataloadgen.WithWait(time.Millisecond)),
+		StarredByMeLoader: dataloadgen.NewLoader(dr.getStarredByMe, dataloadgen.WithWait(time.Millisecond)),
+		ViewCountByStudysetIDLoader: dataloadgen.NewLoader(dr.getViewCountsByStudysetIDs, dataloadgen.WithWait(time.Millisecond)),
+		LearnerCountByStudysetIDLoader: dataloadgen.NewLoader(dr.getLearnerCountsByStudysetIDs, dataloadgen.WithWait(time.Millisecond)),
+		FollowerCountLoader: dataloadgen.NewLoader(dr.getFollowerCounts, dataloadgen.WithWait(time.Millisecond)),
+		FollowingCountLoader: dataloadgen.NewLoader(dr.getFollowingCounts, dataloadgen.WithWait(time.Millisecond)),
+		StudysetCommentsLoader: dataloadgen.NewLoader(dr.getStudysetsComments, dataloadgen.WithWait(time.Millisecond)),
+		TermCommentsLoader: dataloadgen.NewLoader(dr.getTermsComments, dataloadgen.WithWait(time.Millisecond)),
+		CommentRepliesLoader: dataloadgen.NewLoader(dr.getCommentsReplies, dataloadgen.WithWait(time.Millisecond)),
 		TermTopConfusionPairsLoader: dataloadgen.NewLoader(dr.getTermsTopConfusionPairs, dataloadgen.WithWait(time.Millisecond)),
 		TermTopReverseConfusionPairsLoader: dataloadgen.NewLoader(dr.getTermsTopReverseConfusionPairs, dataloadgen.WithWait(time.Millisecond)),
 		PracticeTestByStudysetIDLoader: dataloadgen.NewLoader(dr.getPracticeTestsByStudysetIDs, dataloadgen.WithWait(time.Millisecond)),
@@ -387,6 +740,18 @@ func GetTermsByIDs(ctx context.Context, ids []string) ([]*model.Term, error) {
 	return loaders.TermByIDLoader.LoadAll(ctx, ids)
 }
 
+// GetImage returns a single image by id efficiently
+func GetImage(ctx context.Context, id string) (*model.Image, error) {
+	loaders := For(ctx)
+	return loaders.ImageByIDLoader.Load(ctx, id)
+}
+
+// GetImages returns many images by ids efficiently
+func GetImages(ctx context.Context, ids []string) ([]*model.Image, error) {
+	loaders := For(ctx)
+	return loaders.ImageByIDLoader.LoadAll(ctx, ids)
+}
+
 // GetTermsByStudysetID returns a single studyset's terms efficiently
 func GetTermsByStudysetID(ctx context.Context, studysetID string) ([]*model.Term, error) {
 	loaders := For(ctx)
@@ -411,6 +776,73 @@ func GetTermsCountByStudysetIDs(ctx context.Context, studysetIDs []string) ([]*i
 	return loaders.TermsCountByStudysetIDLoader.LoadAll(ctx, studysetIDs)
 }
 
+// GetStarCountByStudysetID returns a single studyset's star count efficiently
+func GetStarCountByStudysetID(ctx context.Context, studysetID string) (*int32, error) {
+	loaders := For(ctx)
+	return loaders.StarCountByStudysetIDLoader.Load(ctx, studysetID)
+}
+
+// GetStarCountsByStudysetIDs returns many studysets' star counts efficiently
+func GetStarCountsByStudysetIDs(ctx context.Context, studysetIDs []string) ([]*int32, error) {
+	loaders := For(ctx)
+	return loaders.StarCountByStudysetIDLoader.LoadAll(ctx, studysetIDs)
+}
+
+// GetStarredByMe returns whether the authed user starred a single studyset efficiently
+func GetStarredByMe(ctx context.Context, studysetID string) (*bool, error) {
+	loaders := For(ctx)
+	return loaders.StarredByMeLoader.Load(ctx, studysetID)
+}
+
+// GetManyStarredByMe returns whether the authed user starred many studysets efficiently
+func GetManyStarredByMe(ctx context.Context, studysetIDs []string) ([]*bool, error) {
+	loaders := For(ctx)
+	return loaders.StarredByMeLoader.LoadAll(ctx, studysetIDs)
+}
+
+// GetViewCountByStudysetID returns a single studyset's view count efficiently,
+// views are counted when stats are rolled up, so it can be a little behind
+func GetViewCountByStudysetID(ctx context.Context, studysetID string) (*int32, error) {
+	loaders := For(ctx)
+	return loaders.ViewCountByStudysetIDLoader.Load(ctx, studysetID)
+}
+
+// GetLearnerCountByStudysetID returns how many people have studied a single studyset efficiently
+func GetLearnerCountByStudysetID(ctx context.Context, studysetID string) (*int32, error) {
+	loaders := For(ctx)
+	return loaders.LearnerCountByStudysetIDLoader.Load(ctx, studysetID)
+}
+
+// GetFollowerCount returns how many people follow a single user efficiently
+func GetFollowerCount(ctx context.Context, userID string) (*int32, error) {
+	loaders := For(ctx)
+	return loaders.FollowerCountLoader.Load(ctx, userID)
+}
+
+// GetFollowingCount returns how many people a single user follows efficiently
+func GetFollowingCount(ctx context.Context, userID string) (*int32, error) {
+	loaders := For(ctx)
+	return loaders.FollowingCountLoader.Load(ctx, userID)
+}
+
+// GetStudysetComments returns a page of a studyset's comments (not replies or comments on its terms) efficiently
+func GetStudysetComments(ctx context.Context, page CommentsPage) ([]*model.Comment, error) {
+	loaders := For(ctx)
+	return loaders.StudysetCommentsLoader.Load(ctx, page)
+}
+
+// GetTermComments returns a page of a term's comments (not replies) efficiently
+func GetTermComments(ctx context.Context, page CommentsPage) ([]*model.Comment, error) {
+	loaders := For(ctx)
+	return loaders.TermCommentsLoader.Load(ctx, page)
+}
+
+// GetCommentReplies returns a page of a comment's replies efficiently
+func GetCommentReplies(ctx context.Context, page CommentsPage) ([]*model.Comment, error) {
+	loaders := For(ctx)
+	return loaders.CommentRepliesLoader.Load(ctx, page)
+}
+
 // GetTermProgress returns a single term's progress record by term id efficiently
 func GetTermProgress(ctx context.Context, termID string) (*model.TermProgress, error) {
 	loaders := For(ctx)
@@ -423,6 +855,30 @@ func GetTermsProgress(ctx context.Context, termIDs []string) ([]*model.TermProgr
 	return loaders.TermProgressLoader.LoadAll(ctx, termIDs)
 }
 
+// GetTermNote returns the authed user's note on a single term efficiently
+func GetTermNote(ctx context.Context, termID string) (*model.TermNote, error) {
+	loaders := For(ctx)
+	return loaders.TermNoteLoader.Load(ctx, termID)
+}
+
+// GetTermsNotes returns the authed user's notes on many terms efficiently
+func GetTermsNotes(ctx context.Context, termIDs []string) ([]*model.TermNote, error) {
+	loaders := For(ctx)
+	return loaders.TermNoteLoader.LoadAll(ctx, termIDs)
+}
+
+// GetStudysetSettings returns the authed user's saved settings for a single studyset efficiently
+func GetStudysetSettings(ctx context.Context, studysetID string) (*model.StudysetSettings, error) {
+	loaders := For(ctx)
+	return loaders.StudysetSettingsLoader.Load(ctx, studysetID)
+}
+
+// GetStudysetsSettings returns the authed user's saved settings for many studysets efficiently
+func GetStudysetsSettings(ctx context.Context, studysetIDs []string) ([]*model.StudysetSettings, error) {
+	loaders := For(ctx)
+	return loaders.StudysetSettingsLoader.LoadAll(ctx, studysetIDs)
+}
+
 // GetTermTopReverseConfusionPairs returns a single term's confusion pairs
 func GetTermTopReverseConfusionPairs(ctx context.Context, termID string) ([]*model.TermConfusionPair, error) {
 	loaders := For(ctx)
@@ -0,0 +1,261 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/language"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const maxMergeSources = 20
+
+// copiedTerm is a term being copied into a new studyset, by merging or splitting studysets
+type copiedTerm struct {
+	ID             string
+	Term           *string
+	Def            *string
+	TermPlain      *string
+	DefPlain       *string
+	TermImageID    *string
+	DefImageID     *string
+	TermAlternates []string
+	DefAlternates  []string
+	Hint           *string
+	TermLanguage   *string
+	DefLanguage    *string
+}
+
+/* selected from terms t joined with their studysets s, for the languages they were in */
+const copiedTermColumns = `t.id, t.term, t.def, t.term_plain, t.def_plain, t.term_image_id, t.def_image_id,
+	t.term_alternates, t.def_alternates, t.hint, s.term_language, s.def_language`
+
+func (t *copiedTerm) newTermInput(sortOrder int) *model.NewTermInput {
+	return &model.NewTermInput{
+		Term:           t.Term,
+		Def:            t.Def,
+		SortOrder:      int32(sortOrder),
+		TermImageID:    t.TermImageID,
+		DefImageID:     t.DefImageID,
+		TermAlternates: t.TermAlternates,
+		DefAlternates:  t.DefAlternates,
+		Hint:           t.Hint,
+	}
+}
+
+// mergeTermKey is what makes two terms duplicates when merging with dedupe,
+// the same term & def (compared like answers in their studyset's languages) and the same images
+func mergeTermKey(t *copiedTerm) string {
+	return language.NormalizeAnswer(stringOrEmpty(t.TermPlain), stringOrEmpty(t.TermLanguage)) + "\x00" +
+		language.NormalizeAnswer(stringOrEmpty(t.DefPlain), stringOrEmpty(t.DefLanguage)) + "\x00" +
+		stringOrEmpty(t.TermImageID) + "\x00" +
+		stringOrEmpty(t.DefImageID)
+}
+
+// mergeStudysets creates a new studyset with the terms of studysets userID can see,
+// in the order of sourceIDs, then each studyset's own order.
+// With dedupe, duplicate terms are collapsed into the first one (with all of their alternates).
+// With carryOverProgress, userID's progress & confused terms from the source terms
+// are copied to the new terms, the original studysets keep theirs
+func (r *Resolver) mergeStudysets(ctx context.Context, userID *string, sourceIDs []string, title string, private bool, dedupe bool, carryOverProgress bool) (*model.Studyset, error) {
+	uniqueSourceIDs := make([]string, 0, len(sourceIDs))
+	seenSources := make(map[string]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if !seenSources[id] {
+			seenSources[id] = true
+			uniqueSourceIDs = append(uniqueSourceIDs, id)
+		}
+	}
+	if len(uniqueSourceIDs) < 2 {
+		return nil, fmt.Errorf("at least 2 studysets are needed to merge")
+	}
+	if len(uniqueSourceIDs) > maxMergeSources {
+		return nil, fmt.Errorf("at most %d studysets can be merged at once", maxMergeSources)
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sources []*struct {
+		model.Studyset
+		ModerationHold bool
+	}
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&sources,
+		`SELECT id, term_language, def_language, moderation_hold FROM public.studysets
+WHERE id = ANY($1) AND (private = false OR user_id = $2) AND deleted_at IS NULL`,
+		uniqueSourceIDs,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch studysets: %w", err)
+	}
+	if len(sources) != len(uniqueSourceIDs) {
+		return nil, fmt.Errorf("studyset not found")
+	}
+	/* a copy of a hidden studyset would get around the moderator's decision */
+	for _, s := range sources {
+		if s.ModerationHold {
+			return nil, fmt.Errorf("studysets hidden by a moderator can't be merged")
+		}
+	}
+
+	/* languages carry over if every studyset agrees on them */
+	input := model.StudysetInput{
+		Title:        title,
+		Private:      private,
+		TermLanguage: sources[0].TermLanguage,
+		DefLanguage:  sources[0].DefLanguage,
+	}
+	for _, s := range sources[1:] {
+		if !stringsEqual(input.TermLanguage, s.TermLanguage) {
+			input.TermLanguage = nil
+		}
+		if !stringsEqual(input.DefLanguage, s.DefLanguage) {
+			input.DefLanguage = nil
+		}
+	}
+
+	var sourceTerms []*copiedTerm
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&sourceTerms,
+		`SELECT `+copiedTermColumns+`
+FROM terms t
+JOIN public.studysets s ON s.id = t.studyset_id
+WHERE t.studyset_id = ANY($1) AND t.deleted_at IS NULL
+ORDER BY array_position($1::uuid[], t.studyset_id), t.sort_order, t.created_at, t.id`,
+		uniqueSourceIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+
+	/* newTerms[i] is made from the source terms in sourceTermIDs[i] */
+	newTerms := make([]*model.NewTermInput, 0, len(sourceTerms))
+	sourceTermIDs := make([][]string, 0, len(sourceTerms))
+	termIndexes := make(map[string]int)
+	for _, t := range sourceTerms {
+		if dedupe {
+			key := mergeTermKey(t)
+			if i, ok := termIndexes[key]; ok {
+				newTerms[i].TermAlternates = append(newTerms[i].TermAlternates, t.TermAlternates...)
+				newTerms[i].DefAlternates = append(newTerms[i].DefAlternates, t.DefAlternates...)
+				if newTerms[i].Hint == nil {
+					newTerms[i].Hint = t.Hint
+				}
+				sourceTermIDs[i] = append(sourceTermIDs[i], t.ID)
+				continue
+			}
+			termIndexes[key] = len(newTerms)
+		}
+		newTerms = append(newTerms, t.newTermInput(len(newTerms)))
+		sourceTermIDs = append(sourceTermIDs, []string{t.ID})
+	}
+
+	/* images in studysets userID can see can be shown in their copy too */
+	merged, err := r.createStudysetWithTermsTx(ctx, tx, userID, input, newTerms)
+	if err != nil {
+		return nil, err
+	}
+
+	if carryOverProgress && len(newTerms) > 0 {
+		if err := carryOverTermProgress(ctx, tx, userID, *merged.ID, sourceTermIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return merged, nil
+}
+
+// carryOverTermProgress copies userID's term_progress & term_confusion_pairs
+// from source terms to the terms made from them, sourceTermIDs[i] being the terms
+// that the new studyset's term with sort_order i was made from.
+// When several source terms were merged into one, the most recently reviewed progress is kept,
+// and their confused counts are added together
+func carryOverTermProgress(ctx context.Context, tx pgx.Tx, userID *string, studysetID string, sourceTermIDs [][]string) error {
+	rows, err := tx.Query(
+		ctx,
+		"SELECT id::text FROM terms WHERE studyset_id = $1 AND deleted_at IS NULL ORDER BY sort_order",
+		studysetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch terms: %w", err)
+	}
+	newTermIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to fetch terms: %w", err)
+	}
+	if len(newTermIDs) != len(sourceTermIDs) {
+		return fmt.Errorf("failed to carry over progress: new studyset has %d terms, expected %d", len(newTermIDs), len(sourceTermIDs))
+	}
+
+	oldIDs := make([]string, 0, len(sourceTermIDs))
+	newIDs := make([]string, 0, len(sourceTermIDs))
+	for i, ids := range sourceTermIDs {
+		for _, id := range ids {
+			oldIDs = append(oldIDs, id)
+			newIDs = append(newIDs, newTermIDs[i])
+		}
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO term_progress (
+	term_id, user_id,
+	term_first_reviewed_at, term_last_reviewed_at, term_review_count,
+	def_first_reviewed_at, def_last_reviewed_at, def_review_count,
+	term_leitner_system_box, def_leitner_system_box,
+	term_correct_count, term_incorrect_count, def_correct_count, def_incorrect_count
+)
+SELECT DISTINCT ON (m.new_id)
+	m.new_id, p.user_id,
+	p.term_first_reviewed_at, p.term_last_reviewed_at, p.term_review_count,
+	p.def_first_reviewed_at, p.def_last_reviewed_at, p.def_review_count,
+	p.term_leitner_system_box, p.def_leitner_system_box,
+	p.term_correct_count, p.term_incorrect_count, p.def_correct_count, p.def_incorrect_count
+FROM unnest($2::uuid[], $3::uuid[]) AS m(old_id, new_id)
+JOIN term_progress p ON p.term_id = m.old_id AND p.user_id = $1
+ORDER BY m.new_id, greatest(p.term_last_reviewed_at, p.def_last_reviewed_at) DESC NULLS LAST`,
+		userID,
+		oldIDs,
+		newIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to carry over progress: %w", err)
+	}
+
+	/* pairs between two terms that were merged into one don't make sense anymore */
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO term_confusion_pairs (
+	user_id, term_id, confused_term_id, answered_with, confused_count, last_confused_at
+)
+SELECT c.user_id, mt.new_id, mc.new_id, c.answered_with, sum(c.confused_count), max(c.last_confused_at)
+FROM term_confusion_pairs c
+JOIN unnest($2::uuid[], $3::uuid[]) AS mt(old_id, new_id) ON mt.old_id = c.term_id
+JOIN unnest($2::uuid[], $3::uuid[]) AS mc(old_id, new_id) ON mc.old_id = c.confused_term_id
+WHERE c.user_id = $1 AND mt.new_id <> mc.new_id
+GROUP BY c.user_id, mt.new_id, mc.new_id, c.answered_with`,
+		userID,
+		oldIDs,
+		newIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to carry over confused terms: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package model
+
+type Comment struct {
+	ID         *string    `json:"id,omitempty"`
+	StudysetID *string    `json:"studysetId,omitempty"`
+	TermID     *string    `json:"termId,omitempty"`
+	ParentID   *string    `json:"parentId,omitempty"`
+	UserID     *string    `json:"-"`
+	User       *User      `json:"user,omitempty"`
+	Body       *string    `json:"body,omitempty"`
+	Deleted    *bool      `json:"deleted,omitempty"`
+	Replies    []*Comment `json:"replies,omitempty"`
+	EditedAt   *string    `json:"editedAt,omitempty"`
+	CreatedAt  *string    `json:"createdAt,omitempty"`
+	UpdatedAt  *string    `json:"updatedAt,omitempty"`
+}
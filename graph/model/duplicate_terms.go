@@ -0,0 +1,17 @@
+package model
+
+// DuplicateTermGroup is terms in a studyset that are all (near) duplicates of each other,
+// with the pairs of them that were similar enough to be grouped
+type DuplicateTermGroup struct {
+	TermIDs []string             `json:"-"`
+	Pairs   []*DuplicateTermPair `json:"pairs,omitempty"`
+}
+
+type DuplicateTermPair struct {
+	TermID          *string  `json:"-"`
+	DuplicateTermID *string  `json:"-"`
+	TermSimilarity  *float64 `json:"termSimilarity,omitempty"`
+	DefSimilarity   *float64 `json:"defSimilarity,omitempty"`
+	Score           *float64 `json:"score,omitempty"`
+	Exact           *bool    `json:"exact,omitempty"`
+}
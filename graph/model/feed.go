@@ -0,0 +1,16 @@
+package model
+
+type StudysetFeed struct {
+	Items       []*StudysetFeedItem `json:"items,omitempty"`
+	EndCursor   *string             `json:"endCursor,omitempty"`
+	HasNextPage *bool               `json:"hasNextPage,omitempty"`
+}
+
+type StudysetFeedItem struct {
+	ID         int64          `json:"-"`
+	Cursor     *string        `json:"cursor,omitempty"`
+	Kind       *FeedEventKind `json:"kind,omitempty"`
+	StudysetID *string        `json:"-"`
+	Studyset   *Studyset      `json:"studyset,omitempty"`
+	CreatedAt  *string        `json:"createdAt,omitempty"`
+}
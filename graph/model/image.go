@@ -0,0 +1,15 @@
+package model
+
+type Image struct {
+	ID           *string `json:"id,omitempty"`
+	UserID       *string `json:"-"`
+	StorageKey   *string `json:"-"`
+	ThumbnailKey *string `json:"-"`
+	URL          *string `json:"url,omitempty"`
+	ThumbnailURL *string `json:"thumbnailUrl,omitempty"`
+	ContentType  *string `json:"contentType,omitempty"`
+	Width        *int32  `json:"width,omitempty"`
+	Height       *int32  `json:"height,omitempty"`
+	SizeBytes    *int32  `json:"sizeBytes,omitempty"`
+	CreatedAt    *string `json:"createdAt,omitempty"`
+}
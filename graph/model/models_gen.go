@@ -7,8 +7,39 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
 )
 
+type Notification interface {
+	IsNotification()
+}
+
+type AnkiImportOptions struct {
+	Private               *bool `json:"private,omitempty"`
+	PreserveDeckHierarchy *bool `json:"preserveDeckHierarchy,omitempty"`
+	SkipMedia             *bool `json:"skipMedia,omitempty"`
+}
+
+type AnkiImportResult struct {
+	Studysets    []*Studyset         `json:"studysets,omitempty"`
+	Decks        []*AnkiImportedDeck `json:"decks,omitempty"`
+	SkippedNotes []*AnkiSkippedNote  `json:"skippedNotes,omitempty"`
+	SkippedMedia []string            `json:"skippedMedia,omitempty"`
+	MediaSkipped *bool               `json:"mediaSkipped,omitempty"`
+}
+
+type AnkiImportedDeck struct {
+	Name  *string         `json:"name,omitempty"`
+	Terms []*NewTermInput `json:"terms,omitempty"`
+}
+
+type AnkiSkippedNote struct {
+	NoteID *string `json:"noteId,omitempty"`
+	Deck   *string `json:"deck,omitempty"`
+	Reason *string `json:"reason,omitempty"`
+}
+
 type Frq struct {
 	Term              *Term       `json:"term,omitempty"`
 	AnswerWith        *AnswerWith `json:"answerWith,omitempty"`
@@ -25,6 +56,26 @@ type FRQInput struct {
 	AnsweredString    *string     `json:"answeredString,omitempty"`
 }
 
+type ImportStudysetInput struct {
+	Text             *string         `json:"text,omitempty"`
+	File             *graphql.Upload `json:"file,omitempty"`
+	TermDefSeparator *string         `json:"termDefSeparator,omitempty"`
+	CardSeparator    *string         `json:"cardSeparator,omitempty"`
+	Quote            *string         `json:"quote,omitempty"`
+	HeaderRow        *bool           `json:"headerRow,omitempty"`
+}
+
+type ImportStudysetResult struct {
+	Studyset *Studyset        `json:"studyset,omitempty"`
+	Terms    []*NewTermInput  `json:"terms,omitempty"`
+	Warnings []*ImportWarning `json:"warnings,omitempty"`
+}
+
+type ImportWarning struct {
+	Line    *int32  `json:"line,omitempty"`
+	Message *string `json:"message,omitempty"`
+}
+
 type Mcq struct {
 	Term         *Term       `json:"term,omitempty"`
 	AnswerWith   *AnswerWith `json:"answerWith,omitempty"`
@@ -60,10 +111,15 @@ type MatchQuestionInput struct {
 type Mutation struct {
 }
 
-type NewTermInput struct {
-	Term      *string `json:"term,omitempty"`
-	Def       *string `json:"def,omitempty"`
-	SortOrder int32   `json:"sortOrder"`
+type NotificationList struct {
+	Notifications []Notification `json:"notifications,omitempty"`
+	UnreadCount   *int32         `json:"unreadCount,omitempty"`
+}
+
+type NotificationPreference struct {
+	Kind    *NotificationKind `json:"kind,omitempty"`
+	Muted   *bool             `json:"muted,omitempty"`
+	CanMute *bool             `json:"canMute,omitempty"`
 }
 
 type PracticeTestInput struct {
@@ -74,6 +130,26 @@ type PracticeTestInput struct {
 	Questions        []*QuestionInput `json:"questions,omitempty"`
 }
 
+type ProfileInput struct {
+	DisplayName   *string              `json:"displayName,omitempty"`
+	Bio           *string              `json:"bio,omitempty"`
+	AvatarImageID *string              `json:"avatarImageId,omitempty"`
+	RemoveAvatar  *bool                `json:"removeAvatar,omitempty"`
+	Privacy       *ProfilePrivacyInput `json:"privacy,omitempty"`
+}
+
+type ProfilePrivacy struct {
+	Private       *bool `json:"private,omitempty"`
+	ShowStudysets *bool `json:"showStudysets,omitempty"`
+	ShowJoinedAt  *bool `json:"showJoinedAt,omitempty"`
+}
+
+type ProfilePrivacyInput struct {
+	Private       *bool `json:"private,omitempty"`
+	ShowStudysets *bool `json:"showStudysets,omitempty"`
+	ShowJoinedAt  *bool `json:"showJoinedAt,omitempty"`
+}
+
 type Query struct {
 }
 
@@ -93,9 +169,90 @@ type QuestionInput struct {
 	FrqInput               *FRQInput               `json:"frqInput,omitempty"`
 }
 
+type SplitRangeInput struct {
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+}
+
+type SplitStrategyInput struct {
+	Size   *int32             `json:"size,omitempty"`
+	Ranges []*SplitRangeInput `json:"ranges,omitempty"`
+	Groups [][]string         `json:"groups,omitempty"`
+}
+
 type StudysetInput struct {
-	Title   string `json:"title"`
-	Private bool   `json:"private"`
+	Title        string  `json:"title"`
+	Private      bool    `json:"private"`
+	TermLanguage *string `json:"termLanguage,omitempty"`
+	DefLanguage  *string `json:"defLanguage,omitempty"`
+}
+
+type StudysetLanguages struct {
+	TermLanguage *string `json:"termLanguage,omitempty"`
+	DefLanguage  *string `json:"defLanguage,omitempty"`
+}
+
+type StudysetRevisionDiff struct {
+	FromRevision   *StudysetRevision `json:"fromRevision,omitempty"`
+	ToRevision     *StudysetRevision `json:"toRevision,omitempty"`
+	TitleChanged   *bool             `json:"titleChanged,omitempty"`
+	PrivateChanged *bool             `json:"privateChanged,omitempty"`
+	TermChanges    []*TermChange     `json:"termChanges,omitempty"`
+}
+
+type StudysetRevisionTerm struct {
+	ID             *string  `json:"id,omitempty"`
+	Term           *string  `json:"term,omitempty"`
+	Def            *string  `json:"def,omitempty"`
+	SortOrder      *int32   `json:"sortOrder,omitempty"`
+	TermImageID    *string  `json:"termImageId,omitempty"`
+	DefImageID     *string  `json:"defImageId,omitempty"`
+	TermAlternates []string `json:"termAlternates,omitempty"`
+	DefAlternates  []string `json:"defAlternates,omitempty"`
+	Hint           *string  `json:"hint,omitempty"`
+}
+
+type StudysetSettingsInput struct {
+	AnswerWith      *AnswerSide    `json:"answerWith,omitempty"`
+	Shuffle         *bool          `json:"shuffle,omitempty"`
+	QuestionTypes   []QuestionType `json:"questionTypes,omitempty"`
+	LeitnerBoxCount *int32         `json:"leitnerBoxCount,omitempty"`
+	Audio           *bool          `json:"audio,omitempty"`
+}
+
+type SuggestedNewTermInput struct {
+	Term           *string  `json:"term,omitempty"`
+	Def            *string  `json:"def,omitempty"`
+	TermAlternates []string `json:"termAlternates,omitempty"`
+	DefAlternates  []string `json:"defAlternates,omitempty"`
+	Hint           *string  `json:"hint,omitempty"`
+}
+
+type SuggestedTermEditInput struct {
+	TermID         string   `json:"termId"`
+	Term           *string  `json:"term,omitempty"`
+	Def            *string  `json:"def,omitempty"`
+	TermAlternates []string `json:"termAlternates,omitempty"`
+	DefAlternates  []string `json:"defAlternates,omitempty"`
+	Hint           *string  `json:"hint,omitempty"`
+}
+
+type SuggestionInput struct {
+	Message   *string                   `json:"message,omitempty"`
+	Edits     []*SuggestedTermEditInput `json:"edits,omitempty"`
+	Additions []*SuggestedNewTermInput  `json:"additions,omitempty"`
+	Removals  []string                  `json:"removals,omitempty"`
+}
+
+type TermChange struct {
+	TermID       *string         `json:"termId,omitempty"`
+	ChangeType   *TermChangeType `json:"changeType,omitempty"`
+	OldTerm      *string         `json:"oldTerm,omitempty"`
+	NewTerm      *string         `json:"newTerm,omitempty"`
+	OldDef       *string         `json:"oldDef,omitempty"`
+	NewDef       *string         `json:"newDef,omitempty"`
+	OldSortOrder *int32          `json:"oldSortOrder,omitempty"`
+	NewSortOrder *int32          `json:"newSortOrder,omitempty"`
 }
 
 type TermConfusionPairInput struct {
@@ -107,10 +264,18 @@ type TermConfusionPairInput struct {
 }
 
 type TermInput struct {
-	ID        string  `json:"id"`
-	Term      *string `json:"term,omitempty"`
-	Def       *string `json:"def,omitempty"`
-	SortOrder *int32  `json:"sortOrder,omitempty"`
+	ID              string   `json:"id"`
+	Term            *string  `json:"term,omitempty"`
+	Def             *string  `json:"def,omitempty"`
+	SortOrder       *int32   `json:"sortOrder,omitempty"`
+	TermImageID     *string  `json:"termImageId,omitempty"`
+	DefImageID      *string  `json:"defImageId,omitempty"`
+	RemoveTermImage *bool    `json:"removeTermImage,omitempty"`
+	RemoveDefImage  *bool    `json:"removeDefImage,omitempty"`
+	TermAlternates  []string `json:"termAlternates,omitempty"`
+	DefAlternates   []string `json:"defAlternates,omitempty"`
+	Hint            *string  `json:"hint,omitempty"`
+	ExpectedVersion *int32   `json:"expectedVersion,omitempty"`
 }
 
 type TermProgress struct {
@@ -140,6 +305,11 @@ type TermProgressInput struct {
 	DefIncorrectIncrease  *int32  `json:"defIncorrectIncrease,omitempty"`
 }
 
+type Trash struct {
+	Studysets []*Studyset `json:"studysets,omitempty"`
+	Terms     []*Term     `json:"terms,omitempty"`
+}
+
 type TrueFalseQuestion struct {
 	Term         *Term       `json:"term,omitempty"`
 	AnswerWith   *AnswerWith `json:"answerWith,omitempty"`
@@ -156,10 +326,61 @@ type TrueFalseQuestionInput struct {
 	Distractor   *TermInput  `json:"distractor,omitempty"`
 }
 
-type User struct {
-	ID          *string `json:"id,omitempty"`
-	Username    *string `json:"username,omitempty"`
-	DisplayName *string `json:"displayName,omitempty"`
+type AnswerSide string
+
+const (
+	AnswerSideTerm AnswerSide = "TERM"
+	AnswerSideDef  AnswerSide = "DEF"
+	AnswerSideBoth AnswerSide = "BOTH"
+)
+
+var AllAnswerSide = []AnswerSide{
+	AnswerSideTerm,
+	AnswerSideDef,
+	AnswerSideBoth,
+}
+
+func (e AnswerSide) IsValid() bool {
+	switch e {
+	case AnswerSideTerm, AnswerSideDef, AnswerSideBoth:
+		return true
+	}
+	return false
+}
+
+func (e AnswerSide) String() string {
+	return string(e)
+}
+
+func (e *AnswerSide) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AnswerSide(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AnswerSide", str)
+	}
+	return nil
+}
+
+func (e AnswerSide) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AnswerSide) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AnswerSide) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
 }
 
 type AnswerWith string
@@ -272,6 +493,185 @@ func (e AuthType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type FeedEventKind string
+
+const (
+	FeedEventKindPublished FeedEventKind = "PUBLISHED"
+	FeedEventKindUpdated   FeedEventKind = "UPDATED"
+)
+
+var AllFeedEventKind = []FeedEventKind{
+	FeedEventKindPublished,
+	FeedEventKindUpdated,
+}
+
+func (e FeedEventKind) IsValid() bool {
+	switch e {
+	case FeedEventKindPublished, FeedEventKindUpdated:
+		return true
+	}
+	return false
+}
+
+func (e FeedEventKind) String() string {
+	return string(e)
+}
+
+func (e *FeedEventKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FeedEventKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FeedEventKind", str)
+	}
+	return nil
+}
+
+func (e FeedEventKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FeedEventKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FeedEventKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ModerationAction string
+
+const (
+	ModerationActionDismiss  ModerationAction = "DISMISS"
+	ModerationActionHide     ModerationAction = "HIDE"
+	ModerationActionRemove   ModerationAction = "REMOVE"
+	ModerationActionAutoHide ModerationAction = "AUTO_HIDE"
+)
+
+var AllModerationAction = []ModerationAction{
+	ModerationActionDismiss,
+	ModerationActionHide,
+	ModerationActionRemove,
+	ModerationActionAutoHide,
+}
+
+func (e ModerationAction) IsValid() bool {
+	switch e {
+	case ModerationActionDismiss, ModerationActionHide, ModerationActionRemove, ModerationActionAutoHide:
+		return true
+	}
+	return false
+}
+
+func (e ModerationAction) String() string {
+	return string(e)
+}
+
+func (e *ModerationAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ModerationAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ModerationAction", str)
+	}
+	return nil
+}
+
+func (e ModerationAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ModerationAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ModerationAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationKind string
+
+const (
+	NotificationKindModerationDecision NotificationKind = "MODERATION_DECISION"
+	NotificationKindStudysetComment    NotificationKind = "STUDYSET_COMMENT"
+	NotificationKindCommentReply       NotificationKind = "COMMENT_REPLY"
+	NotificationKindStudysetSuggestion NotificationKind = "STUDYSET_SUGGESTION"
+	NotificationKindSuggestionAccepted NotificationKind = "SUGGESTION_ACCEPTED"
+	NotificationKindSuggestionRejected NotificationKind = "SUGGESTION_REJECTED"
+	NotificationKindNewFollower        NotificationKind = "NEW_FOLLOWER"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindModerationDecision,
+	NotificationKindStudysetComment,
+	NotificationKindCommentReply,
+	NotificationKindStudysetSuggestion,
+	NotificationKindSuggestionAccepted,
+	NotificationKindSuggestionRejected,
+	NotificationKindNewFollower,
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindModerationDecision, NotificationKindStudysetComment, NotificationKindCommentReply, NotificationKindStudysetSuggestion, NotificationKindSuggestionAccepted, NotificationKindSuggestionRejected, NotificationKindNewFollower:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+func (e *NotificationKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationKind", str)
+	}
+	return nil
+}
+
+func (e NotificationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type QuestionType string
 
 const (
@@ -330,3 +730,351 @@ func (e QuestionType) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type ReportReason string
+
+const (
+	ReportReasonSpam          ReportReason = "SPAM"
+	ReportReasonInappropriate ReportReason = "INAPPROPRIATE"
+	ReportReasonHarassment    ReportReason = "HARASSMENT"
+	ReportReasonCopyright     ReportReason = "COPYRIGHT"
+	ReportReasonMisleading    ReportReason = "MISLEADING"
+	ReportReasonOther         ReportReason = "OTHER"
+)
+
+var AllReportReason = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonInappropriate,
+	ReportReasonHarassment,
+	ReportReasonCopyright,
+	ReportReasonMisleading,
+	ReportReasonOther,
+}
+
+func (e ReportReason) IsValid() bool {
+	switch e {
+	case ReportReasonSpam, ReportReasonInappropriate, ReportReasonHarassment, ReportReasonCopyright, ReportReasonMisleading, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+func (e ReportReason) String() string {
+	return string(e)
+}
+
+func (e *ReportReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportReason", str)
+	}
+	return nil
+}
+
+func (e ReportReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportReason) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportReason) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "OPEN"
+	ReportStatusDismissed ReportStatus = "DISMISSED"
+	ReportStatusActioned  ReportStatus = "ACTIONED"
+)
+
+var AllReportStatus = []ReportStatus{
+	ReportStatusOpen,
+	ReportStatusDismissed,
+	ReportStatusActioned,
+}
+
+func (e ReportStatus) IsValid() bool {
+	switch e {
+	case ReportStatusOpen, ReportStatusDismissed, ReportStatusActioned:
+		return true
+	}
+	return false
+}
+
+func (e ReportStatus) String() string {
+	return string(e)
+}
+
+func (e *ReportStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportStatus", str)
+	}
+	return nil
+}
+
+func (e ReportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SavedStudysetsOrder string
+
+const (
+	SavedStudysetsOrderLastStudied SavedStudysetsOrder = "LAST_STUDIED"
+	SavedStudysetsOrderSavedAt     SavedStudysetsOrder = "SAVED_AT"
+)
+
+var AllSavedStudysetsOrder = []SavedStudysetsOrder{
+	SavedStudysetsOrderLastStudied,
+	SavedStudysetsOrderSavedAt,
+}
+
+func (e SavedStudysetsOrder) IsValid() bool {
+	switch e {
+	case SavedStudysetsOrderLastStudied, SavedStudysetsOrderSavedAt:
+		return true
+	}
+	return false
+}
+
+func (e SavedStudysetsOrder) String() string {
+	return string(e)
+}
+
+func (e *SavedStudysetsOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SavedStudysetsOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SavedStudysetsOrder", str)
+	}
+	return nil
+}
+
+func (e SavedStudysetsOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SavedStudysetsOrder) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SavedStudysetsOrder) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SuggestionStatus string
+
+const (
+	SuggestionStatusOpen     SuggestionStatus = "OPEN"
+	SuggestionStatusAccepted SuggestionStatus = "ACCEPTED"
+	SuggestionStatusRejected SuggestionStatus = "REJECTED"
+)
+
+var AllSuggestionStatus = []SuggestionStatus{
+	SuggestionStatusOpen,
+	SuggestionStatusAccepted,
+	SuggestionStatusRejected,
+}
+
+func (e SuggestionStatus) IsValid() bool {
+	switch e {
+	case SuggestionStatusOpen, SuggestionStatusAccepted, SuggestionStatusRejected:
+		return true
+	}
+	return false
+}
+
+func (e SuggestionStatus) String() string {
+	return string(e)
+}
+
+func (e *SuggestionStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SuggestionStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SuggestionStatus", str)
+	}
+	return nil
+}
+
+func (e SuggestionStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SuggestionStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SuggestionStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TermChangeType string
+
+const (
+	TermChangeTypeAdded    TermChangeType = "ADDED"
+	TermChangeTypeRemoved  TermChangeType = "REMOVED"
+	TermChangeTypeModified TermChangeType = "MODIFIED"
+	TermChangeTypeMoved    TermChangeType = "MOVED"
+)
+
+var AllTermChangeType = []TermChangeType{
+	TermChangeTypeAdded,
+	TermChangeTypeRemoved,
+	TermChangeTypeModified,
+	TermChangeTypeMoved,
+}
+
+func (e TermChangeType) IsValid() bool {
+	switch e {
+	case TermChangeTypeAdded, TermChangeTypeRemoved, TermChangeTypeModified, TermChangeTypeMoved:
+		return true
+	}
+	return false
+}
+
+func (e TermChangeType) String() string {
+	return string(e)
+}
+
+func (e *TermChangeType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TermChangeType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TermChangeType", str)
+	}
+	return nil
+}
+
+func (e TermChangeType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TermChangeType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TermChangeType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TrendingWindow string
+
+const (
+	TrendingWindowDay   TrendingWindow = "DAY"
+	TrendingWindowWeek  TrendingWindow = "WEEK"
+	TrendingWindowMonth TrendingWindow = "MONTH"
+)
+
+var AllTrendingWindow = []TrendingWindow{
+	TrendingWindowDay,
+	TrendingWindowWeek,
+	TrendingWindowMonth,
+}
+
+func (e TrendingWindow) IsValid() bool {
+	switch e {
+	case TrendingWindowDay, TrendingWindowWeek, TrendingWindowMonth:
+		return true
+	}
+	return false
+}
+
+func (e TrendingWindow) String() string {
+	return string(e)
+}
+
+func (e *TrendingWindow) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TrendingWindow(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TrendingWindow", str)
+	}
+	return nil
+}
+
+func (e TrendingWindow) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TrendingWindow) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TrendingWindow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
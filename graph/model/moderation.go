@@ -0,0 +1,34 @@
+package model
+
+type StudysetReport struct {
+	ID         *string       `json:"id,omitempty"`
+	StudysetID *string       `json:"studysetId,omitempty"`
+	ReporterID *string       `json:"reporterId,omitempty"`
+	Reporter   *User         `json:"reporter,omitempty"`
+	Reason     *ReportReason `json:"reason,omitempty"`
+	Details    *string       `json:"details,omitempty"`
+	Status     *ReportStatus `json:"status,omitempty"`
+	CreatedAt  *string       `json:"createdAt,omitempty"`
+	UpdatedAt  *string       `json:"updatedAt,omitempty"`
+}
+
+type ModerationDecision struct {
+	ID          *string           `json:"id,omitempty"`
+	StudysetID  *string           `json:"studysetId,omitempty"`
+	ModeratorID *string           `json:"moderatorId,omitempty"`
+	Moderator   *User             `json:"moderator,omitempty"`
+	Action      *ModerationAction `json:"action,omitempty"`
+	Note        *string           `json:"note,omitempty"`
+	CreatedAt   *string           `json:"createdAt,omitempty"`
+}
+
+type ModerationCase struct {
+	StudysetID      *string               `json:"studysetId,omitempty"`
+	Studyset        *Studyset             `json:"studyset,omitempty"`
+	Status          *ReportStatus         `json:"status,omitempty"`
+	ReportCount     *int32                `json:"reportCount,omitempty"`
+	FirstReportedAt *string               `json:"firstReportedAt,omitempty"`
+	LastReportedAt  *string               `json:"lastReportedAt,omitempty"`
+	Reports         []*StudysetReport     `json:"reports,omitempty"`
+	Decisions       []*ModerationDecision `json:"decisions,omitempty"`
+}
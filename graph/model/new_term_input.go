@@ -0,0 +1,14 @@
+package model
+
+// NewTermInput is written by hand (instead of generated) so it can also be
+// the model for ImportedTerm, imports preview the terms they'd create
+type NewTermInput struct {
+	Term           *string  `json:"term,omitempty"`
+	Def            *string  `json:"def,omitempty"`
+	SortOrder      int32    `json:"sortOrder"`
+	TermImageID    *string  `json:"termImageId,omitempty"`
+	DefImageID     *string  `json:"defImageId,omitempty"`
+	TermAlternates []string `json:"termAlternates,omitempty"`
+	DefAlternates  []string `json:"defAlternates,omitempty"`
+	Hint           *string  `json:"hint,omitempty"`
+}
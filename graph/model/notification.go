@@ -0,0 +1,58 @@
+package model
+
+// Notifications are stored in the notifications table,
+// their id, kind, read, and createdAt are columns
+// and everything else is the data column's json
+
+type ModerationNotification struct {
+	ID         *string           `json:"-"`
+	Kind       *NotificationKind `json:"-"`
+	Read       *bool             `json:"-"`
+	CreatedAt  *string           `json:"-"`
+	StudysetID *string           `json:"studysetId,omitempty"`
+	Title      *string           `json:"title,omitempty"`
+	Action     *ModerationAction `json:"action,omitempty"`
+	Note       *string           `json:"note,omitempty"`
+}
+
+func (ModerationNotification) IsNotification() {}
+
+// CommentNotification is for comments on your studysets and replies to your comments
+type CommentNotification struct {
+	ID         *string           `json:"-"`
+	Kind       *NotificationKind `json:"-"`
+	Read       *bool             `json:"-"`
+	CreatedAt  *string           `json:"-"`
+	StudysetID *string           `json:"studysetId,omitempty"`
+	Title      *string           `json:"title,omitempty"`
+	TermID     *string           `json:"termId,omitempty"`
+	CommentID  *string           `json:"commentId,omitempty"`
+	UserID     *string           `json:"userId,omitempty"`
+}
+
+func (CommentNotification) IsNotification() {}
+
+// SuggestionNotification is for suggested edits to your studysets,
+// and your suggestions being accepted or rejected
+type SuggestionNotification struct {
+	ID           *string           `json:"-"`
+	Kind         *NotificationKind `json:"-"`
+	Read         *bool             `json:"-"`
+	CreatedAt    *string           `json:"-"`
+	StudysetID   *string           `json:"studysetId,omitempty"`
+	Title        *string           `json:"title,omitempty"`
+	SuggestionID *string           `json:"suggestionId,omitempty"`
+	UserID       *string           `json:"userId,omitempty"`
+}
+
+func (SuggestionNotification) IsNotification() {}
+
+type FollowNotification struct {
+	ID        *string           `json:"-"`
+	Kind      *NotificationKind `json:"-"`
+	Read      *bool             `json:"-"`
+	CreatedAt *string           `json:"-"`
+	UserID    *string           `json:"userId,omitempty"`
+}
+
+func (FollowNotification) IsNotification() {}
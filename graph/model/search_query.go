@@ -3,4 +3,5 @@ package model
 type SearchQuery struct {
 	Query   *string `json:"query,omitempty"`
 	Subject *string `json:"subject,omitempty"`
+	Language *string `json:"language,omitempty"`
 }
@@ -4,9 +4,15 @@ type Studyset struct {
 	ID        *string `json:"id,omitempty"`
 	Title     *string `json:"title,omitempty"`
 	Private   *bool   `json:"private,omitempty"`
+	TermLanguage *string `json:"termLanguage,omitempty"`
+	DefLanguage  *string `json:"defLanguage,omitempty"`
+	Version   *int32  `json:"version,omitempty"`
 	UpdatedAt *string `json:"updatedAt,omitempty"`
+	DeletedAt *string `json:"deletedAt,omitempty"`
 	UserID      *string   `json:"userId,omitempty"`
 	User      *User   `json:"user,omitempty"`
 	Terms     []*Term `json:"terms,omitempty"`
 	TermsCount     []*Term `json:"termsCount,omitempty"`
+	// only set by createStudyset & updateStudyset with checkDuplicates
+	DuplicateWarnings []*DuplicateTermGroup `json:"duplicateWarnings,omitempty"`
 }
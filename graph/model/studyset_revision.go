@@ -0,0 +1,12 @@
+package model
+
+type StudysetRevision struct {
+	ID         *string                 `json:"id,omitempty"`
+	StudysetID *string                 `json:"studysetId,omitempty"`
+	CreatedAt  *string                 `json:"createdAt,omitempty"`
+	UserID     *string                 `json:"userId,omitempty"`
+	User       *User                   `json:"user,omitempty"`
+	Title      *string                 `json:"title,omitempty"`
+	Private    *bool                   `json:"private,omitempty"`
+	Terms      []*StudysetRevisionTerm `json:"terms,omitempty"`
+}
@@ -0,0 +1,10 @@
+package model
+
+type StudysetSettings struct {
+	AnswerWith      *AnswerSide    `json:"answerWith,omitempty"`
+	Shuffle         *bool          `json:"shuffle,omitempty"`
+	QuestionTypes   []QuestionType `json:"questionTypes,omitempty"`
+	LeitnerBoxCount *int32         `json:"leitnerBoxCount,omitempty"`
+	Audio           *bool          `json:"audio,omitempty"`
+	UpdatedAt       *string        `json:"-"`
+}
@@ -0,0 +1,43 @@
+package model
+
+type StudysetSuggestion struct {
+	ID         *string                 `json:"id,omitempty"`
+	StudysetID *string                 `json:"studysetId,omitempty"`
+	Studyset   *Studyset               `json:"studyset,omitempty"`
+	UserID     *string                 `json:"-"`
+	User       *User                   `json:"user,omitempty"`
+	Message    *string                 `json:"message,omitempty"`
+	Edits      []*SuggestedTermEdit    `json:"edits,omitempty"`
+	Additions  []*SuggestedNewTerm     `json:"additions,omitempty"`
+	Removals   []*SuggestedTermRemoval `json:"removals,omitempty"`
+	Status     *SuggestionStatus       `json:"status,omitempty"`
+	CreatedAt  *string                 `json:"createdAt,omitempty"`
+	UpdatedAt  *string                 `json:"updatedAt,omitempty"`
+	ResolvedAt *string                 `json:"resolvedAt,omitempty"`
+}
+
+// SuggestedTermEdit is stored in studyset_suggestions.edits as json,
+// nil fields aren't changed. Its alternates aren't omitempty,
+// so suggesting to remove all of them (an empty list) isn't lost
+type SuggestedTermEdit struct {
+	TermID         *string  `json:"termId,omitempty"`
+	BaseVersion    *int32   `json:"baseVersion,omitempty"`
+	Term           *string  `json:"term,omitempty"`
+	Def            *string  `json:"def,omitempty"`
+	TermAlternates []string `json:"termAlternates"`
+	DefAlternates  []string `json:"defAlternates"`
+	Hint           *string  `json:"hint,omitempty"`
+}
+
+type SuggestedNewTerm struct {
+	Term           *string  `json:"term,omitempty"`
+	Def            *string  `json:"def,omitempty"`
+	TermAlternates []string `json:"termAlternates,omitempty"`
+	DefAlternates  []string `json:"defAlternates,omitempty"`
+	Hint           *string  `json:"hint,omitempty"`
+}
+
+type SuggestedTermRemoval struct {
+	TermID      *string `json:"termId,omitempty"`
+	BaseVersion *int32  `json:"baseVersion,omitempty"`
+}
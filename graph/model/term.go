@@ -4,9 +4,25 @@ type Term struct {
 	ID        *string       `json:"id,omitempty"`
 	Term      *string       `json:"term,omitempty"`
 	Def       *string       `json:"def,omitempty"`
-	StudysetID *string
+	TermHTML  *string       `json:"termHtml,omitempty"`
+	DefHTML   *string       `json:"defHtml,omitempty"`
+	TermPlain *string       `json:"-"`
+	DefPlain  *string       `json:"-"`
+	TermLanguage *string    `json:"-"`
+	DefLanguage  *string    `json:"-"`
+	TermAlternates []string `json:"termAlternates,omitempty"`
+	DefAlternates []string  `json:"defAlternates,omitempty"`
+	Hint      *string       `json:"hint,omitempty"`
+	MyNote    *TermNote     `json:"myNote,omitempty"`
+	StudysetID *string      `json:"studysetId,omitempty"`
 	SortOrder *int32        `json:"sortOrder,omitempty"`
+	TermImageID *string     `json:"termImageId,omitempty"`
+	TermImage *Image        `json:"termImage,omitempty"`
+	DefImageID *string      `json:"defImageId,omitempty"`
+	DefImage  *Image        `json:"defImage,omitempty"`
 	Progress  *TermProgress `json:"progress,omitempty"`
+	Version   *int32        `json:"version,omitempty"`
 	CreatedAt *string       `json:"createdAt,omitempty"`
 	UpdatedAt *string       `json:"updatedAt,omitempty"`
+	DeletedAt *string       `json:"deletedAt,omitempty"`
 }
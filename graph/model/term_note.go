@@ -0,0 +1,9 @@
+package model
+
+type TermNote struct {
+	ID        *string `json:"id,omitempty"`
+	TermID    *string `json:"termId,omitempty"`
+	Note      *string `json:"note,omitempty"`
+	CreatedAt *string `json:"createdAt,omitempty"`
+	UpdatedAt *string `json:"updatedAt,omitempty"`
+}
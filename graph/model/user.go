@@ -0,0 +1,16 @@
+package model
+
+type User struct {
+	ID                   *string         `json:"id,omitempty"`
+	Username             *string         `json:"username,omitempty"`
+	DisplayName          *string         `json:"displayName,omitempty"`
+	Bio                  *string         `json:"bio,omitempty"`
+	AvatarImageID        *string         `json:"-"`
+	Avatar               *Image          `json:"avatar,omitempty"`
+	JoinedAt             *string         `json:"joinedAt,omitempty"`
+	Studysets            []*Studyset     `json:"studysets,omitempty"`
+	Privacy              *ProfilePrivacy `json:"privacy,omitempty"`
+	ProfilePrivate       *bool           `json:"-"`
+	ProfileShowStudysets *bool           `json:"-"`
+	ProfileShowJoinedAt  *bool           `json:"-"`
+}
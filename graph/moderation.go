@@ -0,0 +1,364 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"strings"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultReportAutoHideThreshold = 3
+	maxReportDetailsLength         = 1000
+	maxModerationNoteLength        = 1000
+)
+
+// reportStudyset reports someone else's public studyset, reporting it again updates the report,
+// unless it was already resolved, so one user can't keep reopening a report that was dismissed.
+// Once a studyset has threshold open reports, it's hidden until a moderator reviews it
+func (r *Resolver) reportStudyset(ctx context.Context, reporterID *string, studysetID string, reason model.ReportReason, details *string) (*model.StudysetReport, error) {
+	if details != nil {
+		trimmed := strings.TrimSpace(*details)
+		if len(trimmed) > maxReportDetailsLength {
+			return nil, fmt.Errorf("details must be at most %d characters", maxReportDetailsLength)
+		}
+		details = &trimmed
+		if trimmed == "" {
+			details = nil
+		}
+	}
+
+	threshold := r.ReportAutoHideThreshold
+	if threshold <= 0 {
+		threshold = DefaultReportAutoHideThreshold
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID *string
+	var title string
+	err = tx.QueryRow(
+		ctx,
+		`SELECT user_id, title FROM public.studysets
+WHERE id = $1 AND private = false AND deleted_at IS NULL
+FOR UPDATE`,
+		studysetID,
+	).Scan(&ownerID, &title)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not found")
+		}
+		return nil, fmt.Errorf("failed to report studyset: %w", err)
+	}
+	if ownerID != nil && reporterID != nil && *ownerID == *reporterID {
+		return nil, fmt.Errorf("you can't report your own studyset")
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO studyset_reports (studyset_id, reporter_id, reason, details)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (studyset_id, reporter_id) DO UPDATE SET
+	reason = EXCLUDED.reason,
+	details = EXCLUDED.details,
+	updated_at = now()
+WHERE studyset_reports.status = 'OPEN'`,
+		studysetID,
+		reporterID,
+		reason,
+		details,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to report studyset: %w", err)
+	}
+
+	var report model.StudysetReport
+	err = pgxscan.Get(
+		ctx,
+		tx,
+		&report,
+		`SELECT id, studyset_id, reporter_id, reason, details, status,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM studyset_reports
+WHERE studyset_id = $1 AND reporter_id = $2`,
+		studysetID,
+		reporterID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report: %w", err)
+	}
+
+	var openReports int
+	err = tx.QueryRow(
+		ctx,
+		"SELECT count(*) FROM studyset_reports WHERE studyset_id = $1 AND status = 'OPEN'",
+		studysetID,
+	).Scan(&openReports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	if openReports >= threshold {
+		if _, err := applyModerationAction(ctx, tx, studysetID, nil, model.ModerationActionAutoHide, nil); err != nil {
+			return nil, err
+		}
+		if ownerID != nil {
+			if err := notifyModerationDecision(ctx, tx, *ownerID, studysetID, title, model.ModerationActionAutoHide, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &report, nil
+}
+
+// moderateStudyset resolves a studyset's open reports with a moderator's decision:
+// DISMISS lets it be public again if it was hidden, HIDE makes it private until a moderator dismisses it,
+// and REMOVE also moves it to the trash
+func (r *Resolver) moderateStudyset(ctx context.Context, moderatorID *string, studysetID string, action model.ModerationAction, note *string) (*model.ModerationDecision, error) {
+	if action == model.ModerationActionAutoHide {
+		return nil, fmt.Errorf("AUTO_HIDE is only for automatic decisions")
+	}
+	if note != nil {
+		trimmed := strings.TrimSpace(*note)
+		if len(trimmed) > maxModerationNoteLength {
+			return nil, fmt.Errorf("note must be at most %d characters", maxModerationNoteLength)
+		}
+		note = &trimmed
+		if trimmed == "" {
+			note = nil
+		}
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	/* moderators can moderate studysets that are hidden or in the trash */
+	var ownerID *string
+	var title string
+	var held bool
+	err = tx.QueryRow(
+		ctx,
+		"SELECT user_id, title, moderation_hold FROM public.studysets WHERE id = $1 FOR UPDATE",
+		studysetID,
+	).Scan(&ownerID, &title, &held)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not found")
+		}
+		return nil, fmt.Errorf("failed to moderate studyset: %w", err)
+	}
+
+	decision, err := applyModerationAction(ctx, tx, studysetID, moderatorID, action, note)
+	if err != nil {
+		return nil, err
+	}
+
+	/* dismissing reports on a studyset the author never knew about isn't worth telling them */
+	if ownerID != nil && (action != model.ModerationActionDismiss || held) {
+		if err := notifyModerationDecision(ctx, tx, *ownerID, studysetID, title, action, note); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return decision, nil
+}
+
+// applyModerationAction changes a studyset & its open reports for a decision, and records the decision,
+// moderatorID is nil for automatic decisions
+func applyModerationAction(ctx context.Context, tx pgx.Tx, studysetID string, moderatorID *string, action model.ModerationAction, note *string) (*model.ModerationDecision, error) {
+	var studysetSQL, reportStatus string
+	switch action {
+	case model.ModerationActionDismiss:
+		/* held studysets were public before they were hidden */
+		studysetSQL = `UPDATE public.studysets
+SET private = CASE WHEN moderation_hold THEN false ELSE private END,
+	moderation_hold = false
+WHERE id = $1`
+		reportStatus = "DISMISSED"
+	case model.ModerationActionHide:
+		studysetSQL = "UPDATE public.studysets SET private = true, moderation_hold = true WHERE id = $1"
+		reportStatus = "ACTIONED"
+	case model.ModerationActionRemove:
+		studysetSQL = `UPDATE public.studysets
+SET private = true, moderation_hold = true, deleted_at = COALESCE(deleted_at, now())
+WHERE id = $1`
+		reportStatus = "ACTIONED"
+	case model.ModerationActionAutoHide:
+		/* reports stay open for a moderator to review */
+		studysetSQL = "UPDATE public.studysets SET private = true, moderation_hold = true WHERE id = $1"
+	default:
+		return nil, fmt.Errorf("invalid moderation action")
+	}
+
+	if _, err := tx.Exec(ctx, studysetSQL, studysetID); err != nil {
+		return nil, fmt.Errorf("failed to moderate studyset: %w", err)
+	}
+
+	if reportStatus != "" {
+		_, err := tx.Exec(
+			ctx,
+			`UPDATE studyset_reports
+SET status = $2, resolved_at = now(), resolved_by = $3, updated_at = now()
+WHERE studyset_id = $1 AND status = 'OPEN'`,
+			studysetID,
+			reportStatus,
+			moderatorID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve reports: %w", err)
+		}
+	}
+
+	/* hidden studysets aren't in anyone else's saved studysets anymore */
+	if err := removeHiddenStars(ctx, tx, studysetID); err != nil {
+		return nil, err
+	}
+
+	var decision model.ModerationDecision
+	err := pgxscan.Get(
+		ctx,
+		tx,
+		&decision,
+		`INSERT INTO moderation_decisions (studyset_id, moderator_id, action, note)
+VALUES ($1, $2, $3, $4)
+RETURNING id, studyset_id, moderator_id, action, note,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at`,
+		studysetID,
+		moderatorID,
+		action,
+		note,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record moderation decision: %w", err)
+	}
+	return &decision, nil
+}
+
+func notifyModerationDecision(ctx context.Context, tx pgx.Tx, userID string, studysetID string, title string, action model.ModerationAction, note *string) error {
+	return notify(ctx, tx, userID, model.NotificationKindModerationDecision, &model.ModerationNotification{
+		StudysetID: &studysetID,
+		Title:      &title,
+		Action:     &action,
+		Note:       note,
+	})
+}
+
+// moderationQueue gets studysets with reports in a status, most reported first,
+// with their reports and past decisions
+func (r *Resolver) moderationQueue(ctx context.Context, status model.ReportStatus, limit int, offset int) ([]*model.ModerationCase, error) {
+	var cases []*model.ModerationCase
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&cases,
+		`SELECT studyset_id, status, count(*)::int AS report_count,
+	to_char(min(created_at), 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as first_reported_at,
+	to_char(max(created_at), 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as last_reported_at
+FROM studyset_reports
+WHERE status = $1
+GROUP BY studyset_id, status
+ORDER BY count(*) DESC, min(created_at)
+LIMIT $2 OFFSET $3`,
+		status,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation queue: %w", err)
+	}
+	if len(cases) == 0 {
+		return cases, nil
+	}
+
+	studysetIDs := make([]string, 0, len(cases))
+	byStudysetID := make(map[string]*model.ModerationCase, len(cases))
+	for _, c := range cases {
+		studysetIDs = append(studysetIDs, *c.StudysetID)
+		byStudysetID[*c.StudysetID] = c
+	}
+
+	/* hidden & removed studysets are included, moderators need to see what was reported */
+	var studysets []*model.Studyset
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&studysets,
+		`SELECT id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at,
+	to_char(deleted_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as deleted_at
+FROM public.studysets
+WHERE id = ANY($1)`,
+		studysetIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reported studysets: %w", err)
+	}
+	for _, s := range studysets {
+		byStudysetID[*s.ID].Studyset = s
+	}
+
+	var reports []*model.StudysetReport
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&reports,
+		`SELECT id, studyset_id, reporter_id, reason, details, status,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM studyset_reports
+WHERE studyset_id = ANY($1) AND status = $2
+ORDER BY created_at`,
+		studysetIDs,
+		status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reports: %w", err)
+	}
+	for _, report := range reports {
+		c := byStudysetID[*report.StudysetID]
+		c.Reports = append(c.Reports, report)
+	}
+
+	var decisions []*model.ModerationDecision
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&decisions,
+		`SELECT id, studyset_id, moderator_id, action, note,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at
+FROM moderation_decisions
+WHERE studyset_id = ANY($1)
+ORDER BY created_at DESC`,
+		studysetIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation decisions: %w", err)
+	}
+	for _, decision := range decisions {
+		c := byStudysetID[*decision.StudysetID]
+		c.Decisions = append(c.Decisions, decision)
+	}
+
+	return cases, nil
+}
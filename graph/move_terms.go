@@ -0,0 +1,267 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"slices"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// touchStudyset marks a studyset as changed (updated_at & version) after its terms were,
+// and returns it
+func touchStudyset(ctx context.Context, tx pgx.Tx, studysetID string) (*model.Studyset, error) {
+	var studyset model.Studyset
+	err := pgxscan.Get(
+		ctx,
+		tx,
+		&studyset,
+		`UPDATE public.studysets
+SET updated_at = now(), version = version + 1
+WHERE id = $1
+RETURNING id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at`,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update studyset: %w", err)
+	}
+	return &studyset, nil
+}
+
+// moveTerms moves terms (in the order of termIDs) to right after afterTermID,
+// or to the start of the studyset if afterTermID is nil,
+// every term in the studyset gets renumbered in one statement so sort_order stays unique
+func (r *Resolver) moveTerms(ctx context.Context, userID *string, studysetID string, termIDs []string, afterTermID *string) (*model.Studyset, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	/* locks the studyset, so two moves can't interleave */
+	if err := checkStudysetVersions(ctx, tx, studysetID, userID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT id::text FROM terms
+WHERE studyset_id = $1 AND deleted_at IS NULL
+ORDER BY sort_order, created_at, id`,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+	currentOrder, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+
+	moving := make(map[string]bool, len(termIDs))
+	var moved []string
+	for _, id := range termIDs {
+		if moving[id] {
+			continue
+		}
+		if !slices.Contains(currentOrder, id) {
+			return nil, fmt.Errorf("term not found")
+		}
+		moving[id] = true
+		moved = append(moved, id)
+	}
+	if afterTermID != nil {
+		if moving[*afterTermID] {
+			return nil, fmt.Errorf("afterTermId can't be one of the terms being moved")
+		}
+		if !slices.Contains(currentOrder, *afterTermID) {
+			return nil, fmt.Errorf("term not found")
+		}
+	}
+
+	newOrder := make([]string, 0, len(currentOrder))
+	if afterTermID == nil {
+		newOrder = append(newOrder, moved...)
+	}
+	for _, id := range currentOrder {
+		if moving[id] {
+			continue
+		}
+		newOrder = append(newOrder, id)
+		if afterTermID != nil && id == *afterTermID {
+			newOrder = append(newOrder, moved...)
+		}
+	}
+
+	/* only the moved terms count as changed for their versions,
+	the others just shift over */
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE terms t
+SET sort_order = input.og_order - 1,
+	version = CASE WHEN t.id = ANY($2) THEN t.version + 1 ELSE t.version END,
+	updated_at = CASE WHEN t.id = ANY($2) THEN now() ELSE t.updated_at END
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(id, og_order)
+WHERE t.id = input.id AND t.sort_order <> input.og_order - 1`,
+		newOrder,
+		moved,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move terms: %w", err)
+	}
+
+	studyset, err := touchStudyset(ctx, tx, studysetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordStudysetRevision(ctx, tx, studysetID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return studyset, nil
+}
+
+// moveTermsToStudyset moves terms (in the order of termIDs) to the end of another studyset,
+// the terms keep their ids, so everything that references them
+// (everyone's progress, confusion pairs, notes, comments) moves with them
+func (r *Resolver) moveTermsToStudyset(ctx context.Context, userID *string, termIDs []string, targetStudysetID string) (*model.Studyset, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var terms []struct {
+		ID         string
+		StudysetID string
+	}
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&terms,
+		`SELECT t.id, t.studyset_id
+FROM terms t
+JOIN public.studysets s ON s.id = t.studyset_id
+WHERE t.id = ANY($1)
+	AND t.deleted_at IS NULL
+	AND s.user_id = $2
+	AND s.deleted_at IS NULL`,
+		termIDs,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+
+	found := make(map[string]bool, len(terms))
+	studysetIDs := []string{targetStudysetID}
+	for _, t := range terms {
+		found[t.ID] = true
+		if !slices.Contains(studysetIDs, t.StudysetID) {
+			studysetIDs = append(studysetIDs, t.StudysetID)
+		}
+	}
+	for _, id := range termIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("term not found")
+		}
+	}
+
+	/* every studyset involved gets locked, always in the same order
+	so two moves between the same studysets can't deadlock */
+	rows, err := tx.Query(
+		ctx,
+		`SELECT id::text FROM public.studysets
+WHERE id = ANY($1) AND user_id = $2 AND deleted_at IS NULL
+ORDER BY id
+FOR UPDATE`,
+		studysetIDs,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock studysets: %w", err)
+	}
+	locked, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock studysets: %w", err)
+	}
+	if !slices.Contains(locked, targetStudysetID) {
+		return nil, fmt.Errorf("studyset not found")
+	}
+	if len(locked) != len(studysetIDs) {
+		/* a term's studyset was deleted since it was fetched */
+		return nil, fmt.Errorf("term not found")
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE terms t
+SET studyset_id = s.id,
+	sort_order = (
+		SELECT COALESCE(max(sort_order), -1) FROM terms
+		WHERE studyset_id = s.id AND deleted_at IS NULL
+	) + input.og_order,
+	term_search_config = s.term_search_config,
+	def_search_config = s.def_search_config,
+	version = t.version + 1,
+	updated_at = now()
+FROM unnest($1::uuid[]) WITH ORDINALITY AS input(id, og_order), public.studysets s
+WHERE t.id = input.id AND s.id = $2`,
+		termIDs,
+		targetStudysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move terms: %w", err)
+	}
+
+	/* comments on the terms (and replies, which have their parent's term_id) go with them */
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE studyset_comments SET studyset_id = $2 WHERE term_id = ANY($1)",
+		termIDs,
+		targetStudysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move term comments: %w", err)
+	}
+
+	var target *model.Studyset
+	for _, id := range studysetIDs {
+		if err := renumberTerms(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		studyset, err := touchStudyset(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if id == targetStudysetID {
+			target = studyset
+			forcedPrivate, err := r.filterStudysetContent(ctx, tx, id)
+			if err != nil {
+				return nil, err
+			}
+			if forcedPrivate {
+				target.Private = &forcedPrivate
+			}
+			if err := recordStudysetUpdateEvent(ctx, tx, id, false, len(termIDs)); err != nil {
+				return nil, err
+			}
+		}
+		if err := recordStudysetRevision(ctx, tx, id, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return target, nil
+}
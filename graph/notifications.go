@@ -0,0 +1,228 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"quizfreely/api/graph/model"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// unmutableNotificationKinds are always sent, users need to know
+// when moderators do something to their studysets
+var unmutableNotificationKinds = map[model.NotificationKind]bool{
+	model.NotificationKindModerationDecision: true,
+}
+
+// notify adds a notification for a user inside tx,
+// so it's only sent if whatever it's about actually gets saved.
+// The notification's fields are saved as its data, except the ones every notification has,
+// and it's skipped if the user muted its kind
+func notify(ctx context.Context, tx pgx.Tx, userID string, kind model.NotificationKind, notification model.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to add notification: %w", err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO notifications (user_id, kind, data)
+SELECT $1, $2::text, $3
+WHERE $4 OR NOT EXISTS (
+	SELECT 1 FROM notification_mutes m WHERE m.user_id = $1 AND m.kind = $2::text
+)`,
+		userID,
+		kind,
+		data,
+		unmutableNotificationKinds[kind],
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add notification: %w", err)
+	}
+	return nil
+}
+
+type notificationRow struct {
+	ID        string
+	Kind      model.NotificationKind
+	Data      []byte
+	Read      bool
+	CreatedAt string
+}
+
+// decodeNotification makes a notification row into its type in the Notification union,
+// kinds that this version of the API doesn't know about are nil
+func decodeNotification(row notificationRow) (model.Notification, error) {
+	var notification model.Notification
+	switch row.Kind {
+	case model.NotificationKindModerationDecision:
+		n := model.ModerationNotification{ID: &row.ID, Kind: &row.Kind, Read: &row.Read, CreatedAt: &row.CreatedAt}
+		if err := json.Unmarshal(row.Data, &n); err != nil {
+			return nil, err
+		}
+		notification = &n
+	case model.NotificationKindStudysetComment, model.NotificationKindCommentReply:
+		n := model.CommentNotification{ID: &row.ID, Kind: &row.Kind, Read: &row.Read, CreatedAt: &row.CreatedAt}
+		if err := json.Unmarshal(row.Data, &n); err != nil {
+			return nil, err
+		}
+		notification = &n
+	case model.NotificationKindStudysetSuggestion, model.NotificationKindSuggestionAccepted, model.NotificationKindSuggestionRejected:
+		n := model.SuggestionNotification{ID: &row.ID, Kind: &row.Kind, Read: &row.Read, CreatedAt: &row.CreatedAt}
+		if err := json.Unmarshal(row.Data, &n); err != nil {
+			return nil, err
+		}
+		notification = &n
+	case model.NotificationKindNewFollower:
+		n := model.FollowNotification{ID: &row.ID, Kind: &row.Kind, Read: &row.Read, CreatedAt: &row.CreatedAt}
+		if err := json.Unmarshal(row.Data, &n); err != nil {
+			return nil, err
+		}
+		notification = &n
+	}
+	return notification, nil
+}
+
+// myNotifications lists a user's notifications, newest first, with how many are unread
+func (r *Resolver) myNotifications(ctx context.Context, userID *string, unreadOnly bool, limit int, offset int) (*model.NotificationList, error) {
+	rows, err := r.DB.Query(
+		ctx,
+		`SELECT id, kind, data, read_at IS NOT NULL,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM')
+FROM notifications
+WHERE user_id = $1 AND kind = ANY($2) AND (NOT $3 OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT $4 OFFSET $5`,
+		userID,
+		model.AllNotificationKind,
+		unreadOnly,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []model.Notification{}
+	for rows.Next() {
+		var row notificationRow
+		if err := rows.Scan(&row.ID, &row.Kind, &row.Data, &row.Read, &row.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notification, err := decodeNotification(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode notification %s: %w", row.ID, err)
+		}
+		if notification != nil {
+			notifications = append(notifications, notification)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+
+	unreadCount, err := r.unreadNotificationsCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NotificationList{
+		Notifications: notifications,
+		UnreadCount:   &unreadCount,
+	}, nil
+}
+
+func (r *Resolver) unreadNotificationsCount(ctx context.Context, userID *string) (int32, error) {
+	var count int32
+	err := r.DB.QueryRow(
+		ctx,
+		"SELECT count(*) FROM notifications WHERE user_id = $1 AND kind = ANY($2) AND read_at IS NULL",
+		userID,
+		model.AllNotificationKind,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
+
+// markNotificationsRead marks some (or all) of a user's notifications read,
+// and returns how many are still unread
+func (r *Resolver) markNotificationsRead(ctx context.Context, userID *string, ids []string, all bool) (int32, error) {
+	if !all && len(ids) == 0 {
+		return 0, fmt.Errorf("ids or all is required")
+	}
+
+	_, err := r.DB.Exec(
+		ctx,
+		`UPDATE notifications SET read_at = now()
+WHERE user_id = $1 AND read_at IS NULL AND ($2 OR id = ANY($3))`,
+		userID,
+		all,
+		ids,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	return r.unreadNotificationsCount(ctx, userID)
+}
+
+// notificationPreferences is every kind of notification and whether a user muted it
+func (r *Resolver) notificationPreferences(ctx context.Context, userID *string) ([]*model.NotificationPreference, error) {
+	rows, err := r.DB.Query(ctx, "SELECT kind FROM notification_mutes WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification preferences: %w", err)
+	}
+	muted, err := pgx.CollectRows(rows, pgx.RowTo[model.NotificationKind])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification preferences: %w", err)
+	}
+	mutedKinds := make(map[model.NotificationKind]bool, len(muted))
+	for _, kind := range muted {
+		mutedKinds[kind] = true
+	}
+
+	preferences := make([]*model.NotificationPreference, 0, len(model.AllNotificationKind))
+	for _, kind := range model.AllNotificationKind {
+		preferences = append(preferences, notificationPreference(kind, mutedKinds[kind]))
+	}
+	return preferences, nil
+}
+
+func notificationPreference(kind model.NotificationKind, muted bool) *model.NotificationPreference {
+	canMute := !unmutableNotificationKinds[kind]
+	muted = muted && canMute
+	return &model.NotificationPreference{
+		Kind:    &kind,
+		Muted:   &muted,
+		CanMute: &canMute,
+	}
+}
+
+// setNotificationMuted mutes or unmutes a kind of notification for a user,
+// muting only stops new notifications, it doesn't hide ones they already got
+func (r *Resolver) setNotificationMuted(ctx context.Context, userID *string, kind model.NotificationKind, muted bool) (*model.NotificationPreference, error) {
+	if unmutableNotificationKinds[kind] {
+		return nil, fmt.Errorf("%s notifications can't be muted", kind)
+	}
+
+	var err error
+	if muted {
+		_, err = r.DB.Exec(
+			ctx,
+			"INSERT INTO notification_mutes (user_id, kind) VALUES ($1, $2) ON CONFLICT (user_id, kind) DO NOTHING",
+			userID,
+			kind,
+		)
+	} else {
+		_, err = r.DB.Exec(ctx, "DELETE FROM notification_mutes WHERE user_id = $1 AND kind = $2", userID, kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+
+	return notificationPreference(kind, muted), nil
+}
@@ -0,0 +1,185 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	/* a study counts more than a view, since it means someone actually used the studyset */
+	studyEventWeight = 3
+	/* popularStudysets looks at the last year, and a day's score halves every month */
+	popularWindowDays   = 365
+	popularHalfLifeDays = 30
+)
+
+// trendingWindowDays is how many days (including today) each TrendingWindow looks at
+var trendingWindowDays = map[model.TrendingWindow]int{
+	model.TrendingWindowDay:   1,
+	model.TrendingWindowWeek:  7,
+	model.TrendingWindowMonth: 30,
+}
+
+// recordStudysetView records that a user (or signed-out visitor) viewed a public studyset,
+// at most once per viewer per day, owners viewing their own studysets don't count.
+// Signed-out visitors are identified by VisitorMiddleware's hash
+func recordStudysetView(ctx context.Context, db *pgxpool.Pool, studysetID string, userID *string) error {
+	var actor string
+	if userID != nil {
+		actor = "u:" + *userID
+	} else if visitor := visitorContext(ctx); visitor != "" {
+		actor = "v:" + visitor
+	} else {
+		return nil
+	}
+
+	_, err := db.Exec(
+		ctx,
+		`INSERT INTO studyset_events (studyset_id, kind, actor)
+SELECT s.id, 'view', $2
+FROM public.studysets s
+WHERE s.id = $1
+	AND s.private = false
+	AND s.deleted_at IS NULL
+	AND s.user_id IS DISTINCT FROM $3
+ON CONFLICT DO NOTHING`,
+		studysetID,
+		actor,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record studyset view: %w", err)
+	}
+	return nil
+}
+
+// recordStudysetStudy records that a user studied a public studyset (by its id, or one of its terms' ids),
+// at most once per user per day, and adds them to its learners
+func recordStudysetStudy(ctx context.Context, db *pgxpool.Pool, userID *string, studysetID *string, termID *string) error {
+	_, err := db.Exec(
+		ctx,
+		`WITH target AS (
+	SELECT s.id
+	FROM public.studysets s
+	WHERE s.id = COALESCE($2::uuid, (SELECT t.studyset_id FROM terms t WHERE t.id = $3::uuid))
+		AND s.private = false
+		AND s.deleted_at IS NULL
+		AND s.user_id IS DISTINCT FROM $1
+), learner AS (
+	INSERT INTO studyset_learners (studyset_id, user_id)
+	SELECT id, $1 FROM target
+	ON CONFLICT DO NOTHING
+)
+INSERT INTO studyset_events (studyset_id, kind, actor)
+SELECT id, 'study', 'u:' || $1::text FROM target
+ON CONFLICT DO NOTHING`,
+		userID,
+		studysetID,
+		termID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record studyset study: %w", err)
+	}
+	return nil
+}
+
+// logStudysetEvent logs errors from recording views & studies instead of returning them,
+// popularity stats shouldn't make studying fail
+func logStudysetEvent(err error) {
+	if err != nil {
+		log.Error().Err(err).Msg("Database error recording studyset event")
+	}
+}
+
+// RollupStudysetStats counts events into studyset_daily_stats,
+// then deletes events from before yesterday, which can't get any more duplicates to dedupe
+func RollupStudysetStats(ctx context.Context, db *pgxpool.Pool) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO studyset_daily_stats (studyset_id, day, views, studies)
+SELECT studyset_id, day,
+	count(*) FILTER (WHERE kind = 'view'),
+	count(*) FILTER (WHERE kind = 'study')
+FROM studyset_events
+GROUP BY studyset_id, day
+ON CONFLICT (studyset_id, day) DO UPDATE SET
+	views = EXCLUDED.views,
+	studies = EXCLUDED.studies`,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM studyset_events WHERE day < current_date - 1")
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// StartStudysetStatsRollup runs RollupStudysetStats once right away,
+// then every interval, until ctx is cancelled
+func StartStudysetStatsRollup(ctx context.Context, db *pgxpool.Pool, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := RollupStudysetStats(ctx, db); err != nil {
+				log.Error().Err(err).Msg("Database error in RollupStudysetStats")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// rankedStudysets gets public studysets by a time-decayed score of their daily stats
+// from the last `days` days, each day's score halves every halfLifeDays
+func rankedStudysets(ctx context.Context, db pgxscan.Querier, days int, halfLifeDays float64, limit int, offset int) ([]*model.Studyset, error) {
+	var studysets []*model.Studyset
+	err := pgxscan.Select(
+		ctx,
+		db,
+		&studysets,
+		`SELECT s.id, s.user_id, s.title, s.private, s.term_language, s.def_language, s.version,
+	to_char(s.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM (
+	SELECT studyset_id,
+		sum(
+			(views + studies * $3) * power(0.5, (current_date - day)::float8 / $2)
+		) AS score
+	FROM studyset_daily_stats
+	WHERE day > current_date - $1::int
+	GROUP BY studyset_id
+) ranked
+JOIN public.studysets s ON s.id = ranked.studyset_id
+WHERE s.private = false AND s.deleted_at IS NULL
+ORDER BY ranked.score DESC, s.updated_at DESC
+LIMIT $4 OFFSET $5`,
+		days,
+		halfLifeDays,
+		studyEventWeight,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return studysets, nil
+}
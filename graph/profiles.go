@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/auth"
+	"quizfreely/api/graph/model"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+const maxBioLength = 500
+
+// profileColumns is everything a User is scanned from, privacy settings included,
+// the User resolvers decide what other people can see
+const profileColumns = `id, username, display_name, bio, avatar_image_id,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as joined_at,
+	profile_private, profile_show_studysets, profile_show_joined_at`
+
+func validateDisplayName(displayName string) error {
+	trimmedDisplayName := strings.TrimSpace(displayName)
+	if len(trimmedDisplayName) < 1 || len(trimmedDisplayName) > 32 {
+		return fmt.Errorf("display name must be between 1 and 32 characters")
+	}
+	if displayName != trimmedDisplayName {
+		return fmt.Errorf("display name must not have leading or trailing whitespace")
+	}
+	return nil
+}
+
+// isOwnProfile is true if the signed in user is the user
+func isOwnProfile(ctx context.Context, user *model.User) bool {
+	authedUser := auth.AuthedUserContext(ctx)
+	return authedUser != nil && authedUser.ID != nil && user.ID != nil && *authedUser.ID == *user.ID
+}
+
+// profileVisible is true if the signed in user can see more than the user's
+// username & display name (which are always shown, like on their studysets)
+func profileVisible(ctx context.Context, user *model.User) bool {
+	if isOwnProfile(ctx, user) {
+		return true
+	}
+	return user.ProfilePrivate == nil || !*user.ProfilePrivate
+}
+
+// getProfile gets a user by id or (lowercase) username
+func (r *Resolver) getProfile(ctx context.Context, column string, value string) (*model.User, error) {
+	var user model.User
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&user,
+		"SELECT "+profileColumns+" FROM auth.users WHERE "+column+" = $1",
+		value,
+	)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return &user, nil
+}
+
+// updateProfile changes the fields of a user's profile that are in the input,
+// an empty bio removes it
+func (r *Resolver) updateProfile(ctx context.Context, userID *string, profile model.ProfileInput) (*model.User, error) {
+	if profile.DisplayName != nil {
+		if err := validateDisplayName(*profile.DisplayName); err != nil {
+			return nil, err
+		}
+	}
+
+	var bio *string
+	if profile.Bio != nil {
+		trimmed := strings.TrimSpace(*profile.Bio)
+		if utf8.RuneCountInString(trimmed) > maxBioLength {
+			return nil, fmt.Errorf("bio must be at most %d characters", maxBioLength)
+		}
+		bio = &trimmed
+	}
+
+	removeAvatar := profile.RemoveAvatar != nil && *profile.RemoveAvatar
+	if removeAvatar && profile.AvatarImageID != nil {
+		return nil, fmt.Errorf("can't set and remove avatar at the same time")
+	}
+
+	var private, showStudysets, showJoinedAt *bool
+	if profile.Privacy != nil {
+		private = profile.Privacy.Private
+		showStudysets = profile.Privacy.ShowStudysets
+		showJoinedAt = profile.Privacy.ShowJoinedAt
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if profile.AvatarImageID != nil {
+		if err := checkImagesOwned(ctx, tx, userID, []string{*profile.AvatarImageID}); err != nil {
+			return nil, err
+		}
+	}
+
+	var user model.User
+	err = pgxscan.Get(
+		ctx,
+		tx,
+		&user,
+		`UPDATE auth.users
+SET display_name = COALESCE($2, display_name),
+	bio = CASE WHEN $3::text IS NULL THEN bio ELSE NULLIF($3, '') END,
+	avatar_image_id = CASE WHEN $5 THEN NULL ELSE COALESCE($4, avatar_image_id) END,
+	profile_private = COALESCE($6, profile_private),
+	profile_show_studysets = COALESCE($7, profile_show_studysets),
+	profile_show_joined_at = COALESCE($8, profile_show_joined_at)
+WHERE id = $1
+RETURNING `+profileColumns,
+		userID,
+		profile.DisplayName,
+		bio,
+		profile.AvatarImageID,
+		removeAvatar,
+		private,
+		showStudysets,
+		showJoinedAt,
+	)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &user, nil
+}
+
+// userStudysets lists a user's studysets, newest first,
+// people can only see each other's public studysets
+func (r *Resolver) userStudysets(ctx context.Context, user *model.User, limit int, offset int) ([]*model.Studyset, error) {
+	own := isOwnProfile(ctx, user)
+	if !own && (!profileVisible(ctx, user) || (user.ProfileShowStudysets != nil && !*user.ProfileShowStudysets)) {
+		return []*model.Studyset{}, nil
+	}
+
+	var studysets []*model.Studyset
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&studysets,
+		`SELECT id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM public.studysets
+WHERE user_id = $1 AND deleted_at IS NULL AND (private = false OR $2)
+ORDER BY updated_at DESC
+LIMIT $3 OFFSET $4`,
+		user.ID,
+		own,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch studysets: %w", err)
+	}
+	return studysets, nil
+}
@@ -8,6 +8,8 @@ package graph
 
 import (
 	"regexp"
+	"quizfreely/api/contentfilter"
+	"quizfreely/api/storage"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -15,5 +17,11 @@ import (
 var validTitleRegex = regexp.MustCompile(`[\p{L}\p{M}\p{N}]`)
 
 type Resolver struct {
-	DB *pgxpool.Pool
+	DB      *pgxpool.Pool
+	Storage storage.Storage
+	/* how many open reports hide a studyset until a moderator reviews it,
+	0 means DefaultReportAutoHideThreshold */
+	ReportAutoHideThreshold int
+	/* checks public studysets for spam & profanity, nil means no filtering */
+	ContentFilter *contentfilter.Filter
 }
@@ -0,0 +1,256 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"slices"
+	"sort"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// recordStudysetRevision snapshots a studyset and all of its terms
+// into studyset_revisions, it should be called inside the same transaction
+// as the change, right before commit, so the snapshot matches what got saved
+func recordStudysetRevision(ctx context.Context, tx pgx.Tx, studysetID string, userID *string) error {
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO studyset_revisions (studyset_id, user_id, title, private, terms)
+SELECT s.id, $2, s.title, s.private,
+	COALESCE((
+		SELECT jsonb_agg(jsonb_build_object(
+			'id', t.id,
+			'term', t.term,
+			'def', t.def,
+			'sortOrder', t.sort_order,
+			'termImageId', t.term_image_id,
+			'defImageId', t.def_image_id,
+			'termAlternates', t.term_alternates,
+			'defAlternates', t.def_alternates,
+			'hint', t.hint
+		) ORDER BY t.sort_order)
+		FROM terms t
+		WHERE t.studyset_id = s.id AND t.deleted_at IS NULL
+	), '[]'::jsonb)
+FROM public.studysets s
+WHERE s.id = $1`,
+		studysetID,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record studyset revision: %w", err)
+	}
+	return nil
+}
+
+// getOwnedStudysetRevision gets a revision by id,
+// only if the studyset it belongs to is owned by userID
+func getOwnedStudysetRevision(ctx context.Context, db pgxscan.Querier, revisionID string, userID *string) (*model.StudysetRevision, error) {
+	var revision model.StudysetRevision
+	err := pgxscan.Get(
+		ctx,
+		db,
+		&revision,
+		`SELECT r.id, r.studyset_id, r.user_id, r.title, r.private, r.terms,
+	to_char(r.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at
+FROM studyset_revisions r
+JOIN public.studysets s ON s.id = r.studyset_id
+WHERE r.id = $1 AND s.user_id = $2 AND s.deleted_at IS NULL`,
+		revisionID,
+		userID,
+	)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("revision not found")
+		}
+		return nil, fmt.Errorf("failed to fetch revision: %w", err)
+	}
+	return &revision, nil
+}
+
+func stringsEqual(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func int32sEqual(a *int32, b *int32) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// diffRevisionTerms compares terms by id,
+// terms only in `from` were removed, terms only in `to` were added,
+// terms in both with a different term/def (or images, alternates, or hint) were modified,
+// and terms in both with only a different sortOrder were moved
+func diffRevisionTerms(from []*model.StudysetRevisionTerm, to []*model.StudysetRevisionTerm) []*model.TermChange {
+	fromByID := make(map[string]*model.StudysetRevisionTerm, len(from))
+	for _, t := range from {
+		if t != nil && t.ID != nil {
+			fromByID[*t.ID] = t
+		}
+	}
+
+	changes := make([]*model.TermChange, 0)
+	seen := make(map[string]bool, len(to))
+	for _, t := range to {
+		if t == nil || t.ID == nil {
+			continue
+		}
+		seen[*t.ID] = true
+
+		old, existed := fromByID[*t.ID]
+		if !existed {
+			changeType := model.TermChangeTypeAdded
+			changes = append(changes, &model.TermChange{
+				TermID:       t.ID,
+				ChangeType:   &changeType,
+				NewTerm:      t.Term,
+				NewDef:       t.Def,
+				NewSortOrder: t.SortOrder,
+			})
+			continue
+		}
+
+		var changeType model.TermChangeType
+		if !stringsEqual(old.Term, t.Term) || !stringsEqual(old.Def, t.Def) ||
+			!stringsEqual(old.TermImageID, t.TermImageID) || !stringsEqual(old.DefImageID, t.DefImageID) ||
+			!slices.Equal(old.TermAlternates, t.TermAlternates) || !slices.Equal(old.DefAlternates, t.DefAlternates) ||
+			!stringsEqual(old.Hint, t.Hint) {
+			changeType = model.TermChangeTypeModified
+		} else if !int32sEqual(old.SortOrder, t.SortOrder) {
+			changeType = model.TermChangeTypeMoved
+		} else {
+			continue
+		}
+		changes = append(changes, &model.TermChange{
+			TermID:       t.ID,
+			ChangeType:   &changeType,
+			OldTerm:      old.Term,
+			NewTerm:      t.Term,
+			OldDef:       old.Def,
+			NewDef:       t.Def,
+			OldSortOrder: old.SortOrder,
+			NewSortOrder: t.SortOrder,
+		})
+	}
+
+	for _, t := range from {
+		if t == nil || t.ID == nil || seen[*t.ID] {
+			continue
+		}
+		changeType := model.TermChangeTypeRemoved
+		changes = append(changes, &model.TermChange{
+			TermID:       t.ID,
+			ChangeType:   &changeType,
+			OldTerm:      t.Term,
+			OldDef:       t.Def,
+			OldSortOrder: t.SortOrder,
+		})
+	}
+
+	/* removed terms don't have a new sortOrder,
+	so they're sorted by where they used to be */
+	sortKey := func(c *model.TermChange) int32 {
+		if c.NewSortOrder != nil {
+			return *c.NewSortOrder
+		}
+		if c.OldSortOrder != nil {
+			return *c.OldSortOrder
+		}
+		return 0
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return sortKey(changes[i]) < sortKey(changes[j])
+	})
+
+	return changes
+}
+
+// restoreStudysetRevisionTx makes a studyset's title, private, and terms
+// match a revision: terms that aren't in the revision get moved to the trash,
+// terms that are get updated and taken out of the trash,
+// or re-inserted with their old id if they were purged
+func restoreStudysetRevisionTx(ctx context.Context, tx pgx.Tx, revisionID string) error {
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE public.studysets s
+SET title = r.title,
+	/* studysets held by moderation stay private */
+	private = r.private OR s.moderation_hold,
+	updated_at = now(), version = s.version + 1
+FROM studyset_revisions r
+WHERE r.id = $1 AND s.id = r.studyset_id`,
+		revisionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore studyset: %w", err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE terms t
+SET deleted_at = now(), version = t.version + 1
+FROM studyset_revisions r
+WHERE r.id = $1
+	AND t.studyset_id = r.studyset_id
+	AND t.deleted_at IS NULL
+	AND NOT EXISTS (
+		SELECT 1 FROM jsonb_array_elements(r.terms) AS elem
+		WHERE (elem->>'id')::uuid = t.id
+	)`,
+		revisionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete terms while restoring: %w", err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO terms (
+	id, studyset_id, term, def, sort_order, term_image_id, def_image_id,
+	term_alternates, def_alternates, hint
+)
+SELECT rt.id, r.studyset_id, rt.term, rt.def, rt."sortOrder",
+	/* images might've been garbage collected since the revision */
+	(SELECT i.id FROM images i WHERE i.id = rt."termImageId"),
+	(SELECT i.id FROM images i WHERE i.id = rt."defImageId"),
+	/* revisions from before alternates don't have them */
+	COALESCE(rt."termAlternates", '{}'),
+	COALESCE(rt."defAlternates", '{}'),
+	rt.hint
+FROM studyset_revisions r
+CROSS JOIN LATERAL jsonb_to_recordset(r.terms)
+	AS rt(
+		id uuid, term text, def text, "sortOrder" int,
+		"termImageId" uuid, "defImageId" uuid,
+		"termAlternates" text[], "defAlternates" text[],
+		hint text
+	)
+WHERE r.id = $1
+ON CONFLICT (id) DO UPDATE SET
+	term = EXCLUDED.term,
+	def = EXCLUDED.def,
+	sort_order = EXCLUDED.sort_order,
+	term_image_id = EXCLUDED.term_image_id,
+	def_image_id = EXCLUDED.def_image_id,
+	term_alternates = EXCLUDED.term_alternates,
+	def_alternates = EXCLUDED.def_alternates,
+	hint = EXCLUDED.hint,
+	updated_at = now(),
+	version = terms.version + 1,
+	deleted_at = NULL
+WHERE terms.studyset_id = EXCLUDED.studyset_id`,
+		revisionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore terms: %w", err)
+	}
+
+	return nil
+}
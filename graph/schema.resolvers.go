@@ -13,71 +13,171 @@ import (
 	"quizfreely/api/graph/model"
 	"strings"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	pgx "github.com/jackc/pgx/v5"
 )
 
+// User is the resolver for the user field.
+func (r *commentResolver) User(ctx context.Context, obj *model.Comment) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	return loader.GetUser(ctx, *obj.UserID)
+}
+
+// Replies is the resolver for the replies field.
+func (r *commentResolver) Replies(ctx context.Context, obj *model.Comment, limit *int32, offset *int32) ([]*model.Comment, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	return loader.GetCommentReplies(ctx, loader.CommentsPage{ID: *obj.ID, Limit: l, Offset: o})
+}
+
+// User is the resolver for the user field.
+func (r *commentNotificationResolver) User(ctx context.Context, obj *model.CommentNotification) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	return loader.GetUser(ctx, *obj.UserID)
+}
+
+// Terms is the resolver for the terms field.
+func (r *duplicateTermGroupResolver) Terms(ctx context.Context, obj *model.DuplicateTermGroup) ([]*model.Term, error) {
+	return loader.GetTermsByIDs(ctx, obj.TermIDs)
+}
+
+// Term is the resolver for the term field.
+func (r *duplicateTermPairResolver) Term(ctx context.Context, obj *model.DuplicateTermPair) (*model.Term, error) {
+	if obj.TermID == nil {
+		return nil, nil
+	}
+
+	return loader.GetTermByID(ctx, *obj.TermID)
+}
+
+// Duplicate is the resolver for the duplicate field.
+func (r *duplicateTermPairResolver) Duplicate(ctx context.Context, obj *model.DuplicateTermPair) (*model.Term, error) {
+	if obj.DuplicateTermID == nil {
+		return nil, nil
+	}
+
+	return loader.GetTermByID(ctx, *obj.DuplicateTermID)
+}
+
+// User is the resolver for the user field.
+func (r *followNotificationResolver) User(ctx context.Context, obj *model.FollowNotification) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	return loader.GetUser(ctx, *obj.UserID)
+}
+
+// URL is the resolver for the url field.
+func (r *imageResolver) URL(ctx context.Context, obj *model.Image) (*string, error) {
+	if obj.StorageKey == nil {
+		return nil, nil
+	}
+
+	url := r.Storage.URL(*obj.StorageKey)
+	return &url, nil
+}
+
+// ThumbnailURL is the resolver for the thumbnailUrl field.
+func (r *imageResolver) ThumbnailURL(ctx context.Context, obj *model.Image) (*string, error) {
+	if obj.ThumbnailKey == nil {
+		return nil, nil
+	}
+
+	url := r.Storage.URL(*obj.ThumbnailKey)
+	return &url, nil
+}
+
+// Moderator is the resolver for the moderator field.
+func (r *moderationDecisionResolver) Moderator(ctx context.Context, obj *model.ModerationDecision) (*model.User, error) {
+	if obj.ModeratorID == nil {
+		return nil, nil
+	}
+
+	return loader.GetUser(ctx, *obj.ModeratorID)
+}
+
 // CreateStudyset is the resolver for the createStudyset field.
-func (r *mutationResolver) CreateStudyset(ctx context.Context, studyset model.StudysetInput, terms []*model.NewTermInput) (*model.Studyset, error) {
+func (r *mutationResolver) CreateStudyset(ctx context.Context, studyset model.StudysetInput, terms []*model.NewTermInput, checkDuplicates *bool) (*model.Studyset, error) {
 	authedUser := auth.AuthedUserContext(ctx)
 	if authedUser == nil {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
-	title := "Untitled Studyset"
-	if len(studyset.Title) > 0 && len(studyset.Title) < 200 && validTitleRegex.MatchString(studyset.Title) {
-		title = studyset.Title
-	}
+	return r.createStudyset(ctx, authedUser.ID, studyset, terms, checkDuplicates != nil && *checkDuplicates)
+}
 
-	tx, err := r.DB.Begin(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+// ImportStudyset is the resolver for the importStudyset field.
+func (r *mutationResolver) ImportStudyset(ctx context.Context, studyset model.StudysetInput, input model.ImportStudysetInput, dryRun *bool) (*model.ImportStudysetResult, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
 	}
-	defer tx.Rollback(ctx)
 
-	sql := `
-		INSERT INTO public.studysets (user_id, title, private)
-		VALUES ($1, $2, $3)
-		RETURNING id, user_id, title, private,
-			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
-	`
-	var newStudyset model.Studyset
-	err = pgxscan.Get(ctx, tx, &newStudyset, sql, authedUser.ID, title, studyset.Private)
+	terms, warnings, err := parseImport(input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create studyset: %w", err)
+		return nil, err
 	}
 
-	if terms != nil && len(terms) > 0 {
-		values := make([]interface{}, 0, len(terms)*4)
-		placeholders := make([]string, 0, len(terms))
-
-		for i, t := range terms {
-			placeholders = append(placeholders, fmt.Sprintf("($%d,$%d,$%d,$%d)", i*4+1, i*4+2, i*4+3, i*4+4))
-			values = append(values, newStudyset.ID, t.Term, t.Def, t.SortOrder)
-		}
+	result := &model.ImportStudysetResult{
+		Terms:    terms,
+		Warnings: warnings,
+	}
+	if dryRun != nil && *dryRun {
+		return result, nil
+	}
 
-		sql := fmt.Sprintf("INSERT INTO terms (studyset_id, term, def, sort_order) VALUES %s", strings.Join(placeholders, ","))
-		_, err := tx.Exec(ctx, sql, values...)
-		if err != nil {
-			return nil, fmt.Errorf("failed to insert terms: %w", err)
-		}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("no terms to import")
+	}
+	result.Studyset, err = r.createStudyset(ctx, authedUser.ID, studyset, terms, false)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+// ImportAnkiPackage is the resolver for the importAnkiPackage field.
+func (r *mutationResolver) ImportAnkiPackage(ctx context.Context, file graphql.Upload, options *model.AnkiImportOptions, dryRun *bool) (*model.AnkiImportResult, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
 	}
 
-	return &newStudyset, nil
+	var opts model.AnkiImportOptions
+	if options != nil {
+		opts = *options
+	}
+	return r.importAnkiPackage(ctx, authedUser.ID, file, opts, dryRun != nil && *dryRun)
 }
 
 // UpdateStudyset is the resolver for the updateStudyset field.
-func (r *mutationResolver) UpdateStudyset(ctx context.Context, id string, studyset *model.StudysetInput, terms []*model.TermInput, newTerms []*model.NewTermInput, deleteTerms []*string) (*model.Studyset, error) {
+func (r *mutationResolver) UpdateStudyset(ctx context.Context, id string, studyset *model.StudysetInput, terms []*model.TermInput, newTerms []*model.NewTermInput, deleteTerms []*string, expectedVersion *int32, checkDuplicates *bool) (*model.Studyset, error) {
 	authedUser := auth.AuthedUserContext(ctx)
 	if authedUser == nil {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
-	if studyset == nil && (terms == nil || len(terms) == 0) {
+	if studyset == nil && len(terms) == 0 && len(newTerms) == 0 && len(deleteTerms) == 0 {
 		return r.Query().Studyset(ctx, id)
 	}
 
@@ -87,6 +187,17 @@ func (r *mutationResolver) UpdateStudyset(ctx context.Context, id string, studys
 	}
 	defer tx.Rollback(ctx)
 
+	if err := checkStudysetVersions(ctx, tx, id, authedUser.ID, expectedVersion, terms); err != nil {
+		return nil, err
+	}
+
+	/* making a studyset public publishes it to its owner's followers' feeds */
+	var wasPrivate bool
+	err = tx.QueryRow(ctx, "SELECT private FROM public.studysets WHERE id = $1", id).Scan(&wasPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch studyset: %w", err)
+	}
+
 	var updatedStudyset model.Studyset
 	if studyset != nil {
 		title := "Untitled Studyset"
@@ -94,26 +205,48 @@ func (r *mutationResolver) UpdateStudyset(ctx context.Context, id string, studys
 			title = studyset.Title
 		}
 
+		langs, err := parseStudysetLanguages(*studyset)
+		if err != nil {
+			return nil, err
+		}
+
+		/* languages that aren't in the input stay the same */
 		sql := `
 			UPDATE public.studysets
-			SET title = $1, private = $2, updated_at = now()
-			WHERE id = $3 AND user_id = $4
-			RETURNING id, user_id, title, private,
+			SET title = $1, private = $2 OR moderation_hold, updated_at = now(), version = version + 1,
+				term_language = NULLIF(COALESCE($5, term_language), ''),
+				def_language = NULLIF(COALESCE($6, def_language), ''),
+				term_search_config = COALESCE($7::regconfig, term_search_config),
+				def_search_config = COALESCE($8::regconfig, def_search_config)
+			WHERE id = $3 AND user_id = $4 AND deleted_at IS NULL
+			RETURNING id, user_id, title, private, term_language, def_language, version,
 				to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
 		`
-		err = pgxscan.Get(ctx, tx, &updatedStudyset, sql, title, studyset.Private, id, authedUser.ID)
+		err = pgxscan.Get(
+			ctx, tx, &updatedStudyset, sql,
+			title, studyset.Private, id, authedUser.ID,
+			langs.termLanguage, langs.defLanguage, langs.termSearchConfig, langs.defSearchConfig,
+		)
 		if err != nil {
 			if pgxscan.NotFound(err) {
 				return nil, fmt.Errorf("studyset not found")
 			}
 			return nil, fmt.Errorf("failed to update studyset: %w", err)
 		}
+
+		if err := syncTermSearchConfigs(ctx, tx, id); err != nil {
+			return nil, err
+		}
+
+		if err := removeHiddenStars(ctx, tx, id); err != nil {
+			return nil, err
+		}
 	} else {
 		sql := `
 			UPDATE public.studysets
-			SET updated_at = now()
-			WHERE id = $1 AND user_id = $2
-			RETURNING id, user_id, title, private,
+			SET updated_at = now(), version = version + 1
+			WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+			RETURNING id, user_id, title, private, term_language, def_language, version,
 				to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
 		`
 		err = pgxscan.Get(ctx, tx, &updatedStudyset, sql, id, authedUser.ID)
@@ -125,66 +258,75 @@ func (r *mutationResolver) UpdateStudyset(ctx context.Context, id string, studys
 		}
 	}
 
-	if terms != nil && len(terms) > 0 {
-		values := make([]interface{}, 0, len(terms)*4)
-		placeholders := make([]string, 0, len(terms))
-
-		for i, t := range terms {
-			placeholders = append(placeholders, fmt.Sprintf(
-				"($%d::uuid, $%d::text, $%d::text, $%d::int)",
-				i*4+1, i*4+2, i*4+3, i*4+4,
-			))
-			values = append(values, t.ID, t.Term, t.Def, t.SortOrder)
-		}
+	forcedPrivate, err := r.applyTermChanges(ctx, tx, authedUser.ID, id, wasPrivate, terms, newTerms, deleteTerms)
+	if err != nil {
+		return nil, err
+	}
+	if forcedPrivate {
+		updatedStudyset.Private = &forcedPrivate
+	}
 
-		sql := fmt.Sprintf(
-			`UPDATE terms AS t
-			SET term = v.term, def = v.def, sort_order = v.sort_order, updated_at = now()
-			FROM (VALUES
-				%s
-			) AS v(id, term, def, sort_order)
-			WHERE t.id = v.id`,
-			strings.Join(placeholders, ","),
-		)
-		_, err := tx.Exec(ctx, sql, values...)
+	if checkDuplicates != nil && *checkDuplicates {
+		updatedStudyset.DuplicateWarnings, err = findDuplicateTerms(ctx, tx, id, DefaultDuplicateThreshold)
 		if err != nil {
-			return nil, fmt.Errorf("failed to update terms: %w", err)
+			return nil, err
 		}
 	}
 
-	if newTerms != nil && len(newTerms) > 0 {
-		values := make([]interface{}, 0, len(newTerms)*4)
-		placeholders := make([]string, 0, len(newTerms))
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-		for i, t := range newTerms {
-			placeholders = append(placeholders, fmt.Sprintf("($%d,$%d,$%d,$%d)", i*4+1, i*4+2, i*4+3, i*4+4))
-			values = append(values, id, t.Term, t.Def, t.SortOrder)
-		}
+	return &updatedStudyset, nil
+}
 
-		sql := fmt.Sprintf("INSERT INTO terms (studyset_id, term, def, sort_order) VALUES %s", strings.Join(placeholders, ","))
-		_, err := tx.Exec(ctx, sql, values...)
-		if err != nil {
-			return nil, fmt.Errorf("failed to insert new terms: %w", err)
-		}
+// MoveTerms is the resolver for the moveTerms field.
+func (r *mutationResolver) MoveTerms(ctx context.Context, studysetID string, termIds []string, afterTermID *string) (*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
 	}
 
-	if deleteTerms != nil && len(deleteTerms) > 0 {
-		_, err := tx.Exec(
-			ctx,
-			"DELETE FROM terms WHERE id = ANY($1) AND studyset_id = $2",
-			deleteTerms,
-			id,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to delete terms: %w", err)
-		}
+	return r.moveTerms(ctx, authedUser.ID, studysetID, termIds, afterTermID)
+}
+
+// MoveTermsToStudyset is the resolver for the moveTermsToStudyset field.
+func (r *mutationResolver) MoveTermsToStudyset(ctx context.Context, termIds []string, targetStudysetID string) (*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	return r.moveTermsToStudyset(ctx, authedUser.ID, termIds, targetStudysetID)
+}
+
+// MergeStudysets is the resolver for the mergeStudysets field.
+func (r *mutationResolver) MergeStudysets(ctx context.Context, sourceIds []string, title string, private *bool, dedupe *bool, carryOverProgress *bool) (*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
 	}
 
-	return &updatedStudyset, nil
+	/* merged studysets are usually for studying on your own, so they're private unless asked otherwise */
+	return r.mergeStudysets(
+		ctx,
+		authedUser.ID,
+		sourceIds,
+		title,
+		private == nil || *private,
+		dedupe != nil && *dedupe,
+		carryOverProgress != nil && *carryOverProgress,
+	)
+}
+
+// SplitStudyset is the resolver for the splitStudyset field.
+func (r *mutationResolver) SplitStudyset(ctx context.Context, id string, strategy model.SplitStrategyInput, keepOriginal *bool) ([]*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.splitStudyset(ctx, authedUser.ID, id, strategy, keepOriginal == nil || *keepOriginal)
 }
 
 // DeleteStudyset is the resolver for the deleteStudyset field.
@@ -194,8 +336,10 @@ func (r *mutationResolver) DeleteStudyset(ctx context.Context, id string) (*stri
 		return nil, fmt.Errorf("not authenticated")
 	}
 
+	/* deleted studysets go to the trash, they're only really deleted
+	by PurgeStudyset or after the trash retention period, see PurgeExpiredTrash */
 	var deletedID string
-	err := r.DB.QueryRow(ctx, "DELETE FROM public.studysets WHERE id = $1 AND user_id = $2 RETURNING id", id, authedUser.ID).Scan(&deletedID)
+	err := r.DB.QueryRow(ctx, "UPDATE public.studysets SET deleted_at = now() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL RETURNING id", id, authedUser.ID).Scan(&deletedID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("studyset not found")
@@ -206,6 +350,69 @@ func (r *mutationResolver) DeleteStudyset(ctx context.Context, id string) (*stri
 	return &deletedID, nil
 }
 
+// StarStudyset is the resolver for the starStudyset field.
+func (r *mutationResolver) StarStudyset(ctx context.Context, id string) (*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	/* only other people's public studysets can be starred,
+	starring again does nothing */
+	var studyset model.Studyset
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&studyset,
+		`WITH starred AS (
+	INSERT INTO studyset_stars (studyset_id, user_id)
+	SELECT s.id, $2
+	FROM public.studysets s
+	WHERE s.id = $1
+		AND s.private = false
+		AND s.deleted_at IS NULL
+		AND s.user_id <> $2
+	ON CONFLICT (studyset_id, user_id) DO NOTHING
+)
+SELECT id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM public.studysets
+WHERE id = $1
+	AND private = false
+	AND deleted_at IS NULL
+	AND user_id <> $2`,
+		id,
+		authedUser.ID,
+	)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("studyset not found")
+		}
+		return nil, fmt.Errorf("failed to star studyset: %w", err)
+	}
+
+	return &studyset, nil
+}
+
+// UnstarStudyset is the resolver for the unstarStudyset field.
+func (r *mutationResolver) UnstarStudyset(ctx context.Context, id string) (*string, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var unstarredID string
+	err := r.DB.QueryRow(ctx, "DELETE FROM studyset_stars WHERE studyset_id = $1 AND user_id = $2 RETURNING studyset_id", id, authedUser.ID).Scan(&unstarredID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not starred")
+		}
+		return nil, fmt.Errorf("failed to unstar studyset: %w", err)
+	}
+
+	return &unstarredID, nil
+}
+
 // UpdateUser is the resolver for the updateUser field.
 func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string) (*model.AuthedUser, error) {
 	authedUser := auth.AuthedUserContext(ctx)
@@ -214,12 +421,8 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string)
 	}
 
 	if displayName != nil {
-		trimmedDisplayName := strings.TrimSpace(*displayName)
-		if len(trimmedDisplayName) < 1 || len(trimmedDisplayName) > 32 {
-			return nil, fmt.Errorf("display name must be between 1 and 32 characters")
-		}
-		if *displayName != trimmedDisplayName {
-			return nil, fmt.Errorf("display name must not have leading or trailing whitespace")
+		if err := validateDisplayName(*displayName); err != nil {
+			return nil, err
 		}
 	} else {
 		return authedUser, nil
@@ -250,83 +453,210 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, displayName *string)
 	return &updatedUser, nil
 }
 
-// UpdateTermProgress is the resolver for the updateTermProgress field.
-func (r *mutationResolver) UpdateTermProgress(ctx context.Context, termID string, progress model.TermProgressInput) (*model.TermProgress, error) {
+// UpdateProfile is the resolver for the updateProfile field.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, profile model.ProfileInput) (*model.User, error) {
 	authedUser := auth.AuthedUserContext(ctx)
 	if authedUser == nil {
 		return nil, fmt.Errorf("not authenticated")
 	}
 
-	var termProgress model.TermProgress
-	err := pgxscan.Get(
-		ctx,
-		r.DB,
-		&termProgress,
-		`INSERT INTO term_progress (
-    term_id, user_id,
-    term_first_reviewed_at, term_last_reviewed_at,
-	term_review_count,
-    def_first_reviewed_at, def_last_reviewed_at,
-	def_review_count,
-    term_leitner_system_box, def_leitner_system_box,
-	term_correct_count, term_incorrect_count,
-	def_correct_count, def_incorrect_count
-) VALUES (
-    $1, $2,
-	$3::timestamptz, $3::timestamptz,
-    CASE WHEN $3 IS NOT NULL THEN 1 ELSE 0 END,
-	$4::timestamptz, $4::timestamptz,
-    CASE WHEN $4 IS NOT NULL THEN 1 ELSE 0 END,
-    $5, $6,
-	COALESCE($7, 0), COALESCE($8, 0),
-	COALESCE($9, 0), COALESCE($10, 0)
-) ON CONFLICT (term_id, user_id) DO UPDATE SET
-	term_last_reviewed_at = COALESCE(
-		$3::timestamptz, term_progress.term_last_reviewed_at
-	),
-	def_last_reviewed_at = COALESCE(
-		$4::timestamptz, term_progress.def_last_reviewed_at
-	),
-    term_leitner_system_box = $5,
-    def_leitner_system_box = $6,
-    term_review_count = term_progress.term_review_count + 
-        (CASE WHEN $3 IS NOT NULL THEN 1 ELSE 0 END),
-    def_review_count = term_progress.def_review_count + 
-        (CASE WHEN $4 IS NOT NULL THEN 1 ELSE 0 END),
-	term_correct_count = term_progress.term_correct_count +
-		COALESCE($7, 0),
-	term_incorrect_count = term_progress.term_incorrect_count +
-		COALESCE($8, 0),
-	def_correct_count = term_progress.def_correct_count +
-		COALESCE($9, 0),
-	def_incorrect_count = term_progress.def_incorrect_count +
-		COALESCE($10, 0)
-RETURNING id,
-	to_char(term_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_first_reviewed_at,
-	to_char(term_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_last_reviewed_at,
-	term_review_count,
-	to_char(def_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as def_first_reviewed_at,
-	to_char(def_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as def_last_reviewed_at,
-	def_review_count,
-	term_leitner_system_box, def_leitner_system_box,
-	term_correct_count, term_incorrect_count,
-	def_correct_count, def_incorrect_count`,
-		termID,
-		authedUser.ID,
-		progress.TermReviewedAt,
-		progress.DefReviewedAt,
-		progress.TermLeitnerSystemBox,
-		progress.DefLeitnerSystemBox,
-		progress.TermCorrectIncrease,
-		progress.TermIncorrectIncrease,
-		progress.DefCorrectIncrease,
-		progress.DefIncorrectIncrease,
+	return r.updateProfile(ctx, authedUser.ID, profile)
+}
+
+// FollowUser is the resolver for the followUser field.
+func (r *mutationResolver) FollowUser(ctx context.Context, id string) (*model.User, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.followUser(ctx, authedUser.ID, id)
+}
+
+// UnfollowUser is the resolver for the unfollowUser field.
+func (r *mutationResolver) UnfollowUser(ctx context.Context, id string) (*string, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var unfollowedID string
+	err := r.DB.QueryRow(ctx, "DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2 RETURNING followee_id", authedUser.ID, id).Scan(&unfollowedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not followed")
+		}
+		return nil, fmt.Errorf("failed to unfollow user: %w", err)
+	}
+
+	return &unfollowedID, nil
+}
+
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string, all *bool) (*int32, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	unreadCount, err := r.markNotificationsRead(ctx, authedUser.ID, ids, all != nil && *all)
+	if err != nil {
+		return nil, err
+	}
+	return &unreadCount, nil
+}
+
+// SetNotificationMuted is the resolver for the setNotificationMuted field.
+func (r *mutationResolver) SetNotificationMuted(ctx context.Context, kind model.NotificationKind, muted bool) (*model.NotificationPreference, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.setNotificationMuted(ctx, authedUser.ID, kind, muted)
+}
+
+// CreateComment is the resolver for the createComment field.
+func (r *mutationResolver) CreateComment(ctx context.Context, studysetID string, termID *string, parentID *string, body string) (*model.Comment, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.createComment(ctx, authedUser.ID, studysetID, termID, parentID, body)
+}
+
+// UpdateComment is the resolver for the updateComment field.
+func (r *mutationResolver) UpdateComment(ctx context.Context, id string, body string) (*model.Comment, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.updateComment(ctx, authedUser.ID, id, body)
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (*string, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	/* moderators can delete anyone's comments */
+	moderator := authedUser.Moderator != nil && *authedUser.Moderator
+	return r.deleteComment(ctx, authedUser.ID, moderator, id)
+}
+
+// SuggestEdits is the resolver for the suggestEdits field.
+func (r *mutationResolver) SuggestEdits(ctx context.Context, studysetID string, suggestion model.SuggestionInput) (*model.StudysetSuggestion, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.suggestEdits(ctx, authedUser.ID, studysetID, suggestion)
+}
+
+// AcceptSuggestion is the resolver for the acceptSuggestion field.
+func (r *mutationResolver) AcceptSuggestion(ctx context.Context, id string) (*model.StudysetSuggestion, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.acceptSuggestion(ctx, authedUser.ID, id)
+}
+
+// RejectSuggestion is the resolver for the rejectSuggestion field.
+func (r *mutationResolver) RejectSuggestion(ctx context.Context, id string) (*model.StudysetSuggestion, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.rejectSuggestion(ctx, authedUser.ID, id)
+}
+
+// UpdateTermProgress is the resolver for the updateTermProgress field.
+func (r *mutationResolver) UpdateTermProgress(ctx context.Context, termID string, progress model.TermProgressInput) (*model.TermProgress, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var termProgress model.TermProgress
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&termProgress,
+		`INSERT INTO term_progress (
+    term_id, user_id,
+    term_first_reviewed_at, term_last_reviewed_at,
+	term_review_count,
+    def_first_reviewed_at, def_last_reviewed_at,
+	def_review_count,
+    term_leitner_system_box, def_leitner_system_box,
+	term_correct_count, term_incorrect_count,
+	def_correct_count, def_incorrect_count
+) VALUES (
+    $1, $2,
+	$3::timestamptz, $3::timestamptz,
+    CASE WHEN $3 IS NOT NULL THEN 1 ELSE 0 END,
+	$4::timestamptz, $4::timestamptz,
+    CASE WHEN $4 IS NOT NULL THEN 1 ELSE 0 END,
+    $5, $6,
+	COALESCE($7, 0), COALESCE($8, 0),
+	COALESCE($9, 0), COALESCE($10, 0)
+) ON CONFLICT (term_id, user_id) DO UPDATE SET
+	term_last_reviewed_at = COALESCE(
+		$3::timestamptz, term_progress.term_last_reviewed_at
+	),
+	def_last_reviewed_at = COALESCE(
+		$4::timestamptz, term_progress.def_last_reviewed_at
+	),
+    term_leitner_system_box = $5,
+    def_leitner_system_box = $6,
+    term_review_count = term_progress.term_review_count + 
+        (CASE WHEN $3 IS NOT NULL THEN 1 ELSE 0 END),
+    def_review_count = term_progress.def_review_count + 
+        (CASE WHEN $4 IS NOT NULL THEN 1 ELSE 0 END),
+	term_correct_count = term_progress.term_correct_count +
+		COALESCE($7, 0),
+	term_incorrect_count = term_progress.term_incorrect_count +
+		COALESCE($8, 0),
+	def_correct_count = term_progress.def_correct_count +
+		COALESCE($9, 0),
+	def_incorrect_count = term_progress.def_incorrect_count +
+		COALESCE($10, 0)
+RETURNING id,
+	to_char(term_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_first_reviewed_at,
+	to_char(term_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as term_last_reviewed_at,
+	term_review_count,
+	to_char(def_first_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as def_first_reviewed_at,
+	to_char(def_last_reviewed_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as def_last_reviewed_at,
+	def_review_count,
+	term_leitner_system_box, def_leitner_system_box,
+	term_correct_count, term_incorrect_count,
+	def_correct_count, def_incorrect_count`,
+		termID,
+		authedUser.ID,
+		progress.TermReviewedAt,
+		progress.DefReviewedAt,
+		progress.TermLeitnerSystemBox,
+		progress.DefLeitnerSystemBox,
+		progress.TermCorrectIncrease,
+		progress.TermIncorrectIncrease,
+		progress.DefCorrectIncrease,
+		progress.DefIncorrectIncrease,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to update term progress: %w", err)
 	}
 
+	logStudysetEvent(recordStudysetStudy(ctx, r.DB, authedUser.ID, nil, &termID))
+
 	return &termProgress, nil
 }
 
@@ -394,6 +724,21 @@ func (r *mutationResolver) RecordPracticeTest(ctx context.Context, input *model.
 		return nil, fmt.Errorf("not authenticated")
 	}
 
+	if input.Questions != nil {
+		questionsCorrect, err := gradePracticeTest(ctx, r.DB, input.Questions)
+		if err != nil {
+			return nil, err
+		}
+		/* the client's own score is kept, the graded one is only for clients that don't send it */
+		if input.QuestionsCorrect == nil {
+			input.QuestionsCorrect = &questionsCorrect
+		}
+		if input.QuestionsTotal == nil {
+			questionsTotal := int32(len(input.Questions))
+			input.QuestionsTotal = &questionsTotal
+		}
+	}
+
 	var practiceTest model.PracticeTest
 	err := pgxscan.Get(
 		ctx,
@@ -418,74 +763,1103 @@ RETURNING
 		return nil, fmt.Errorf("database error in RecordPracticeTest: %w", err)
 	}
 
+	if input.StudysetID != nil {
+		logStudysetEvent(recordStudysetStudy(ctx, r.DB, authedUser.ID, input.StudysetID, nil))
+	}
+
 	return &practiceTest, nil
 }
 
-// Authed is the resolver for the authed field.
-func (r *queryResolver) Authed(ctx context.Context) (*bool, error) {
-	authed := auth.AuthedUserContext(ctx) != nil
-	return &authed, nil
+// RecordStudysetView is the resolver for the recordStudysetView field.
+func (r *mutationResolver) RecordStudysetView(ctx context.Context, studysetID string) (*bool, error) {
+	var userID *string
+	if authedUser := auth.AuthedUserContext(ctx); authedUser != nil {
+		userID = authedUser.ID
+	}
+
+	if err := recordStudysetView(ctx, r.DB, studysetID, userID); err != nil {
+		return nil, err
+	}
+
+	recorded := true
+	return &recorded, nil
 }
 
-// AuthedUser is the resolver for the authedUser field.
-func (r *queryResolver) AuthedUser(ctx context.Context) (*model.AuthedUser, error) {
-	return auth.AuthedUserContext(ctx), nil
+// ReportStudyset is the resolver for the reportStudyset field.
+func (r *mutationResolver) ReportStudyset(ctx context.Context, id string, reason model.ReportReason, details *string) (*model.StudysetReport, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.reportStudyset(ctx, authedUser.ID, id, reason, details)
 }
 
-// Studyset is the resolver for the studyset field.
-func (r *queryResolver) Studyset(ctx context.Context, id string) (*model.Studyset, error) {
+// ModerateStudyset is the resolver for the moderateStudyset field.
+func (r *mutationResolver) ModerateStudyset(ctx context.Context, id string, action model.ModerationAction, note *string) (*model.ModerationDecision, error) {
 	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if authedUser.Moderator == nil || !*authedUser.Moderator {
+		return nil, fmt.Errorf("not a moderator")
+	}
 
-	var studyset model.Studyset
-	var err error
-	if authedUser != nil {
-		sql := `
-			SELECT id, user_id, title, private,
-				to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
-			FROM public.studysets
-			WHERE id = $1 AND (private = false OR (private = true AND user_id = $2))`
-		err = pgxscan.Get(ctx, r.DB, &studyset, sql, id, authedUser.ID)
-	} else {
-		sql := `
-			SELECT id, user_id, title, private,
-				to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
-			FROM public.studysets
-			WHERE id = $1 AND private = false`
-		err = pgxscan.Get(ctx, r.DB, &studyset, sql, id)
+	return r.moderateStudyset(ctx, authedUser.ID, id, action, note)
+}
+
+// RestoreStudysetRevision is the resolver for the restoreStudysetRevision field.
+func (r *mutationResolver) RestoreStudysetRevision(ctx context.Context, revisionID string) (*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	revision, err := getOwnedStudysetRevision(ctx, tx, revisionID, authedUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := restoreStudysetRevisionTx(ctx, tx, revisionID); err != nil {
+		return nil, err
+	}
+	if err := rerenderStudysetTerms(ctx, tx, *revision.StudysetID); err != nil {
+		return nil, err
+	}
+	if err := renumberTerms(ctx, tx, *revision.StudysetID); err != nil {
+		return nil, err
+	}
+	if err := removeHiddenStars(ctx, tx, *revision.StudysetID); err != nil {
+		return nil, err
+	}
+	if _, err := r.filterStudysetContent(ctx, tx, *revision.StudysetID); err != nil {
+		return nil, err
+	}
+
+	/* terms that were purged get re-inserted with the default search configs */
+	if err := syncTermSearchConfigs(ctx, tx, *revision.StudysetID); err != nil {
+		return nil, err
+	}
+
+	/* restoring is recorded as its own new revision,
+	so restoring can be undone by restoring the revision before it */
+	if err := recordStudysetRevision(ctx, tx, *revision.StudysetID, authedUser.ID); err != nil {
+		return nil, err
+	}
+
+	var restoredStudyset model.Studyset
+	err = pgxscan.Get(
+		ctx,
+		tx,
+		&restoredStudyset,
+		`SELECT id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM public.studysets
+WHERE id = $1`,
+		revision.StudysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch restored studyset: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &restoredStudyset, nil
+}
+
+// RestoreStudyset is the resolver for the restoreStudyset field.
+func (r *mutationResolver) RestoreStudyset(ctx context.Context, id string) (*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var restoredID string
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE public.studysets
+SET deleted_at = NULL
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+RETURNING id`,
+		id,
+		authedUser.ID,
+	).Scan(&restoredID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not found in trash")
+		}
+		return nil, fmt.Errorf("failed to restore studyset: %w", err)
+	}
+
+	restoredStudyset, err := touchStudyset(ctx, tx, restoredID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordStudysetRevision(ctx, tx, restoredID, authedUser.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return restoredStudyset, nil
+}
+
+// PurgeStudyset is the resolver for the purgeStudyset field.
+func (r *mutationResolver) PurgeStudyset(ctx context.Context, id string) (*string, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	/* only studysets already in the trash can be purged */
+	var purgedID string
+	err := r.DB.QueryRow(ctx, "DELETE FROM public.studysets WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL RETURNING id", id, authedUser.ID).Scan(&purgedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not found in trash")
+		}
+		return nil, fmt.Errorf("failed to purge studyset: %w", err)
+	}
+
+	return &purgedID, nil
+}
+
+// RestoreTerms is the resolver for the restoreTerms field.
+func (r *mutationResolver) RestoreTerms(ctx context.Context, ids []string) ([]*model.Term, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var restored []struct {
+		ID         string
+		StudysetID string
+	}
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&restored,
+		`UPDATE terms t
+SET deleted_at = NULL, updated_at = now(), version = t.version + 1
+FROM public.studysets s
+WHERE t.id = ANY($1)
+	AND t.deleted_at IS NOT NULL
+	AND s.id = t.studyset_id
+	AND s.user_id = $2
+	AND s.deleted_at IS NULL
+RETURNING t.id, t.studyset_id`,
+		ids,
+		authedUser.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore terms: %w", err)
+	}
+
+	/* restored terms go back to their old sort_order,
+	so the terms around them get renumbered to make room */
+	restoredIDs := make([]string, 0, len(restored))
+	renumbered := make(map[string]bool)
+	for _, t := range restored {
+		restoredIDs = append(restoredIDs, t.ID)
+		if renumbered[t.StudysetID] {
+			continue
+		}
+		renumbered[t.StudysetID] = true
+		if err := renumberTerms(ctx, tx, t.StudysetID); err != nil {
+			return nil, err
+		}
+		if _, err := touchStudyset(ctx, tx, t.StudysetID); err != nil {
+			return nil, err
+		}
+		if err := recordStudysetRevision(ctx, tx, t.StudysetID, authedUser.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var restoredTerms []*model.Term
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&restoredTerms,
+		`SELECT t.id, t.studyset_id, t.term, t.def, t.sort_order,
+	t.term_image_id, t.def_image_id,
+	t.term_html, t.def_html, t.term_plain, t.def_plain,
+	t.term_alternates, t.def_alternates, t.hint, t.version,
+	to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(t.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM terms t
+WHERE t.id = ANY($1)`,
+		restoredIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch restored terms: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return restoredTerms, nil
+}
+
+// PurgeTerms is the resolver for the purgeTerms field.
+func (r *mutationResolver) PurgeTerms(ctx context.Context, ids []string) ([]*string, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var purgedIDs []*string
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&purgedIDs,
+		`DELETE FROM terms t
+USING public.studysets s
+WHERE t.id = ANY($1)
+	AND t.deleted_at IS NOT NULL
+	AND s.id = t.studyset_id
+	AND s.user_id = $2
+RETURNING t.id`,
+		ids,
+		authedUser.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge terms: %w", err)
+	}
+
+	return purgedIDs, nil
+}
+
+// UpsertTermNote is the resolver for the upsertTermNote field.
+func (r *mutationResolver) UpsertTermNote(ctx context.Context, termID string, note string) (*model.TermNote, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	trimmedNote := strings.TrimSpace(note)
+	if len(trimmedNote) < 1 || len(trimmedNote) > 2000 {
+		return nil, fmt.Errorf("note must be between 1 and 2000 characters")
+	}
+
+	/* notes are private, but can be added to any term the user can see,
+	including terms in other people's public studysets */
+	var termNote model.TermNote
+	err := pgxscan.Get(
+		ctx,
+		r.DB,
+		&termNote,
+		`INSERT INTO term_notes (term_id, user_id, note)
+SELECT t.id, $2, $3
+FROM terms t
+JOIN public.studysets s ON s.id = t.studyset_id
+WHERE t.id = $1
+	AND t.deleted_at IS NULL
+	AND s.deleted_at IS NULL
+	AND (s.private = false OR s.user_id = $2)
+ON CONFLICT (term_id, user_id) DO UPDATE SET
+	note = EXCLUDED.note,
+	updated_at = now()
+RETURNING id, term_id, note,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at`,
+		termID,
+		authedUser.ID,
+		trimmedNote,
+	)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("term not found")
+		}
+		return nil, fmt.Errorf("failed to save term note: %w", err)
+	}
+
+	return &termNote, nil
+}
+
+// DeleteTermNote is the resolver for the deleteTermNote field.
+func (r *mutationResolver) DeleteTermNote(ctx context.Context, termID string) (*string, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	var deletedID string
+	err := r.DB.QueryRow(ctx, "DELETE FROM term_notes WHERE term_id = $1 AND user_id = $2 RETURNING id", termID, authedUser.ID).Scan(&deletedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("term note not found")
+		}
+		return nil, fmt.Errorf("failed to delete term note: %w", err)
+	}
+
+	return &deletedID, nil
+}
+
+// UpsertStudysetSettings is the resolver for the upsertStudysetSettings field.
+func (r *mutationResolver) UpsertStudysetSettings(ctx context.Context, studysetID string, settings model.StudysetSettingsInput) (*model.StudysetSettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	/* settings can be saved for any studyset the user can see,
+	including other people's public studysets */
+	var visible bool
+	err = tx.QueryRow(
+		ctx,
+		`SELECT true FROM public.studysets
+WHERE id = $1 AND deleted_at IS NULL AND (private = false OR user_id = $2)`,
+		studysetID,
+		authedUser.ID,
+	).Scan(&visible)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not found")
+		}
+		return nil, fmt.Errorf("failed to get studyset: %w", err)
+	}
+
+	saved, err := lockSavedStudysetSettings(ctx, tx, studysetID, authedUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := mergeStudysetSettings(saved, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedAt string
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO studyset_settings (studyset_id, user_id, settings)
+VALUES ($1, $2, $3)
+ON CONFLICT (studyset_id, user_id) DO UPDATE SET
+	settings = EXCLUDED.settings,
+	updated_at = now()
+RETURNING to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM')`,
+		studysetID,
+		authedUser.ID,
+		merged,
+	).Scan(&updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save studyset settings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	result := withStudysetSettingsDefaults(merged)
+	result.UpdatedAt = &updatedAt
+	return result, nil
+}
+
+// Authed is the resolver for the authed field.
+func (r *queryResolver) Authed(ctx context.Context) (*bool, error) {
+	authed := auth.AuthedUserContext(ctx) != nil
+	return &authed, nil
+}
+
+// AuthedUser is the resolver for the authedUser field.
+func (r *queryResolver) AuthedUser(ctx context.Context) (*model.AuthedUser, error) {
+	return auth.AuthedUserContext(ctx), nil
+}
+
+// Studyset is the resolver for the studyset field.
+func (r *queryResolver) Studyset(ctx context.Context, id string) (*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+
+	var studyset model.Studyset
+	var err error
+	if authedUser != nil {
+		sql := `
+			SELECT id, user_id, title, private, term_language, def_language, version,
+				to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+			FROM public.studysets
+			WHERE id = $1 AND (private = false OR (private = true AND user_id = $2))
+				AND deleted_at IS NULL`
+		err = pgxscan.Get(ctx, r.DB, &studyset, sql, id, authedUser.ID)
+	} else {
+		sql := `
+			SELECT id, user_id, title, private, term_language, def_language, version,
+				to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+			FROM public.studysets
+			WHERE id = $1 AND private = false AND deleted_at IS NULL`
+		err = pgxscan.Get(ctx, r.DB, &studyset, sql, id)
+	}
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("studyset not found")
+		}
+		return nil, fmt.Errorf("failed to fetch studyset: %w", err)
+	}
+
+	return &studyset, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error) {
+	return r.getProfile(ctx, "id", id)
+}
+
+// UserByUsername is the resolver for the userByUsername field.
+func (r *queryResolver) UserByUsername(ctx context.Context, username string) (*model.User, error) {
+	/* usernames are always lowercase */
+	return r.getProfile(ctx, "username", strings.ToLower(strings.TrimSpace(username)))
+}
+
+// MyFeed is the resolver for the myFeed field.
+func (r *queryResolver) MyFeed(ctx context.Context, first *int32, after *string) (*model.StudysetFeed, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	l := 20
+	if first != nil && *first > 0 && *first < 20 {
+		l = int(*first)
+	}
+
+	return r.myFeed(ctx, authedUser.ID, l, after)
+}
+
+// MyNotifications is the resolver for the myNotifications field.
+func (r *queryResolver) MyNotifications(ctx context.Context, unreadOnly *bool, limit *int32, offset *int32) (*model.NotificationList, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	return r.myNotifications(ctx, authedUser.ID, unreadOnly != nil && *unreadOnly, l, o)
+}
+
+// MyNotificationPreferences is the resolver for the myNotificationPreferences field.
+func (r *queryResolver) MyNotificationPreferences(ctx context.Context) ([]*model.NotificationPreference, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	return r.notificationPreferences(ctx, authedUser.ID)
+}
+
+// FeaturedStudysets is the resolver for the featuredStudysets field.
+func (r *queryResolver) FeaturedStudysets(ctx context.Context, limit *int32, offset *int32) ([]*model.Studyset, error) {
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	var studysets []*model.Studyset
+	sql := `
+		SELECT
+			id,
+			user_id,
+			title,
+			private,
+			term_language,
+			def_language,
+			version,
+			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+		FROM public.studysets
+		WHERE private = false
+			AND featured = true
+			AND deleted_at IS NULL
+		ORDER BY terms_count DESC
+		LIMIT $1 OFFSET $2
+	`
+	err := pgxscan.Select(ctx, r.DB, &studysets, sql, l, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch featured studysets: %w", err)
+	}
+
+	return studysets, nil
+}
+
+// RecentStudysets is the resolver for the recentStudysets field.
+func (r *queryResolver) RecentStudysets(ctx context.Context, limit *int32, offset *int32) ([]*model.Studyset, error) {
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	var studysets []*model.Studyset
+	sql := `
+		SELECT
+			id,
+			user_id,
+			title,
+			private,
+			term_language,
+			def_language,
+			version,
+			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+		FROM public.studysets
+		WHERE private = false AND deleted_at IS NULL
+		ORDER BY updated_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	err := pgxscan.Select(ctx, r.DB, &studysets, sql, l, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recent studysets: %w", err)
+	}
+
+	return studysets, nil
+}
+
+// SearchStudysets is the resolver for the searchStudysets field.
+func (r *queryResolver) SearchStudysets(ctx context.Context, q string, language *string, limit *int32, offset *int32) ([]*model.Studyset, error) {
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	langFilter, err := languageFilter(language)
+	if err != nil {
+		return nil, err
+	}
+
+	/* each studyset (and its terms) is searched with the text search configs
+	for its own languages, so the query gets stemmed the same way they were.
+	The query is parsed once per config, instead of once per row,
+	and matched on rows with the same config, so the GIN indexes still get used */
+	var studysets []*model.Studyset
+	sql := `
+		WITH queries AS MATERIALIZED (
+			SELECT c.config, websearch_to_tsquery(c.config, $1) AS query
+			FROM unnest($5::regconfig[]) AS c(config)
+		),
+		matches AS (
+			SELECT s.id, ts_rank(s.tsvector_title, q.query) AS rank
+			FROM public.studysets s
+			JOIN queries q ON q.config = s.def_search_config
+			WHERE s.tsvector_title @@ q.query
+			UNION ALL
+			SELECT t.studyset_id, 0
+			FROM terms t
+			JOIN queries q ON q.config = t.term_search_config OR q.config = t.def_search_config
+			WHERE t.tsvector_plain @@ q.query AND t.deleted_at IS NULL
+		),
+		ranked AS (
+			SELECT id, max(rank) AS rank FROM matches GROUP BY id
+		)
+		SELECT
+			s.id,
+			s.user_id,
+			s.title,
+			s.private,
+			s.term_language,
+			s.def_language,
+			s.version,
+			to_char(s.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+		FROM ranked m
+		JOIN public.studysets s ON s.id = m.id
+		WHERE (
+				$4::text IS NULL
+				OR split_part(s.term_language, '-', 1) = $4
+				OR split_part(s.def_language, '-', 1) = $4
+			)
+			AND s.private = false
+			AND s.deleted_at IS NULL
+		ORDER BY m.rank DESC, s.id
+		LIMIT $2 OFFSET $3
+	`
+	err = pgxscan.Select(ctx, r.DB, &studysets, sql, q, l, o, langFilter, allSearchConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search studysets: %w", err)
+	}
+
+	return studysets, nil
+}
+
+// MyStudysets is the resolver for the myStudysets field.
+func (r *queryResolver) MyStudysets(ctx context.Context, limit *int32, offset *int32) ([]*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	var studysets []*model.Studyset
+	sql := `
+		SELECT
+			id,
+			user_id,
+			title,
+			private,
+			term_language,
+			def_language,
+			version,
+			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+		FROM public.studysets
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY updated_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	err := pgxscan.Select(ctx, r.DB, &studysets, sql, authedUser.ID, l, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch my studysets: %w", err)
+	}
+
+	return studysets, nil
+}
+
+// SavedStudysets is the resolver for the savedStudysets field.
+func (r *queryResolver) SavedStudysets(ctx context.Context, limit *int32, offset *int32, orderBy *model.SavedStudysetsOrder) ([]*model.Studyset, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	order := "last_studied_at DESC NULLS LAST, ss.created_at DESC"
+	if orderBy != nil && *orderBy == model.SavedStudysetsOrderSavedAt {
+		order = "ss.created_at DESC"
+	}
+
+	/* studysets that were made private or deleted aren't shown,
+	last studied is the latest review or practice test in the studyset */
+	var studysets []*model.Studyset
+	sql := fmt.Sprintf(`
+		SELECT
+			s.id,
+			s.user_id,
+			s.title,
+			s.private,
+			s.term_language,
+			s.def_language,
+			s.version,
+			to_char(s.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+		FROM studyset_stars ss
+		JOIN public.studysets s ON s.id = ss.studyset_id
+		LEFT JOIN LATERAL (
+			SELECT GREATEST(
+				(
+					SELECT max(GREATEST(tp.term_last_reviewed_at, tp.def_last_reviewed_at))
+					FROM term_progress tp
+					JOIN terms t ON t.id = tp.term_id
+					WHERE t.studyset_id = s.id AND tp.user_id = ss.user_id
+				),
+				(
+					SELECT max(pt.timestamp)
+					FROM practice_tests pt
+					WHERE pt.studyset_id = s.id AND pt.user_id = ss.user_id
+				)
+			) AS last_studied_at
+		) studied ON true
+		WHERE ss.user_id = $1
+			AND s.private = false
+			AND s.deleted_at IS NULL
+		ORDER BY %s
+		LIMIT $2 OFFSET $3
+	`, order)
+	err := pgxscan.Select(ctx, r.DB, &studysets, sql, authedUser.ID, l, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved studysets: %w", err)
+	}
+
+	return studysets, nil
+}
+
+// TrendingStudysets is the resolver for the trendingStudysets field.
+func (r *queryResolver) TrendingStudysets(ctx context.Context, window *model.TrendingWindow, limit *int32, offset *int32) ([]*model.Studyset, error) {
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	days := trendingWindowDays[model.TrendingWindowWeek]
+	if window != nil && window.IsValid() {
+		days = trendingWindowDays[*window]
+	}
+
+	/* a day's score halves every half of the window,
+	so the newest days in the window matter most */
+	studysets, err := rankedStudysets(ctx, r.DB, days, float64(days)/2, l, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trending studysets: %w", err)
+	}
+
+	return studysets, nil
+}
+
+// PopularStudysets is the resolver for the popularStudysets field.
+func (r *queryResolver) PopularStudysets(ctx context.Context, limit *int32, offset *int32) ([]*model.Studyset, error) {
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	studysets, err := rankedStudysets(ctx, r.DB, popularWindowDays, popularHalfLifeDays, l, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch popular studysets: %w", err)
+	}
+
+	return studysets, nil
+}
+
+// ModerationQueue is the resolver for the moderationQueue field.
+func (r *queryResolver) ModerationQueue(ctx context.Context, status *model.ReportStatus, limit *int32, offset *int32) ([]*model.ModerationCase, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	if authedUser.Moderator == nil || !*authedUser.Moderator {
+		return nil, fmt.Errorf("not a moderator")
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	s := model.ReportStatusOpen
+	if status != nil && status.IsValid() {
+		s = *status
+	}
+
+	return r.moderationQueue(ctx, s, l, o)
+}
+
+// StudysetRevisionDiff is the resolver for the studysetRevisionDiff field.
+func (r *queryResolver) StudysetRevisionDiff(ctx context.Context, fromRevisionID string, toRevisionID string) (*model.StudysetRevisionDiff, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	fromRevision, err := getOwnedStudysetRevision(ctx, r.DB, fromRevisionID, authedUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := getOwnedStudysetRevision(ctx, r.DB, toRevisionID, authedUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !stringsEqual(fromRevision.StudysetID, toRevision.StudysetID) {
+		return nil, fmt.Errorf("revisions must be from the same studyset")
+	}
+
+	titleChanged := !stringsEqual(fromRevision.Title, toRevision.Title)
+	privateChanged := fromRevision.Private != nil && toRevision.Private != nil &&
+		*fromRevision.Private != *toRevision.Private
+
+	return &model.StudysetRevisionDiff{
+		FromRevision:   fromRevision,
+		ToRevision:     toRevision,
+		TitleChanged:   &titleChanged,
+		PrivateChanged: &privateChanged,
+		TermChanges:    diffRevisionTerms(fromRevision.Terms, toRevision.Terms),
+	}, nil
+}
+
+// MyTrash is the resolver for the myTrash field.
+func (r *queryResolver) MyTrash(ctx context.Context, limit *int32, offset *int32) (*model.Trash, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	var trash model.Trash
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&trash.Studysets,
+		`SELECT id, user_id, title, private, term_language, def_language, version,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at,
+	to_char(deleted_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as deleted_at
+FROM public.studysets
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $2 OFFSET $3`,
+		authedUser.ID,
+		l,
+		o,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trashed studysets: %w", err)
+	}
+
+	/* terms trashed from studysets that are themselves in the trash
+	aren't listed separately, restoring the studyset restores them */
+	err = pgxscan.Select(
+		ctx,
+		r.DB,
+		&trash.Terms,
+		`SELECT t.id, t.studyset_id, t.term, t.def, t.sort_order,
+	t.term_image_id, t.def_image_id,
+	t.term_html, t.def_html, t.term_plain, t.def_plain,
+	t.term_alternates, t.def_alternates, t.hint, t.version,
+	to_char(t.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(t.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at,
+	to_char(t.deleted_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as deleted_at
+FROM terms t
+JOIN public.studysets s ON s.id = t.studyset_id
+WHERE s.user_id = $1
+	AND s.deleted_at IS NULL
+	AND t.deleted_at IS NOT NULL
+ORDER BY t.deleted_at DESC
+LIMIT $2 OFFSET $3`,
+		authedUser.ID,
+		l,
+		o,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trashed terms: %w", err)
+	}
+
+	return &trash, nil
+}
+
+// DetectLanguages is the resolver for the detectLanguages field.
+func (r *queryResolver) DetectLanguages(ctx context.Context, terms []*string, defs []*string) (*model.StudysetLanguages, error) {
+	termTexts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t != nil {
+			termTexts = append(termTexts, *t)
+		}
+	}
+	defTexts := make([]string, 0, len(defs))
+	for _, d := range defs {
+		if d != nil {
+			defTexts = append(defTexts, *d)
+		}
+	}
+
+	return detectStudysetLanguages(termTexts, defTexts), nil
+}
+
+// SuggestedLanguages is the resolver for the suggestedLanguages field.
+func (r *studysetResolver) SuggestedLanguages(ctx context.Context, obj *model.Studyset) (*model.StudysetLanguages, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	terms, err := loader.GetTermsByStudysetID(ctx, *obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+
+	/* plain text, so markdown doesn't throw off the guess */
+	termTexts := make([]string, 0, len(terms))
+	defTexts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t == nil {
+			continue
+		}
+		if t.TermPlain != nil {
+			termTexts = append(termTexts, *t.TermPlain)
+		} else if t.Term != nil {
+			termTexts = append(termTexts, *t.Term)
+		}
+		if t.DefPlain != nil {
+			defTexts = append(defTexts, *t.DefPlain)
+		} else if t.Def != nil {
+			defTexts = append(defTexts, *t.Def)
+		}
+	}
+
+	return detectStudysetLanguages(termTexts, defTexts), nil
+}
+
+// User is the resolver for the user field.
+func (r *studysetResolver) User(ctx context.Context, obj *model.Studyset) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	return loader.GetUser(ctx, *obj.UserID)
+}
+
+// Terms is the resolver for the terms field.
+func (r *studysetResolver) Terms(ctx context.Context, obj *model.Studyset) ([]*model.Term, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	return loader.GetTermsByStudysetID(ctx, *obj.ID)
+}
+
+// TermsCount is the resolver for the terms_count field.
+func (r *studysetResolver) TermsCount(ctx context.Context, obj *model.Studyset) (*int32, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	return loader.GetTermsCountByStudysetID(ctx, *obj.ID)
+}
+
+// PracticeTests is the resolver for the practice_tests field.
+func (r *studysetResolver) PracticeTests(ctx context.Context, obj *model.Studyset) ([]*model.PracticeTest, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil || obj.ID == nil {
+		return nil, nil
+	}
+
+	return loader.GetPracticeTestsByStudysetID(ctx, *obj.ID)
+}
+
+// MySettings is the resolver for the mySettings field.
+func (r *studysetResolver) MySettings(ctx context.Context, obj *model.Studyset) (*model.StudysetSettings, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil || obj.ID == nil {
+		return nil, nil
+	}
+
+	saved, err := loader.GetStudysetSettings(ctx, *obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return withStudysetSettingsDefaults(saved), nil
+}
+
+// StarCount is the resolver for the starCount field.
+func (r *studysetResolver) StarCount(ctx context.Context, obj *model.Studyset) (*int32, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	return loader.GetStarCountByStudysetID(ctx, *obj.ID)
+}
+
+// StarredByMe is the resolver for the starredByMe field.
+func (r *studysetResolver) StarredByMe(ctx context.Context, obj *model.Studyset) (*bool, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil || obj.ID == nil {
+		return nil, nil
 	}
-	if err != nil {
-		if pgxscan.NotFound(err) {
-			return nil, fmt.Errorf("studyset not found")
-		}
-		return nil, fmt.Errorf("failed to fetch studyset: %w", err)
+
+	return loader.GetStarredByMe(ctx, *obj.ID)
+}
+
+// ViewCount is the resolver for the viewCount field.
+func (r *studysetResolver) ViewCount(ctx context.Context, obj *model.Studyset) (*int32, error) {
+	if obj.ID == nil {
+		return nil, nil
 	}
 
-	return &studyset, nil
+	return loader.GetViewCountByStudysetID(ctx, *obj.ID)
 }
 
-// User is the resolver for the user field.
-func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error) {
-	var user model.User
-	sql := `
-		SELECT
-			id,
-			username,
-			display_name,
-		FROM auth.users
-		WHERE id = $1
-	`
-	err := pgxscan.Get(ctx, r.DB, &user, sql, id)
-	if err != nil {
-		if pgxscan.NotFound(err) {
-			return nil, fmt.Errorf("user not found")
-		}
-		return nil, fmt.Errorf("failed to fetch user: %w", err)
+// LearnerCount is the resolver for the learnerCount field.
+func (r *studysetResolver) LearnerCount(ctx context.Context, obj *model.Studyset) (*int32, error) {
+	if obj.ID == nil {
+		return nil, nil
 	}
-	return &user, nil
+
+	return loader.GetLearnerCountByStudysetID(ctx, *obj.ID)
 }
 
-// FeaturedStudysets is the resolver for the featuredStudysets field.
-func (r *queryResolver) FeaturedStudysets(ctx context.Context, limit *int32, offset *int32) ([]*model.Studyset, error) {
+// Revisions is the resolver for the revisions field.
+func (r *studysetResolver) Revisions(ctx context.Context, obj *model.Studyset, limit *int32, offset *int32) ([]*model.StudysetRevision, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil || obj.ID == nil {
+		return nil, nil
+	}
+	/* only the owner can see who changed their studyset and when */
+	if !stringsEqual(obj.UserID, authedUser.ID) {
+		return nil, nil
+	}
+
 	l := 20
 	if limit != nil && *limit > 0 && *limit < 20 {
 		l = int(*limit)
@@ -496,30 +1870,29 @@ func (r *queryResolver) FeaturedStudysets(ctx context.Context, limit *int32, off
 		o = int(*offset)
 	}
 
-	var studysets []*model.Studyset
+	var revisions []*model.StudysetRevision
 	sql := `
-		SELECT
-			id,
-			user_id,
-			title,
-			private,
-			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
-		FROM public.studysets
-		WHERE private = false
-			AND featured = true
-		ORDER BY terms_count DESC
-		LIMIT $1 OFFSET $2
+		SELECT id, studyset_id, user_id, title, private, terms,
+			to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at
+		FROM studyset_revisions
+		WHERE studyset_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
 	`
-	err := pgxscan.Select(ctx, r.DB, &studysets, sql, l, o)
+	err := pgxscan.Select(ctx, r.DB, &revisions, sql, obj.ID, l, o)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch featured studysets: %w", err)
+		return nil, fmt.Errorf("failed to fetch studyset revisions: %w", err)
 	}
 
-	return studysets, nil
+	return revisions, nil
 }
 
-// RecentStudysets is the resolver for the recentStudysets field.
-func (r *queryResolver) RecentStudysets(ctx context.Context, limit *int32, offset *int32) ([]*model.Studyset, error) {
+// Comments is the resolver for the comments field.
+func (r *studysetResolver) Comments(ctx context.Context, obj *model.Studyset, limit *int32, offset *int32) ([]*model.Comment, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
 	l := 20
 	if limit != nil && *limit > 0 && *limit < 20 {
 		l = int(*limit)
@@ -530,29 +1903,16 @@ func (r *queryResolver) RecentStudysets(ctx context.Context, limit *int32, offse
 		o = int(*offset)
 	}
 
-	var studysets []*model.Studyset
-	sql := `
-		SELECT
-			id,
-			user_id,
-			title,
-			private,
-			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
-		FROM public.studysets
-		WHERE private = false
-		ORDER BY updated_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	err := pgxscan.Select(ctx, r.DB, &studysets, sql, l, o)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch recent studysets: %w", err)
-	}
-
-	return studysets, nil
+	return loader.GetStudysetComments(ctx, loader.CommentsPage{ID: *obj.ID, Limit: l, Offset: o})
 }
 
-// SearchStudysets is the resolver for the searchStudysets field.
-func (r *queryResolver) SearchStudysets(ctx context.Context, q string, limit *int32, offset *int32) ([]*model.Studyset, error) {
+// Suggestions is the resolver for the suggestions field.
+func (r *studysetResolver) Suggestions(ctx context.Context, obj *model.Studyset, status *model.SuggestionStatus, limit *int32, offset *int32) ([]*model.StudysetSuggestion, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil || obj.ID == nil {
+		return nil, nil
+	}
+
 	l := 20
 	if limit != nil && *limit > 0 && *limit < 20 {
 		l = int(*limit)
@@ -563,67 +1923,73 @@ func (r *queryResolver) SearchStudysets(ctx context.Context, q string, limit *in
 		o = int(*offset)
 	}
 
-	var studysets []*model.Studyset
-	sql := `
-		SELECT
-			id,
-			user_id,
-			title,
-			private,
-			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
-		FROM public.studysets
-		WHERE tsvector_title @@ websearch_to_tsquery('english', $1) AND private = false
-		ORDER BY ts_rank(tsvector_title, websearch_to_tsquery('english', $1)) DESC
-		LIMIT $2 OFFSET $3
-	`
-	err := pgxscan.Select(ctx, r.DB, &studysets, sql, q, l, o)
+	return r.studysetSuggestions(ctx, obj, authedUser.ID, status, l, o)
+}
+
+// DuplicateTerms is the resolver for the duplicateTerms field.
+func (r *studysetResolver) DuplicateTerms(ctx context.Context, obj *model.Studyset, threshold *float64) ([]*model.DuplicateTermGroup, error) {
+	authedUser := auth.AuthedUserContext(ctx)
+	if authedUser == nil || authedUser.ID == nil || obj.ID == nil {
+		return nil, nil
+	}
+	/* comparing terms is too expensive to let anyone run on any public studyset,
+	and only the owner can fix duplicates anyway */
+	if !stringsEqual(obj.UserID, authedUser.ID) {
+		return nil, nil
+	}
+
+	t := DefaultDuplicateThreshold
+	if threshold != nil {
+		t = *threshold
+	}
+
+	tx, err := r.DB.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search studysets: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	return studysets, nil
+	return findDuplicateTerms(ctx, tx, *obj.ID, t)
 }
 
-// MyStudysets is the resolver for the myStudysets field.
-func (r *queryResolver) MyStudysets(ctx context.Context, limit *int32, offset *int32) ([]*model.Studyset, error) {
-	authedUser := auth.AuthedUserContext(ctx)
-	if authedUser == nil {
-		return nil, fmt.Errorf("not authenticated")
+// Reporter is the resolver for the reporter field.
+func (r *studysetReportResolver) Reporter(ctx context.Context, obj *model.StudysetReport) (*model.User, error) {
+	if obj.ReporterID == nil {
+		return nil, nil
 	}
 
-	l := 20
-	if limit != nil && *limit > 0 && *limit < 20 {
-		l = int(*limit)
+	return loader.GetUser(ctx, *obj.ReporterID)
+}
+
+// User is the resolver for the user field.
+func (r *studysetRevisionResolver) User(ctx context.Context, obj *model.StudysetRevision) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
 	}
 
-	o := 0
-	if offset != nil && *offset > 0 {
-		o = int(*offset)
+	return loader.GetUser(ctx, *obj.UserID)
+}
+
+// Studyset is the resolver for the studyset field.
+func (r *studysetSuggestionResolver) Studyset(ctx context.Context, obj *model.StudysetSuggestion) (*model.Studyset, error) {
+	if obj.StudysetID == nil {
+		return nil, nil
 	}
 
-	var studysets []*model.Studyset
-	sql := `
-		SELECT
-			id,
-			user_id,
-			title,
-			private,
-			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
-		FROM public.studysets
-		WHERE user_id = $1
-		ORDER BY updated_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	err := pgxscan.Select(ctx, r.DB, &studysets, sql, authedUser.ID, l, o)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch my studysets: %w", err)
+	return r.Query().Studyset(ctx, *obj.StudysetID)
+}
+
+// User is the resolver for the user field.
+func (r *studysetSuggestionResolver) User(ctx context.Context, obj *model.StudysetSuggestion) (*model.User, error) {
+	if obj.UserID == nil {
+		return nil, nil
 	}
 
-	return studysets, nil
+	return loader.GetUser(ctx, *obj.UserID)
 }
 
 // User is the resolver for the user field.
-func (r *studysetResolver) User(ctx context.Context, obj *model.Studyset) (*model.User, error) {
+func (r *suggestionNotificationResolver) User(ctx context.Context, obj *model.SuggestionNotification) (*model.User, error) {
 	if obj.UserID == nil {
 		return nil, nil
 	}
@@ -631,32 +1997,54 @@ func (r *studysetResolver) User(ctx context.Context, obj *model.Studyset) (*mode
 	return loader.GetUser(ctx, *obj.UserID)
 }
 
-// Terms is the resolver for the terms field.
-func (r *studysetResolver) Terms(ctx context.Context, obj *model.Studyset) ([]*model.Term, error) {
-	if obj.ID == nil {
+// TermHTML is the resolver for the termHtml field.
+func (r *termResolver) TermHTML(ctx context.Context, obj *model.Term) (*string, error) {
+	if obj.TermHTML == nil {
+		/* terms from before markdown support don't have html yet */
+		html, _ := renderTermText(obj.Term)
+		return html, nil
+	}
+
+	return obj.TermHTML, nil
+}
+
+// DefHTML is the resolver for the defHtml field.
+func (r *termResolver) DefHTML(ctx context.Context, obj *model.Term) (*string, error) {
+	if obj.DefHTML == nil {
+		/* terms from before markdown support don't have html yet */
+		html, _ := renderTermText(obj.Def)
+		return html, nil
+	}
+
+	return obj.DefHTML, nil
+}
+
+// TermImage is the resolver for the termImage field.
+func (r *termResolver) TermImage(ctx context.Context, obj *model.Term) (*model.Image, error) {
+	if obj.TermImageID == nil {
 		return nil, nil
 	}
 
-	return loader.GetTermsByStudysetID(ctx, *obj.ID)
+	return loader.GetImage(ctx, *obj.TermImageID)
 }
 
-// TermsCount is the resolver for the terms_count field.
-func (r *studysetResolver) TermsCount(ctx context.Context, obj *model.Studyset) (*int32, error) {
-	if obj.ID == nil {
+// DefImage is the resolver for the defImage field.
+func (r *termResolver) DefImage(ctx context.Context, obj *model.Term) (*model.Image, error) {
+	if obj.DefImageID == nil {
 		return nil, nil
 	}
 
-	return loader.GetTermsCountByStudysetID(ctx, *obj.ID)
+	return loader.GetImage(ctx, *obj.DefImageID)
 }
 
-// PracticeTests is the resolver for the practice_tests field.
-func (r *studysetResolver) PracticeTests(ctx context.Context, obj *model.Studyset) ([]*model.PracticeTest, error) {
+// MyNote is the resolver for the myNote field.
+func (r *termResolver) MyNote(ctx context.Context, obj *model.Term) (*model.TermNote, error) {
 	authedUser := auth.AuthedUserContext(ctx)
 	if authedUser == nil || authedUser.ID == nil || obj.ID == nil {
 		return nil, nil
 	}
 
-	return loader.GetPracticeTestsByStudysetID(ctx, *obj.ID)
+	return loader.GetTermNote(ctx, *obj.ID)
 }
 
 // Progress is the resolver for the progress field.
@@ -689,6 +2077,25 @@ func (r *termResolver) TopReverseConfusionPairs(ctx context.Context, obj *model.
 	return loader.GetTermTopReverseConfusionPairs(ctx, *obj.ID)
 }
 
+// Comments is the resolver for the comments field.
+func (r *termResolver) Comments(ctx context.Context, obj *model.Term, limit *int32, offset *int32) ([]*model.Comment, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	return loader.GetTermComments(ctx, loader.CommentsPage{ID: *obj.ID, Limit: l, Offset: o})
+}
+
 // Term is the resolver for the term field.
 func (r *termConfusionPairResolver) Term(ctx context.Context, obj *model.TermConfusionPair) (*model.Term, error) {
 	if obj.TermID == nil {
@@ -707,6 +2114,118 @@ func (r *termConfusionPairResolver) ConfusedTerm(ctx context.Context, obj *model
 	return loader.GetTermByID(ctx, *obj.ConfusedTermID)
 }
 
+// Bio is the resolver for the bio field.
+func (r *userResolver) Bio(ctx context.Context, obj *model.User) (*string, error) {
+	if !profileVisible(ctx, obj) {
+		return nil, nil
+	}
+
+	return obj.Bio, nil
+}
+
+// Avatar is the resolver for the avatar field.
+func (r *userResolver) Avatar(ctx context.Context, obj *model.User) (*model.Image, error) {
+	if obj.AvatarImageID == nil || !profileVisible(ctx, obj) {
+		return nil, nil
+	}
+
+	return loader.GetImage(ctx, *obj.AvatarImageID)
+}
+
+// JoinedAt is the resolver for the joinedAt field.
+func (r *userResolver) JoinedAt(ctx context.Context, obj *model.User) (*string, error) {
+	if !profileVisible(ctx, obj) {
+		return nil, nil
+	}
+	if !isOwnProfile(ctx, obj) && obj.ProfileShowJoinedAt != nil && !*obj.ProfileShowJoinedAt {
+		return nil, nil
+	}
+
+	return obj.JoinedAt, nil
+}
+
+// Studysets is the resolver for the studysets field.
+func (r *userResolver) Studysets(ctx context.Context, obj *model.User, limit *int32, offset *int32) ([]*model.Studyset, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	l := 20
+	if limit != nil && *limit > 0 && *limit < 20 {
+		l = int(*limit)
+	}
+
+	o := 0
+	if offset != nil && *offset > 0 {
+		o = int(*offset)
+	}
+
+	return r.userStudysets(ctx, obj, l, o)
+}
+
+// Privacy is the resolver for the privacy field.
+func (r *userResolver) Privacy(ctx context.Context, obj *model.User) (*model.ProfilePrivacy, error) {
+	/* only users can see their own privacy settings */
+	if !isOwnProfile(ctx, obj) {
+		return nil, nil
+	}
+
+	return &model.ProfilePrivacy{
+		Private:       obj.ProfilePrivate,
+		ShowStudysets: obj.ProfileShowStudysets,
+		ShowJoinedAt:  obj.ProfileShowJoinedAt,
+	}, nil
+}
+
+// FollowerCount is the resolver for the followerCount field.
+func (r *userResolver) FollowerCount(ctx context.Context, obj *model.User) (*int32, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	return loader.GetFollowerCount(ctx, *obj.ID)
+}
+
+// FollowingCount is the resolver for the followingCount field.
+func (r *userResolver) FollowingCount(ctx context.Context, obj *model.User) (*int32, error) {
+	if obj.ID == nil {
+		return nil, nil
+	}
+
+	return loader.GetFollowingCount(ctx, *obj.ID)
+}
+
+// Comment returns CommentResolver implementation.
+func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
+
+// CommentNotification returns CommentNotificationResolver implementation.
+func (r *Resolver) CommentNotification() CommentNotificationResolver {
+	return &commentNotificationResolver{r}
+}
+
+// DuplicateTermGroup returns DuplicateTermGroupResolver implementation.
+func (r *Resolver) DuplicateTermGroup() DuplicateTermGroupResolver {
+	return &duplicateTermGroupResolver{r}
+}
+
+// DuplicateTermPair returns DuplicateTermPairResolver implementation.
+func (r *Resolver) DuplicateTermPair() DuplicateTermPairResolver {
+	return &duplicateTermPairResolver{r}
+}
+
+// FollowNotification returns FollowNotificationResolver implementation.
+func (r *Resolver) FollowNotification() FollowNotificationResolver {
+	return &followNotificationResolver{r}
+}
+
+// Image returns ImageResolver implementation.
+func (r *Resolver) Image() ImageResolver { return &imageResolver{r} }
+
+// ModerationDecision returns ModerationDecisionResolver implementation.
+func (r *Resolver) ModerationDecision() ModerationDecisionResolver {
+	return &moderationDecisionResolver{r}
+}
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
@@ -716,6 +2235,22 @@ func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 // Studyset returns StudysetResolver implementation.
 func (r *Resolver) Studyset() StudysetResolver { return &studysetResolver{r} }
 
+// StudysetReport returns StudysetReportResolver implementation.
+func (r *Resolver) StudysetReport() StudysetReportResolver { return &studysetReportResolver{r} }
+
+// StudysetRevision returns StudysetRevisionResolver implementation.
+func (r *Resolver) StudysetRevision() StudysetRevisionResolver { return &studysetRevisionResolver{r} }
+
+// StudysetSuggestion returns StudysetSuggestionResolver implementation.
+func (r *Resolver) StudysetSuggestion() StudysetSuggestionResolver {
+	return &studysetSuggestionResolver{r}
+}
+
+// SuggestionNotification returns SuggestionNotificationResolver implementation.
+func (r *Resolver) SuggestionNotification() SuggestionNotificationResolver {
+	return &suggestionNotificationResolver{r}
+}
+
 // Term returns TermResolver implementation.
 func (r *Resolver) Term() TermResolver { return &termResolver{r} }
 
@@ -724,8 +2259,23 @@ func (r *Resolver) TermConfusionPair() TermConfusionPairResolver {
 	return &termConfusionPairResolver{r}
 }
 
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type commentResolver struct{ *Resolver }
+type commentNotificationResolver struct{ *Resolver }
+type duplicateTermGroupResolver struct{ *Resolver }
+type duplicateTermPairResolver struct{ *Resolver }
+type followNotificationResolver struct{ *Resolver }
+type imageResolver struct{ *Resolver }
+type moderationDecisionResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type studysetResolver struct{ *Resolver }
+type studysetReportResolver struct{ *Resolver }
+type studysetRevisionResolver struct{ *Resolver }
+type studysetSuggestionResolver struct{ *Resolver }
+type suggestionNotificationResolver struct{ *Resolver }
 type termResolver struct{ *Resolver }
 type termConfusionPairResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,210 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+const maxSplitParts = 50
+
+// splitPartTitle is the title of a studyset split from another, like "Unit 1 (part 2)",
+// the original title is shortened if it has to be, so it stays under 200 bytes (like every title)
+func splitPartTitle(title string, part int) string {
+	suffix := fmt.Sprintf(" (part %d)", part)
+	for len(title)+len(suffix) >= 200 {
+		_, size := utf8.DecodeLastRuneInString(title)
+		title = title[:len(title)-size]
+	}
+	return strings.TrimSpace(title) + suffix
+}
+
+// splitTermGroups divides a studyset's terms (in order) into the groups for each new studyset,
+// by exactly one of the strategy's size, sort order ranges, or explicit groups of term ids.
+// Terms that aren't in a range or group aren't copied into any of the new studysets
+func splitTermGroups(terms []*copiedTerm, strategy model.SplitStrategyInput) ([][]*copiedTerm, error) {
+	strategies := 0
+	if strategy.Size != nil {
+		strategies++
+	}
+	if strategy.Ranges != nil {
+		strategies++
+	}
+	if strategy.Groups != nil {
+		strategies++
+	}
+	if strategies != 1 {
+		return nil, fmt.Errorf("strategy must have exactly one of size, ranges, or groups")
+	}
+
+	var groups [][]*copiedTerm
+	switch {
+	case strategy.Size != nil:
+		size := int(*strategy.Size)
+		if size < 1 {
+			return nil, fmt.Errorf("size must be at least 1")
+		}
+		for start := 0; start < len(terms); start += size {
+			groups = append(groups, terms[start:min(start+size, len(terms))])
+		}
+	case strategy.Ranges != nil:
+		/* terms are in sort_order, which is always 0, 1, 2, ... (see renumberTerms) */
+		covered := make([]bool, len(terms))
+		for _, r := range strategy.Ranges {
+			from, to := int(r.From), int(r.To)
+			if from < 0 || to < from || to >= len(terms) {
+				return nil, fmt.Errorf("range %d-%d is outside of the studyset's %d terms", r.From, r.To, len(terms))
+			}
+			for i := from; i <= to; i++ {
+				if covered[i] {
+					return nil, fmt.Errorf("ranges can't overlap")
+				}
+				covered[i] = true
+			}
+			groups = append(groups, terms[from:to+1])
+		}
+	case strategy.Groups != nil:
+		byID := make(map[string]*copiedTerm, len(terms))
+		for _, t := range terms {
+			byID[t.ID] = t
+		}
+		grouped := make(map[string]bool, len(terms))
+		for _, ids := range strategy.Groups {
+			group := make([]*copiedTerm, 0, len(ids))
+			for _, id := range ids {
+				t, ok := byID[id]
+				if !ok {
+					return nil, fmt.Errorf("term not found")
+				}
+				if grouped[id] {
+					return nil, fmt.Errorf("a term can only be in one group")
+				}
+				grouped[id] = true
+				group = append(group, t)
+			}
+			groups = append(groups, group)
+		}
+	}
+
+	for _, group := range groups {
+		if len(group) == 0 {
+			return nil, fmt.Errorf("groups can't be empty")
+		}
+	}
+	if len(groups) < 2 {
+		return nil, fmt.Errorf("studyset must be split into at least 2 parts")
+	}
+	if len(groups) > maxSplitParts {
+		return nil, fmt.Errorf("studyset can be split into at most %d parts", maxSplitParts)
+	}
+	return groups, nil
+}
+
+// splitStudyset divides one of userID's studysets into new studysets, titled "<title> (part n)",
+// with the same privacy, languages, and moderation hold. userID's progress & confused terms are copied to the new terms.
+// Without keepOriginal, the original goes to the trash
+func (r *Resolver) splitStudyset(ctx context.Context, userID *string, studysetID string, strategy model.SplitStrategyInput, keepOriginal bool) ([]*model.Studyset, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	/* locks the studyset, so its terms can't change while it's being split */
+	if err := checkStudysetVersions(ctx, tx, studysetID, userID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	var original struct {
+		model.Studyset
+		ModerationHold bool
+	}
+	err = pgxscan.Get(
+		ctx,
+		tx,
+		&original,
+		"SELECT id, title, private, term_language, def_language, moderation_hold FROM public.studysets WHERE id = $1",
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch studyset: %w", err)
+	}
+
+	var terms []*copiedTerm
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&terms,
+		`SELECT `+copiedTermColumns+`
+FROM terms t
+JOIN public.studysets s ON s.id = t.studyset_id
+WHERE t.studyset_id = $1 AND t.deleted_at IS NULL
+ORDER BY t.sort_order, t.created_at, t.id`,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+
+	groups, err := splitTermGroups(terms, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]*model.Studyset, 0, len(groups))
+	for i, group := range groups {
+		newTerms := make([]*model.NewTermInput, 0, len(group))
+		sourceTermIDs := make([][]string, 0, len(group))
+		for j, t := range group {
+			newTerms = append(newTerms, t.newTermInput(j))
+			sourceTermIDs = append(sourceTermIDs, []string{t.ID})
+		}
+
+		/* the terms' images are already in userID's studyset */
+		part, err := r.createStudysetWithTermsTx(ctx, tx, userID, model.StudysetInput{
+			Title:        splitPartTitle(*original.Title, i+1),
+			Private:      *original.Private,
+			TermLanguage: original.TermLanguage,
+			DefLanguage:  original.DefLanguage,
+		}, newTerms)
+		if err != nil {
+			return nil, err
+		}
+
+		/* parts of a hidden studyset stay hidden until a moderator decides on them too */
+		if original.ModerationHold {
+			_, err = tx.Exec(
+				ctx,
+				"UPDATE public.studysets SET private = true, moderation_hold = true WHERE id = $1",
+				*part.ID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to hold studyset: %w", err)
+			}
+			held := true
+			part.Private = &held
+		}
+
+		if err := carryOverTermProgress(ctx, tx, userID, *part.ID, sourceTermIDs); err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	if !keepOriginal {
+		_, err = tx.Exec(ctx, "UPDATE public.studysets SET deleted_at = now() WHERE id = $1", studysetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete studyset: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"fmt"
+	"quizfreely/api/graph/model"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitTermGroups(t *testing.T) {
+	terms := make([]*copiedTerm, 6)
+	for i := range terms {
+		terms[i] = &copiedTerm{ID: fmt.Sprint("t", i)}
+	}
+	size := func(n int32) *int32 { return &n }
+	ranges := func(fromTo ...int32) []*model.SplitRangeInput {
+		var r []*model.SplitRangeInput
+		for i := 0; i+1 < len(fromTo); i += 2 {
+			r = append(r, &model.SplitRangeInput{From: fromTo[i], To: fromTo[i+1]})
+		}
+		return r
+	}
+	manyTerms := make([]*copiedTerm, maxSplitParts+1)
+	for i := range manyTerms {
+		manyTerms[i] = &copiedTerm{ID: fmt.Sprint("t", i)}
+	}
+
+	tests := []struct {
+		name     string
+		terms    []*copiedTerm
+		strategy model.SplitStrategyInput
+		want     [][]string
+		wantErr  string
+	}{
+		{"size", terms, model.SplitStrategyInput{Size: size(4)}, [][]string{{"t0", "t1", "t2", "t3"}, {"t4", "t5"}}, ""},
+		{"size of 1", terms[:2], model.SplitStrategyInput{Size: size(1)}, [][]string{{"t0"}, {"t1"}}, ""},
+		{"size 0", terms, model.SplitStrategyInput{Size: size(0)}, nil, "size must be at least 1"},
+		{"size bigger than the studyset", terms, model.SplitStrategyInput{Size: size(6)}, nil, "studyset must be split into at least 2 parts"},
+		{"size makes too many parts", manyTerms, model.SplitStrategyInput{Size: size(1)}, nil, "studyset can be split into at most 50 parts"},
+		{"exactly the most parts", manyTerms[:maxSplitParts], model.SplitStrategyInput{Size: size(1)}, nil, ""},
+		{"ranges", terms, model.SplitStrategyInput{Ranges: ranges(3, 5, 0, 1)}, [][]string{{"t3", "t4", "t5"}, {"t0", "t1"}}, ""},
+		{"overlapping ranges", terms, model.SplitStrategyInput{Ranges: ranges(0, 2, 2, 4)}, nil, "ranges can't overlap"},
+		{"range past the end", terms, model.SplitStrategyInput{Ranges: ranges(0, 2, 3, 6)}, nil, "range 3-6 is outside of the studyset's 6 terms"},
+		{"negative range", terms, model.SplitStrategyInput{Ranges: ranges(-1, 2, 3, 4)}, nil, "range -1-2 is outside of the studyset's 6 terms"},
+		{"backwards range", terms, model.SplitStrategyInput{Ranges: ranges(0, 1, 4, 3)}, nil, "range 4-3 is outside of the studyset's 6 terms"},
+		{"one range", terms, model.SplitStrategyInput{Ranges: ranges(0, 5)}, nil, "studyset must be split into at least 2 parts"},
+		{"no ranges", terms, model.SplitStrategyInput{Ranges: []*model.SplitRangeInput{}}, nil, "studyset must be split into at least 2 parts"},
+		{"groups", terms, model.SplitStrategyInput{Groups: [][]string{{"t5", "t0"}, {"t2"}}}, [][]string{{"t5", "t0"}, {"t2"}}, ""},
+		{"duplicate ids across groups", terms, model.SplitStrategyInput{Groups: [][]string{{"t0", "t1"}, {"t1"}}}, nil, "a term can only be in one group"},
+		{"duplicate ids in a group", terms, model.SplitStrategyInput{Groups: [][]string{{"t0", "t0"}, {"t1"}}}, nil, "a term can only be in one group"},
+		{"unknown id", terms, model.SplitStrategyInput{Groups: [][]string{{"t0"}, {"other"}}}, nil, "term not found"},
+		{"empty group", terms, model.SplitStrategyInput{Groups: [][]string{{"t0"}, {}}}, nil, "groups can't be empty"},
+		{"one group", terms, model.SplitStrategyInput{Groups: [][]string{{"t0", "t1"}}}, nil, "studyset must be split into at least 2 parts"},
+		{"no strategy", terms, model.SplitStrategyInput{}, nil, "strategy must have exactly one of size, ranges, or groups"},
+		{"two strategies", terms, model.SplitStrategyInput{Size: size(2), Ranges: ranges(0, 1, 2, 3)}, nil, "strategy must have exactly one of size, ranges, or groups"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := splitTermGroups(tt.terms, tt.strategy)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("splitTermGroups() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitTermGroups() error = %v", err)
+			}
+			if tt.want == nil {
+				return
+			}
+			got := make([][]string, len(groups))
+			for i, group := range groups {
+				for _, term := range group {
+					got[i] = append(got[i], term.ID)
+				}
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("splitTermGroups() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitPartTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		part  int
+		want  string
+	}{
+		{"short", "Unit 1", 2, "Unit 1 (part 2)"},
+		{"just fits", strings.Repeat("a", 190), 1, strings.Repeat("a", 190) + " (part 1)"},
+		{"one byte over", strings.Repeat("a", 191), 1, strings.Repeat("a", 190) + " (part 1)"},
+		{"longer suffix", strings.Repeat("a", 190), 10, strings.Repeat("a", 189) + " (part 10)"},
+		{"trimmed on a rune boundary", "a" + strings.Repeat("é", 100), 1, "a" + strings.Repeat("é", 94) + " (part 1)"},
+		{"trailing space is trimmed", strings.Repeat("a", 188) + "  b", 3, strings.Repeat("a", 188) + " (part 3)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitPartTitle(tt.title, tt.part)
+			if got != tt.want {
+				t.Errorf("splitPartTitle() = %q, want %q", got, tt.want)
+			}
+			if len(got) >= 200 || !utf8.ValidString(got) {
+				t.Errorf("splitPartTitle() = %d bytes, valid utf-8: %v", len(got), utf8.ValidString(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// removeHiddenStars removes other users' stars from a studyset once it's private,
+// so it disappears from their saved studysets instead of coming back if it's made public again
+func removeHiddenStars(ctx context.Context, tx pgx.Tx, studysetID string) error {
+	_, err := tx.Exec(
+		ctx,
+		`DELETE FROM studyset_stars ss
+USING public.studysets s
+WHERE s.id = $1
+	AND s.private = true
+	AND ss.studyset_id = s.id
+	AND ss.user_id <> s.user_id`,
+		studysetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove stars: %w", err)
+	}
+	return nil
+}
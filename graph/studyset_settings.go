@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"slices"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultLeitnerBoxCount = 5
+	minLeitnerBoxCount     = 2
+	maxLeitnerBoxCount     = 10
+)
+
+// withStudysetSettingsDefaults fills in every setting that isn't saved (or isn't valid anymore)
+// with its default, so clients always get a complete StudysetSettings
+func withStudysetSettingsDefaults(saved *model.StudysetSettings) *model.StudysetSettings {
+	settings := model.StudysetSettings{}
+	if saved != nil {
+		settings = *saved
+	}
+
+	if settings.AnswerWith == nil || !settings.AnswerWith.IsValid() {
+		answerWith := model.AnswerSideDef
+		settings.AnswerWith = &answerWith
+	}
+	if settings.Shuffle == nil {
+		shuffle := false
+		settings.Shuffle = &shuffle
+	}
+
+	questionTypes := make([]model.QuestionType, 0, len(model.AllQuestionType))
+	for _, questionType := range settings.QuestionTypes {
+		if questionType.IsValid() && !slices.Contains(questionTypes, questionType) {
+			questionTypes = append(questionTypes, questionType)
+		}
+	}
+	if len(questionTypes) == 0 {
+		questionTypes = append(questionTypes, model.AllQuestionType...)
+	}
+	settings.QuestionTypes = questionTypes
+
+	if settings.LeitnerBoxCount == nil ||
+		*settings.LeitnerBoxCount < minLeitnerBoxCount ||
+		*settings.LeitnerBoxCount > maxLeitnerBoxCount {
+		boxCount := int32(defaultLeitnerBoxCount)
+		settings.LeitnerBoxCount = &boxCount
+	}
+	if settings.Audio == nil {
+		audio := false
+		settings.Audio = &audio
+	}
+
+	return &settings
+}
+
+// mergeStudysetSettings applies an input on top of saved settings,
+// settings that aren't in the input stay the same.
+// Defaults aren't filled in, so settings the user never chose follow the defaults if they change
+func mergeStudysetSettings(saved *model.StudysetSettings, input model.StudysetSettingsInput) (*model.StudysetSettings, error) {
+	merged := model.StudysetSettings{}
+	if saved != nil {
+		merged = *saved
+	}
+
+	if input.AnswerWith != nil {
+		merged.AnswerWith = input.AnswerWith
+	}
+	if input.Shuffle != nil {
+		merged.Shuffle = input.Shuffle
+	}
+	if input.QuestionTypes != nil {
+		if len(input.QuestionTypes) == 0 {
+			return nil, fmt.Errorf("at least one question type must be enabled")
+		}
+		merged.QuestionTypes = input.QuestionTypes
+	}
+	if input.LeitnerBoxCount != nil {
+		if *input.LeitnerBoxCount < minLeitnerBoxCount || *input.LeitnerBoxCount > maxLeitnerBoxCount {
+			return nil, fmt.Errorf("leitnerBoxCount must be between %d and %d", minLeitnerBoxCount, maxLeitnerBoxCount)
+		}
+		merged.LeitnerBoxCount = input.LeitnerBoxCount
+	}
+	if input.Audio != nil {
+		merged.Audio = input.Audio
+	}
+
+	return &merged, nil
+}
+
+// lockSavedStudysetSettings gets a user's saved settings for a studyset (nil if there aren't any),
+// locking them until the transaction ends so two saves can't undo each other's changes
+func lockSavedStudysetSettings(ctx context.Context, tx pgx.Tx, studysetID string, userID *string) (*model.StudysetSettings, error) {
+	var savedJSON []byte
+	err := tx.QueryRow(
+		ctx,
+		`SELECT settings FROM studyset_settings
+WHERE studyset_id = $1 AND user_id = $2
+FOR UPDATE`,
+		studysetID,
+		userID,
+	).Scan(&savedJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get studyset settings: %w", err)
+	}
+
+	var saved model.StudysetSettings
+	if err := json.Unmarshal(savedJSON, &saved); err != nil {
+		/* unreadable settings get replaced instead of blocking every save */
+		return nil, nil
+	}
+	return &saved, nil
+}
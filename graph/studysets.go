@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// createStudyset creates a studyset with its terms in one transaction,
+// it's shared by CreateStudyset and ImportStudyset
+// so imported studysets get validated, rendered, and revisioned the same way.
+// With checkDuplicates, (near) duplicate terms are returned as its DuplicateWarnings
+func (r *Resolver) createStudyset(ctx context.Context, userID *string, studyset model.StudysetInput, terms []*model.NewTermInput, checkDuplicates bool) (*model.Studyset, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	newStudyset, err := r.createStudysetTx(ctx, tx, userID, studyset, terms)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkDuplicates {
+		newStudyset.DuplicateWarnings, err = findDuplicateTerms(ctx, tx, *newStudyset.ID, DefaultDuplicateThreshold)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return newStudyset, nil
+}
+
+// createStudysetTx is createStudyset inside an existing transaction,
+// for creating more than one studyset at once (like importing an anki package)
+func (r *Resolver) createStudysetTx(ctx context.Context, tx pgx.Tx, userID *string, studyset model.StudysetInput, terms []*model.NewTermInput) (*model.Studyset, error) {
+	if err := checkNewTermImagesOwned(ctx, tx, userID, terms); err != nil {
+		return nil, err
+	}
+	return r.createStudysetWithTermsTx(ctx, tx, userID, studyset, terms)
+}
+
+// createStudysetWithTermsTx is createStudysetTx without checking who uploaded the terms' images,
+// for terms copied from studysets userID can already see (like merging studysets)
+func (r *Resolver) createStudysetWithTermsTx(ctx context.Context, tx pgx.Tx, userID *string, studyset model.StudysetInput, terms []*model.NewTermInput) (*model.Studyset, error) {
+	title := "Untitled Studyset"
+	if len(studyset.Title) > 0 && len(studyset.Title) < 200 && validTitleRegex.MatchString(studyset.Title) {
+		title = studyset.Title
+	}
+
+	langs, err := parseStudysetLanguages(studyset)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `
+		INSERT INTO public.studysets (
+			user_id, title, private,
+			term_language, def_language, term_search_config, def_search_config
+		)
+		VALUES (
+			$1, $2, $3,
+			NULLIF($4, ''), NULLIF($5, ''),
+			COALESCE($6::regconfig, 'english'), COALESCE($7::regconfig, 'english')
+		)
+		RETURNING id, user_id, title, private, term_language, def_language, version,
+			to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+	`
+	var newStudyset model.Studyset
+	err = pgxscan.Get(
+		ctx, tx, &newStudyset, sql,
+		userID, title, studyset.Private,
+		langs.termLanguage, langs.defLanguage, langs.termSearchConfig, langs.defSearchConfig,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create studyset: %w", err)
+	}
+
+	if terms != nil && len(terms) > 0 {
+		if err := insertNewTerms(ctx, tx, *newStudyset.ID, terms); err != nil {
+			return nil, err
+		}
+
+		if err := renumberTerms(ctx, tx, *newStudyset.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	forcedPrivate, err := r.filterStudysetContent(ctx, tx, *newStudyset.ID)
+	if err != nil {
+		return nil, err
+	}
+	if forcedPrivate {
+		newStudyset.Private = &forcedPrivate
+	}
+
+	if err := recordFeedEvent(ctx, tx, *newStudyset.ID, model.FeedEventKindPublished); err != nil {
+		return nil, err
+	}
+
+	if err := recordStudysetRevision(ctx, tx, *newStudyset.ID, userID); err != nil {
+		return nil, err
+	}
+
+	return &newStudyset, nil
+}
+
+// applyTermChanges updates, adds, and deletes a studyset's terms, then does everything
+// that has to happen after its terms change (renumbering, filtering, feeds, and a revision).
+// It's shared by UpdateStudyset and accepting suggested edits, so they work the same way.
+// It returns true if the content filter forced the studyset private
+func (r *Resolver) applyTermChanges(ctx context.Context, tx pgx.Tx, userID *string, studysetID string, wasPrivate bool, terms []*model.TermInput, newTerms []*model.NewTermInput, deleteTerms []*string) (bool, error) {
+	if terms != nil && len(terms) > 0 {
+		if err := checkTermImagesOwned(ctx, tx, userID, studysetID, terms); err != nil {
+			return false, err
+		}
+
+		if err := updateTerms(ctx, tx, studysetID, terms); err != nil {
+			return false, err
+		}
+	}
+
+	if newTerms != nil && len(newTerms) > 0 {
+		if err := checkNewTermImagesOwned(ctx, tx, userID, newTerms); err != nil {
+			return false, err
+		}
+
+		if err := insertNewTerms(ctx, tx, studysetID, newTerms); err != nil {
+			return false, err
+		}
+	}
+
+	if deleteTerms != nil && len(deleteTerms) > 0 {
+		/* deleted terms go to the trash, see PurgeTerms */
+		_, err := tx.Exec(
+			ctx,
+			"UPDATE terms SET deleted_at = now(), version = version + 1 WHERE id = ANY($1) AND studyset_id = $2 AND deleted_at IS NULL",
+			deleteTerms,
+			studysetID,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to delete terms: %w", err)
+		}
+	}
+
+	if err := renumberTerms(ctx, tx, studysetID); err != nil {
+		return false, err
+	}
+
+	forcedPrivate, err := r.filterStudysetContent(ctx, tx, studysetID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := recordStudysetUpdateEvent(ctx, tx, studysetID, wasPrivate, len(terms)+len(newTerms)+len(deleteTerms)); err != nil {
+		return false, err
+	}
+
+	if err := recordStudysetRevision(ctx, tx, studysetID, userID); err != nil {
+		return false, err
+	}
+
+	return forcedPrivate, nil
+}
@@ -0,0 +1,442 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	maxSuggestionChanges       = 100
+	maxSuggestionMessageLength = 1000
+)
+
+const suggestionColumns = `id, studyset_id, user_id, message, edits, additions, removals, status,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at,
+	to_char(resolved_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as resolved_at`
+
+// suggestEdits saves edits, additions, and removals that userID suggests
+// for someone else's public studyset, for its owner to accept or reject
+func (r *Resolver) suggestEdits(ctx context.Context, userID *string, studysetID string, input model.SuggestionInput) (*model.StudysetSuggestion, error) {
+	changes := len(input.Edits) + len(input.Additions) + len(input.Removals)
+	if changes == 0 {
+		return nil, fmt.Errorf("suggestion doesn't change anything")
+	}
+	if changes > maxSuggestionChanges {
+		return nil, fmt.Errorf("suggestions can change at most %d terms", maxSuggestionChanges)
+	}
+
+	var message *string
+	if input.Message != nil {
+		trimmed := strings.TrimSpace(*input.Message)
+		if utf8.RuneCountInString(trimmed) > maxSuggestionMessageLength {
+			return nil, fmt.Errorf("message must be at most %d characters", maxSuggestionMessageLength)
+		}
+		if trimmed != "" {
+			message = &trimmed
+		}
+	}
+
+	termIDs := make([]string, 0, len(input.Edits)+len(input.Removals))
+	seen := make(map[string]bool)
+	for _, edit := range input.Edits {
+		if edit.Term == nil && edit.Def == nil && edit.TermAlternates == nil && edit.DefAlternates == nil && edit.Hint == nil {
+			return nil, fmt.Errorf("suggested edit doesn't change anything")
+		}
+		termIDs = append(termIDs, edit.TermID)
+	}
+	termIDs = append(termIDs, input.Removals...)
+	for _, id := range termIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("each term can only be changed once in a suggestion")
+		}
+		seen[id] = true
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID *string
+	var title string
+	err = tx.QueryRow(
+		ctx,
+		`SELECT user_id, title FROM public.studysets
+WHERE id = $1 AND private = false AND deleted_at IS NULL`,
+		studysetID,
+	).Scan(&ownerID, &title)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("studyset not found")
+		}
+		return nil, fmt.Errorf("failed to suggest edits: %w", err)
+	}
+	if ownerID == nil {
+		return nil, fmt.Errorf("studyset has no owner to accept suggestions")
+	}
+	if userID != nil && *ownerID == *userID {
+		return nil, fmt.Errorf("you can edit your own studyset instead of suggesting edits")
+	}
+
+	/* the versions terms are at now are what accepting checks for conflicts against */
+	versions := make(map[string]int32, len(termIDs))
+	if len(termIDs) > 0 {
+		rows, err := tx.Query(
+			ctx,
+			"SELECT id::text, version FROM terms WHERE id = ANY($1) AND studyset_id = $2 AND deleted_at IS NULL",
+			termIDs,
+			studysetID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch terms: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			var version int32
+			if err := rows.Scan(&id, &version); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to fetch terms: %w", err)
+			}
+			versions[id] = version
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch terms: %w", err)
+		}
+		if len(versions) != len(termIDs) {
+			return nil, fmt.Errorf("term not found")
+		}
+	}
+
+	edits := make([]*model.SuggestedTermEdit, 0, len(input.Edits))
+	for _, edit := range input.Edits {
+		termID := edit.TermID
+		version := versions[termID]
+		edits = append(edits, &model.SuggestedTermEdit{
+			TermID:         &termID,
+			BaseVersion:    &version,
+			Term:           edit.Term,
+			Def:            edit.Def,
+			TermAlternates: edit.TermAlternates,
+			DefAlternates:  edit.DefAlternates,
+			Hint:           edit.Hint,
+		})
+	}
+	additions := make([]*model.SuggestedNewTerm, 0, len(input.Additions))
+	for _, addition := range input.Additions {
+		additions = append(additions, &model.SuggestedNewTerm{
+			Term:           addition.Term,
+			Def:            addition.Def,
+			TermAlternates: addition.TermAlternates,
+			DefAlternates:  addition.DefAlternates,
+			Hint:           addition.Hint,
+		})
+	}
+	removals := make([]*model.SuggestedTermRemoval, 0, len(input.Removals))
+	for _, id := range input.Removals {
+		termID := id
+		version := versions[termID]
+		removals = append(removals, &model.SuggestedTermRemoval{TermID: &termID, BaseVersion: &version})
+	}
+
+	editsJSON, err := json.Marshal(edits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode suggestion: %w", err)
+	}
+	additionsJSON, err := json.Marshal(additions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode suggestion: %w", err)
+	}
+	removalsJSON, err := json.Marshal(removals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode suggestion: %w", err)
+	}
+
+	var suggestion model.StudysetSuggestion
+	err = pgxscan.Get(
+		ctx,
+		tx,
+		&suggestion,
+		`INSERT INTO studyset_suggestions (studyset_id, user_id, message, edits, additions, removals)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING `+suggestionColumns,
+		studysetID,
+		userID,
+		message,
+		editsJSON,
+		additionsJSON,
+		removalsJSON,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest edits: %w", err)
+	}
+
+	err = notify(ctx, tx, *ownerID, model.NotificationKindStudysetSuggestion, &model.SuggestionNotification{
+		StudysetID:   &studysetID,
+		Title:        &title,
+		SuggestionID: suggestion.ID,
+		UserID:       userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &suggestion, nil
+}
+
+// lockOpenSuggestion gets an open suggestion on one of ownerID's studysets, locked until tx ends
+func lockOpenSuggestion(ctx context.Context, tx pgx.Tx, suggestionID string, ownerID *string) (*model.StudysetSuggestion, error) {
+	var suggestion model.StudysetSuggestion
+	err := pgxscan.Get(
+		ctx,
+		tx,
+		&suggestion,
+		`SELECT `+suggestionColumns+`
+FROM studyset_suggestions sg
+WHERE id = $1 AND status = 'OPEN'
+	AND EXISTS (
+		SELECT 1 FROM public.studysets s
+		WHERE s.id = sg.studyset_id AND s.user_id = $2 AND s.deleted_at IS NULL
+	)
+FOR UPDATE`,
+		suggestionID,
+		ownerID,
+	)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("suggestion not found")
+		}
+		return nil, fmt.Errorf("failed to fetch suggestion: %w", err)
+	}
+	return &suggestion, nil
+}
+
+// resolveSuggestion marks a suggestion accepted or rejected and lets whoever suggested it know
+func resolveSuggestion(ctx context.Context, tx pgx.Tx, suggestion *model.StudysetSuggestion, ownerID *string, status model.SuggestionStatus, notificationKind model.NotificationKind) (*model.StudysetSuggestion, error) {
+	var resolved model.StudysetSuggestion
+	err := pgxscan.Get(
+		ctx,
+		tx,
+		&resolved,
+		`UPDATE studyset_suggestions
+SET status = $2, resolved_at = now(), resolved_by = $3, updated_at = now()
+WHERE id = $1
+RETURNING `+suggestionColumns,
+		suggestion.ID,
+		status,
+		ownerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update suggestion: %w", err)
+	}
+
+	var title string
+	err = tx.QueryRow(ctx, "SELECT title FROM public.studysets WHERE id = $1", suggestion.StudysetID).Scan(&title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch studyset: %w", err)
+	}
+	err = notify(ctx, tx, *suggestion.UserID, notificationKind, &model.SuggestionNotification{
+		StudysetID:   suggestion.StudysetID,
+		Title:        &title,
+		SuggestionID: suggestion.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolved, nil
+}
+
+// acceptSuggestion applies a suggestion to its studyset the same way UpdateStudyset would.
+// If a term it edits or removes changed since it was suggested,
+// it's a CONFLICT error (see checkStudysetVersions) and the suggestion stays open
+func (r *Resolver) acceptSuggestion(ctx context.Context, ownerID *string, suggestionID string) (*model.StudysetSuggestion, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	suggestion, err := lockOpenSuggestion(ctx, tx, suggestionID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	studysetID := *suggestion.StudysetID
+
+	versionChecks := make([]*model.TermInput, 0, len(suggestion.Edits)+len(suggestion.Removals))
+	editTermIDs := make([]string, 0, len(suggestion.Edits))
+	for _, edit := range suggestion.Edits {
+		versionChecks = append(versionChecks, &model.TermInput{ID: *edit.TermID, ExpectedVersion: edit.BaseVersion})
+		editTermIDs = append(editTermIDs, *edit.TermID)
+	}
+	deleteTerms := make([]*string, 0, len(suggestion.Removals))
+	for _, removal := range suggestion.Removals {
+		versionChecks = append(versionChecks, &model.TermInput{ID: *removal.TermID, ExpectedVersion: removal.BaseVersion})
+		deleteTerms = append(deleteTerms, removal.TermID)
+	}
+	if err := checkStudysetVersions(ctx, tx, studysetID, ownerID, nil, versionChecks); err != nil {
+		return nil, err
+	}
+
+	/* edits only change some fields, the rest stay how they are now */
+	var current []*model.Term
+	err = pgxscan.Select(
+		ctx,
+		tx,
+		&current,
+		`SELECT id, term, def, sort_order, term_image_id, def_image_id,
+	term_alternates, def_alternates, hint
+FROM terms
+WHERE id = ANY($1) AND studyset_id = $2 AND deleted_at IS NULL`,
+		editTermIDs,
+		studysetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+	currentByID := make(map[string]*model.Term, len(current))
+	for _, t := range current {
+		currentByID[*t.ID] = t
+	}
+
+	terms := make([]*model.TermInput, 0, len(suggestion.Edits))
+	for _, edit := range suggestion.Edits {
+		t := currentByID[*edit.TermID]
+		input := &model.TermInput{
+			ID:             *t.ID,
+			Term:           t.Term,
+			Def:            t.Def,
+			SortOrder:      t.SortOrder,
+			TermImageID:    t.TermImageID,
+			DefImageID:     t.DefImageID,
+			TermAlternates: t.TermAlternates,
+			DefAlternates:  t.DefAlternates,
+			Hint:           t.Hint,
+		}
+		if edit.Term != nil {
+			input.Term = edit.Term
+		}
+		if edit.Def != nil {
+			input.Def = edit.Def
+		}
+		if edit.TermAlternates != nil {
+			input.TermAlternates = edit.TermAlternates
+		}
+		if edit.DefAlternates != nil {
+			input.DefAlternates = edit.DefAlternates
+		}
+		if edit.Hint != nil {
+			input.Hint = edit.Hint
+		}
+		terms = append(terms, input)
+	}
+
+	/* added terms go at the end, renumbering cleans up their sort order */
+	var maxSortOrder int32
+	err = tx.QueryRow(
+		ctx,
+		"SELECT COALESCE(max(sort_order), -1) FROM terms WHERE studyset_id = $1 AND deleted_at IS NULL",
+		studysetID,
+	).Scan(&maxSortOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch terms: %w", err)
+	}
+	newTerms := make([]*model.NewTermInput, 0, len(suggestion.Additions))
+	for i, addition := range suggestion.Additions {
+		newTerms = append(newTerms, &model.NewTermInput{
+			Term:           addition.Term,
+			Def:            addition.Def,
+			SortOrder:      maxSortOrder + 1 + int32(i),
+			TermAlternates: addition.TermAlternates,
+			DefAlternates:  addition.DefAlternates,
+			Hint:           addition.Hint,
+		})
+	}
+
+	if _, err := touchStudyset(ctx, tx, studysetID); err != nil {
+		return nil, err
+	}
+	if _, err := r.applyTermChanges(ctx, tx, ownerID, studysetID, false, terms, newTerms, deleteTerms); err != nil {
+		return nil, err
+	}
+
+	accepted, err := resolveSuggestion(ctx, tx, suggestion, ownerID, model.SuggestionStatusAccepted, model.NotificationKindSuggestionAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return accepted, nil
+}
+
+// rejectSuggestion closes a suggestion without changing its studyset
+func (r *Resolver) rejectSuggestion(ctx context.Context, ownerID *string, suggestionID string) (*model.StudysetSuggestion, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	suggestion, err := lockOpenSuggestion(ctx, tx, suggestionID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected, err := resolveSuggestion(ctx, tx, suggestion, ownerID, model.SuggestionStatusRejected, model.NotificationKindSuggestionRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rejected, nil
+}
+
+// studysetSuggestions lists a studyset's suggestions, newest first.
+// Owners see every suggestion on their studyset, other people only see their own
+func (r *Resolver) studysetSuggestions(ctx context.Context, studyset *model.Studyset, userID *string, status *model.SuggestionStatus, limit int, offset int) ([]*model.StudysetSuggestion, error) {
+	owner := stringsEqual(studyset.UserID, userID)
+
+	var suggestions []*model.StudysetSuggestion
+	err := pgxscan.Select(
+		ctx,
+		r.DB,
+		&suggestions,
+		`SELECT `+suggestionColumns+`
+FROM studyset_suggestions
+WHERE studyset_id = $1
+	AND ($2 OR user_id = $3)
+	AND ($4::text IS NULL OR status = $4)
+ORDER BY created_at DESC
+LIMIT $5 OFFSET $6`,
+		studyset.ID,
+		owner,
+		userID,
+		status,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch suggestions: %w", err)
+	}
+	return suggestions, nil
+}
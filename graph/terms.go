@@ -0,0 +1,273 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"quizfreely/api/graph/model"
+	"quizfreely/api/language"
+	"quizfreely/api/markdown"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// placeholderRow makes one row of a multi-row VALUES list, like `($5::uuid,$6::text)`,
+// for the row at index `row` with len(casts) columns, a blank cast means no cast
+func placeholderRow(row int, casts []string) string {
+	parts := make([]string, len(casts))
+	for col, cast := range casts {
+		parts[col] = fmt.Sprintf("$%d", row*len(casts)+col+1)
+		if cast != "" {
+			parts[col] += "::" + cast
+		}
+	}
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+// renderTermText renders a term or def's markdown into its html & plain text forms
+func renderTermText(src *string) (*string, *string) {
+	if src == nil {
+		return nil, nil
+	}
+	html, plain := markdown.Render(*src)
+	return &html, &plain
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// termCasts are the types of the columns in insertNewTerms & updateTerms's VALUES lists,
+// the first column is the studyset id for inserts and the term id for updates
+var termCasts = []string{"uuid", "text", "text", "int", "uuid", "uuid", "text", "text", "text", "text", "text[]", "text[]", "text"}
+
+// updateTermCasts are termCasts with updateTerms's extra columns, for removing images
+var updateTermCasts = append(append([]string{}, termCasts...), "bool", "bool")
+
+const maxAlternates = 20
+
+// cleanAlternates trims alternate answers and drops blank & duplicate ones
+// (compared like answers in lang, see language.NormalizeAnswer),
+// it never returns nil, cause the columns are `not null default '{}'`
+func cleanAlternates(alternates []string, lang string) []string {
+	cleaned := make([]string, 0, len(alternates))
+	seen := make(map[string]bool, len(alternates))
+	for _, a := range alternates {
+		a = strings.TrimSpace(a)
+		normalized := language.NormalizeAnswer(a, lang)
+		if a == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		cleaned = append(cleaned, a)
+		if len(cleaned) >= maxAlternates {
+			break
+		}
+	}
+	return cleaned
+}
+
+// updatedAlternates is cleanAlternates for updateTerms,
+// except it's nil when alternates weren't in the input, so the saved ones are kept
+func updatedAlternates(alternates []string, lang string) []string {
+	if alternates == nil {
+		return nil
+	}
+	return cleanAlternates(alternates, lang)
+}
+
+// insertNewTerms adds terms to a studyset,
+// it's the one place terms get inserted, so every term gets rendered the same way
+// and searched with its studyset's text search configs
+func insertNewTerms(ctx context.Context, tx pgx.Tx, studysetID string, terms []*model.NewTermInput) error {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	termLanguage, defLanguage, err := answerLanguages(ctx, tx, studysetID)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, 0, len(terms)*len(termCasts))
+	placeholders := make([]string, 0, len(terms))
+
+	for i, t := range terms {
+		termHTML, termPlain := renderTermText(t.Term)
+		defHTML, defPlain := renderTermText(t.Def)
+		placeholders = append(placeholders, placeholderRow(i, termCasts))
+		values = append(
+			values,
+			studysetID, t.Term, t.Def, t.SortOrder, t.TermImageID, t.DefImageID,
+			termHTML, defHTML, termPlain, defPlain,
+			cleanAlternates(t.TermAlternates, termLanguage), cleanAlternates(t.DefAlternates, defLanguage),
+			t.Hint,
+		)
+	}
+
+	sql := fmt.Sprintf(
+		`INSERT INTO terms (
+	studyset_id, term, def, sort_order, term_image_id, def_image_id,
+	term_html, def_html, term_plain, def_plain,
+	term_alternates, def_alternates, hint,
+	term_search_config, def_search_config
+)
+SELECT v.*, s.term_search_config, s.def_search_config
+FROM (VALUES
+	%s
+) AS v(
+	studyset_id, term, def, sort_order, term_image_id, def_image_id,
+	term_html, def_html, term_plain, def_plain,
+	term_alternates, def_alternates, hint
+)
+JOIN public.studysets s ON s.id = v.studyset_id`,
+		strings.Join(placeholders, ","),
+	)
+	if _, err := tx.Exec(ctx, sql, values...); err != nil {
+		return fmt.Errorf("failed to insert terms: %w", err)
+	}
+	return nil
+}
+
+// updateTerms replaces the content of existing terms in a studyset,
+// terms that aren't in studysetID (or are in the trash) are ignored.
+// Images, alternates, and hints that aren't in the input stay the same,
+// removeTermImage & removeDefImage remove images, an empty list removes alternates,
+// and an empty hint removes it
+func updateTerms(ctx context.Context, tx pgx.Tx, studysetID string, terms []*model.TermInput) error {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	termLanguage, defLanguage, err := answerLanguages(ctx, tx, studysetID)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, 0, len(terms)*len(updateTermCasts)+1)
+	placeholders := make([]string, 0, len(terms))
+
+	for i, t := range terms {
+		removeTermImage := t.RemoveTermImage != nil && *t.RemoveTermImage
+		removeDefImage := t.RemoveDefImage != nil && *t.RemoveDefImage
+		if (removeTermImage && t.TermImageID != nil) || (removeDefImage && t.DefImageID != nil) {
+			return fmt.Errorf("can't set and remove a term's image at the same time")
+		}
+
+		termHTML, termPlain := renderTermText(t.Term)
+		defHTML, defPlain := renderTermText(t.Def)
+		placeholders = append(placeholders, placeholderRow(i, updateTermCasts))
+		values = append(
+			values,
+			t.ID, t.Term, t.Def, t.SortOrder, t.TermImageID, t.DefImageID,
+			termHTML, defHTML, termPlain, defPlain,
+			updatedAlternates(t.TermAlternates, termLanguage), updatedAlternates(t.DefAlternates, defLanguage),
+			t.Hint,
+			removeTermImage, removeDefImage,
+		)
+	}
+	values = append(values, studysetID)
+
+	sql := fmt.Sprintf(
+		`UPDATE terms AS t
+SET term = v.term, def = v.def, sort_order = v.sort_order,
+	term_image_id = CASE WHEN v.remove_term_image THEN NULL ELSE COALESCE(v.term_image_id, t.term_image_id) END,
+	def_image_id = CASE WHEN v.remove_def_image THEN NULL ELSE COALESCE(v.def_image_id, t.def_image_id) END,
+	term_html = v.term_html, def_html = v.def_html,
+	term_plain = v.term_plain, def_plain = v.def_plain,
+	term_alternates = COALESCE(v.term_alternates, t.term_alternates),
+	def_alternates = COALESCE(v.def_alternates, t.def_alternates),
+	hint = CASE WHEN v.hint IS NULL THEN t.hint ELSE NULLIF(v.hint, '') END,
+	updated_at = now(), version = t.version + 1
+FROM (VALUES
+	%s
+) AS v(
+	id, term, def, sort_order, term_image_id, def_image_id,
+	term_html, def_html, term_plain, def_plain,
+	term_alternates, def_alternates, hint,
+	remove_term_image, remove_def_image
+)
+WHERE t.id = v.id AND t.studyset_id = $%d AND t.deleted_at IS NULL`,
+		strings.Join(placeholders, ","),
+		len(values),
+	)
+	if _, err := tx.Exec(ctx, sql, values...); err != nil {
+		return fmt.Errorf("failed to update terms: %w", err)
+	}
+	return nil
+}
+
+// rerenderStudysetTerms re-renders every term in a studyset from its markdown,
+// for when terms were changed directly in SQL (like restoring a revision)
+func rerenderStudysetTerms(ctx context.Context, tx pgx.Tx, studysetID string) error {
+	rows, err := tx.Query(
+		ctx,
+		"SELECT id, term, def FROM terms WHERE studyset_id = $1 AND deleted_at IS NULL",
+		studysetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch terms to render: %w", err)
+	}
+
+	type renderedTerm struct {
+		id                                     string
+		termHTML, defHTML, termPlain, defPlain *string
+	}
+	var rendered []renderedTerm
+	for rows.Next() {
+		var id string
+		var term, def *string
+		if err := rows.Scan(&id, &term, &def); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to fetch terms to render: %w", err)
+		}
+		termHTML, termPlain := renderTermText(term)
+		defHTML, defPlain := renderTermText(def)
+		rendered = append(rendered, renderedTerm{id, termHTML, defHTML, termPlain, defPlain})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch terms to render: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, t := range rendered {
+		batch.Queue(
+			`UPDATE terms SET term_html = $2, def_html = $3, term_plain = $4, def_plain = $5
+WHERE id = $1`,
+			t.id, t.termHTML, t.defHTML, t.termPlain, t.defPlain,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to render terms: %w", err)
+	}
+	return nil
+}
+
+// renumberTerms sets a studyset's terms' sort_order to 0, 1, 2, ... keeping their order,
+// so there are no gaps or duplicates when the transaction commits
+// (see the terms_studyset_id_sort_order_excl constraint).
+// When two terms have the same sort_order, the one that was changed more recently goes first,
+// so sending one term's new sortOrder moves it in front of the term that was already there
+func renumberTerms(ctx context.Context, tx pgx.Tx, studysetID string) error {
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE terms t
+SET sort_order = numbered.new_sort_order
+FROM (
+	SELECT id,
+		row_number() OVER (ORDER BY sort_order, updated_at DESC, created_at, id) - 1 AS new_sort_order
+	FROM terms
+	WHERE studyset_id = $1 AND deleted_at IS NULL
+) numbered
+WHERE t.id = numbered.id AND t.sort_order <> numbered.new_sort_order`,
+		studysetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to renumber terms: %w", err)
+	}
+	return nil
+}
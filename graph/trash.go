@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// PurgeExpiredTrash permanently deletes studysets and terms
+// that have been in the trash for longer than retention
+func PurgeExpiredTrash(ctx context.Context, db *pgxpool.Pool, retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+
+	_, err := db.Exec(
+		ctx,
+		"DELETE FROM public.studysets WHERE deleted_at IS NOT NULL AND deleted_at < $1",
+		cutoff,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(
+		ctx,
+		"DELETE FROM terms WHERE deleted_at IS NOT NULL AND deleted_at < $1",
+		cutoff,
+	)
+	return err
+}
+
+// StartTrashPurger runs PurgeExpiredTrash once right away,
+// then every interval, until ctx is cancelled
+func StartTrashPurger(ctx context.Context, db *pgxpool.Pool, retention time.Duration, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := PurgeExpiredTrash(ctx, db, retention); err != nil {
+				log.Error().Err(err).Msg("Database error in PurgeExpiredTrash")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
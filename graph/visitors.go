@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+)
+
+type visitorContextKey struct{}
+
+// VisitorMiddleware identifies signed-out visitors by a keyed hash of their IP address & user agent,
+// so their views can be counted once a day without trusting an id the client picks.
+// The secret keeps the hashes from being matched back to IP addresses
+func VisitorMiddleware(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				/* RemoteAddr set by chi's RealIP has no port */
+				ip = r.RemoteAddr
+			}
+
+			mac := hmac.New(sha256.New, secret)
+			mac.Write([]byte(ip))
+			mac.Write([]byte{0})
+			mac.Write([]byte(r.UserAgent()))
+			visitor := hex.EncodeToString(mac.Sum(nil))
+
+			r = r.WithContext(context.WithValue(r.Context(), visitorContextKey{}, visitor))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// visitorContext returns the visitor hash from VisitorMiddleware, or "" outside of it
+func visitorContext(ctx context.Context) string {
+	visitor, _ := ctx.Value(visitorContextKey{}).(string)
+	return visitor
+}
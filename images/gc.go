@@ -0,0 +1,79 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"quizfreely/api/storage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// OrphanGracePeriod is how long an uploaded image can go unused before it's collected,
+// images are uploaded before the studyset that uses them is saved,
+// so brand new images are always "orphans" for a little while
+const OrphanGracePeriod = 24 * time.Hour
+
+// CollectOrphans deletes images that no term (including terms in the trash) or avatar uses anymore.
+// Rows are deleted first, in one statement that re-checks they're unused,
+// then their files, so a file is never deleted while a term still points at it.
+func CollectOrphans(ctx context.Context, db *pgxpool.Pool, store storage.Storage) error {
+	rows, err := db.Query(
+		ctx,
+		`DELETE FROM images i
+WHERE i.created_at < $1
+	AND NOT EXISTS (SELECT 1 FROM terms t WHERE t.term_image_id = i.id)
+	AND NOT EXISTS (SELECT 1 FROM terms t WHERE t.def_image_id = i.id)
+	AND NOT EXISTS (SELECT 1 FROM auth.users u WHERE u.avatar_image_id = i.id)
+RETURNING i.storage_key, i.thumbnail_key`,
+		time.Now().Add(-OrphanGracePeriod),
+	)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for rows.Next() {
+		var storageKey, thumbnailKey string
+		if err := rows.Scan(&storageKey, &thumbnailKey); err != nil {
+			rows.Close()
+			return err
+		}
+		keys = append(keys, storageKey, thumbnailKey)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		err := store.Delete(ctx, key)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			/* the row is already gone, so this file is just leaked,
+			log it and keep going instead of failing the rest */
+			log.Error().Err(err).Str("key", key).Msg("Storage error while deleting orphaned image")
+		}
+	}
+	return nil
+}
+
+// StartOrphanCollector runs CollectOrphans once right away,
+// then every interval, until ctx is cancelled
+func StartOrphanCollector(ctx context.Context, db *pgxpool.Pool, store storage.Storage, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := CollectOrphans(ctx, db, store); err != nil {
+				log.Error().Err(err).Msg("Database error in CollectOrphans")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
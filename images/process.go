@@ -0,0 +1,130 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	DefaultMaxUploadBytes = 10 << 20
+	// images bigger than this (in pixels) are rejected before decoding,
+	// so a tiny file can't decompress into gigabytes of memory
+	maxSourcePixels    = 50_000_000
+	MaxDimension       = 1600
+	ThumbnailDimension = 320
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type, must be jpeg, png, gif, or webp")
+	ErrTooLarge        = errors.New("image dimensions are too large")
+)
+
+// Processed is an uploaded image after it's been checked, resized, and re-encoded.
+// Re-encoding also strips EXIF & other metadata (like GPS location from phone cameras).
+type Processed struct {
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+	Image       []byte
+	Thumbnail   []byte
+}
+
+// Process sniffs data's actual content type (ignoring whatever the client claimed),
+// applies EXIF orientation, resizes it to fit in MaxDimension,
+// and makes a thumbnail that fits in ThumbnailDimension
+func Process(data []byte) (*Processed, error) {
+	sniffed := http.DetectContentType(data)
+	switch sniffed {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if config.Width <= 0 || config.Height <= 0 ||
+		config.Width*config.Height > maxSourcePixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if sniffed == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	/* images that might have transparency stay png,
+	everything else becomes jpeg cause it's way smaller for photos */
+	encode := encodeJPEG
+	processed := &Processed{ContentType: "image/jpeg", Extension: "jpg"}
+	if sniffed == "image/png" || sniffed == "image/gif" {
+		encode = encodePNG
+		processed.ContentType = "image/png"
+		processed.Extension = "png"
+	}
+
+	full := fit(img, MaxDimension)
+	processed.Width = full.Bounds().Dx()
+	processed.Height = full.Bounds().Dy()
+	if processed.Image, err = encode(full); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	if processed.Thumbnail, err = encode(fit(img, ThumbnailDimension)); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	return processed, nil
+}
+
+// fit scales img down (never up) so both sides are <= max, keeping its aspect ratio
+func fit(img image.Image, max int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= max && h <= max {
+		return img
+	}
+	if w >= h {
+		h = h * max / w
+		w = max
+	} else {
+		w = w * max / h
+		h = max
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	return buf.Bytes(), err
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	err := encoder.Encode(&buf, img)
+	return buf.Bytes(), err
+}
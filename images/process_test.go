@@ -0,0 +1,173 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeTestImage(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, testImage(w, h), nil)
+	case "png":
+		err = png.Encode(&buf, testImage(w, h))
+	case "gif":
+		err = gif.Encode(&buf, testImage(w, h), nil)
+	}
+	if err != nil {
+		t.Fatalf("failed to encode test %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// withExif adds an APP1 segment with an orientation tag and some other data right after a jpeg's SOI marker
+func withExif(jpegData []byte, orientation uint16, extra string) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, extra...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, app1...)
+	return append(out, jpegData[2:]...)
+}
+
+// pngHeader is just a png's signature and IHDR chunk, enough for image.DecodeConfig
+func pngHeader(w, h uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, w)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, h)
+	ihdr = append(ihdr, 8, 2, 0, 0, 0)
+
+	out := []byte("\x89PNG\r\n\x1a\n")
+	out = binary.BigEndian.AppendUint32(out, uint32(len(ihdr)-4))
+	out = append(out, ihdr...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name                    string
+		format                  string
+		w, h                    int
+		wantType, wantExtension string
+		wantW, wantH            int
+		wantThumbW, wantThumbH  int
+	}{
+		{"small png", "png", 100, 50, "image/png", "png", 100, 50, 100, 50},
+		{"small jpeg", "jpeg", 100, 50, "image/jpeg", "jpg", 100, 50, 100, 50},
+		{"gif becomes png", "gif", 100, 50, "image/png", "png", 100, 50, 100, 50},
+		{"wide jpeg is resized", "jpeg", 3200, 1600, "image/jpeg", "jpg", MaxDimension, 800, ThumbnailDimension, 160},
+		{"tall png is resized", "png", 1000, 4000, "image/png", "png", 400, MaxDimension, 80, ThumbnailDimension},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := Process(encodeTestImage(t, tt.format, tt.w, tt.h))
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if processed.ContentType != tt.wantType || processed.Extension != tt.wantExtension {
+				t.Errorf("Process() type = %s (.%s), want %s (.%s)",
+					processed.ContentType, processed.Extension, tt.wantType, tt.wantExtension)
+			}
+			if processed.Width != tt.wantW || processed.Height != tt.wantH {
+				t.Errorf("Process() size = %dx%d, want %dx%d", processed.Width, processed.Height, tt.wantW, tt.wantH)
+			}
+
+			/* the bytes are really what ContentType says, at the size it says */
+			for _, encoded := range []struct {
+				name string
+				data []byte
+				w, h int
+			}{
+				{"image", processed.Image, tt.wantW, tt.wantH},
+				{"thumbnail", processed.Thumbnail, tt.wantThumbW, tt.wantThumbH},
+			} {
+				config, format, err := image.DecodeConfig(bytes.NewReader(encoded.data))
+				if err != nil {
+					t.Fatalf("failed to decode processed %s: %v", encoded.name, err)
+				}
+				if "image/"+format != tt.wantType {
+					t.Errorf("processed %s is %s, want %s", encoded.name, format, tt.wantType)
+				}
+				if config.Width != encoded.w || config.Height != encoded.h {
+					t.Errorf("processed %s is %dx%d, want %dx%d", encoded.name, config.Width, config.Height, encoded.w, encoded.h)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessStripsExif(t *testing.T) {
+	const secret = "GPS 40.7128 N 74.0060 W"
+	data := withExif(encodeTestImage(t, "jpeg", 40, 20), 6, secret)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("test jpeg's orientation = %d, want 6", jpegOrientation(data))
+	}
+
+	processed, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	for _, encoded := range [][]byte{processed.Image, processed.Thumbnail} {
+		if bytes.Contains(encoded, []byte(secret)) || bytes.Contains(encoded, []byte("Exif\x00\x00")) {
+			t.Error("processed image still has its EXIF data")
+		}
+	}
+	/* orientation 6 is rotated 90°, so it's tall now */
+	if processed.Width != 20 || processed.Height != 40 {
+		t.Errorf("Process() size = %dx%d, want 20x40", processed.Width, processed.Height)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrUnsupportedType},
+		{"text", []byte("just some text, not an image"), ErrUnsupportedType},
+		{"html", []byte("<html><body><script>alert(1)</script></body></html>"), ErrUnsupportedType},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), ErrUnsupportedType},
+		{"png signature with garbage", append([]byte("\x89PNG\r\n\x1a\n"), "garbage"...), ErrUnsupportedType},
+		{"too many pixels", pngHeader(10_000, 10_000), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Process() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
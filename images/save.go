@@ -0,0 +1,58 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"quizfreely/api/graph/model"
+	"quizfreely/api/storage"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+)
+
+// Save adds a processed image's row (owned by userID) and uploads its files.
+// The row is added before uploading, so if anything after that fails,
+// the row is an unused image that CollectOrphans will clean up.
+func Save(ctx context.Context, db pgxscan.Querier, store storage.Storage, userID *string, processed *Processed) (*model.Image, error) {
+	id := uuid.New().String()
+	storageKey := "images/" + id + "." + processed.Extension
+	thumbnailKey := "images/" + id + "_thumb." + processed.Extension
+
+	var image model.Image
+	err := pgxscan.Get(
+		ctx,
+		db,
+		&image,
+		`INSERT INTO images (id, user_id, storage_key, thumbnail_key, content_type, width, height, size_bytes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, storage_key, thumbnail_key, content_type, width, height, size_bytes,
+	to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as created_at`,
+		id,
+		userID,
+		storageKey,
+		thumbnailKey,
+		processed.ContentType,
+		processed.Width,
+		processed.Height,
+		len(processed.Image),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add image: %w", err)
+	}
+
+	err = store.Put(ctx, storageKey, bytes.NewReader(processed.Image), int64(len(processed.Image)), processed.ContentType)
+	if err == nil {
+		err = store.Put(ctx, thumbnailKey, bytes.NewReader(processed.Thumbnail), int64(len(processed.Thumbnail)), processed.ContentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	url := store.URL(storageKey)
+	thumbnailURL := store.URL(thumbnailKey)
+	image.URL = &url
+	image.ThumbnailURL = &thumbnailURL
+	return &image, nil
+}
@@ -0,0 +1,256 @@
+// Package importer parses terms pasted or exported from other flashcard tools,
+// like CSV, TSV, or "term - definition" lines, into term/definition pairs.
+//
+// Each card (by default, each line) is split into a term and a definition
+// by the term/definition separator (by default, a tab).
+// Fields can be quoted so they can have separators or line breaks in them,
+// and a doubled quote inside a quoted field is a literal quote, like in CSV.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxTerms is the most terms one import can have
+const MaxTerms = 2000
+
+// MaxTextBytes is the most text one import can have
+const MaxTextBytes = 2 << 20
+
+var ErrTooLarge = errors.New("import is too large")
+var ErrSameSeparators = errors.New("term/definition separator and card separator can't be the same")
+
+// Options configures how text is split into cards and fields,
+// blank separators use the defaults and a blank Quote turns quoting off
+type Options struct {
+	TermDefSeparator string
+	CardSeparator    string
+	Quote            string
+	HeaderRow        bool
+}
+
+const (
+	DefaultTermDefSeparator = "\t"
+	DefaultCardSeparator    = "\n"
+)
+
+// CSVOptions are the options for comma separated files
+var CSVOptions = Options{
+	TermDefSeparator: ",",
+	CardSeparator:    "\n",
+	Quote:            `"`,
+}
+
+// Card is one parsed term, Line is the (1-based) line it starts on
+type Card struct {
+	Term string
+	Def  string
+	Line int
+}
+
+// Warning is a problem with one line that didn't stop the import
+type Warning struct {
+	Line    int
+	Message string
+}
+
+// Parse splits text into cards, skipping blank ones.
+// Problems with single lines are returned as warnings instead of failing the whole import,
+// so users can see what went wrong in the preview and fix it.
+func Parse(text string, opts Options) ([]Card, []Warning, error) {
+	if len(text) > MaxTextBytes {
+		return nil, nil, ErrTooLarge
+	}
+	if opts.TermDefSeparator == "" {
+		opts.TermDefSeparator = DefaultTermDefSeparator
+	}
+	if opts.CardSeparator == "" {
+		opts.CardSeparator = DefaultCardSeparator
+	}
+	opts.TermDefSeparator = unescapeSeparator(opts.TermDefSeparator)
+	opts.CardSeparator = unescapeSeparator(opts.CardSeparator)
+	if opts.TermDefSeparator == opts.CardSeparator {
+		return nil, nil, ErrSameSeparators
+	}
+	/* so "\n" separators also match windows line endings */
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	records, warnings := splitRecords(text, opts)
+
+	termCol, defCol := 0, 1
+	if opts.HeaderRow && len(records) > 0 {
+		termCol, defCol = headerColumns(records[0].fields)
+		records = records[1:]
+	}
+
+	cards := make([]Card, 0, len(records))
+	for _, rec := range records {
+		if isBlank(rec.fields) {
+			continue
+		}
+		if len(cards) >= MaxTerms {
+			warnings = append(warnings, Warning{
+				Line:    rec.line,
+				Message: fmt.Sprintf("only the first %d terms were imported", MaxTerms),
+			})
+			break
+		}
+
+		var card Card
+		card.Line = rec.line
+		if termCol < len(rec.fields) {
+			card.Term = strings.TrimSpace(rec.fields[termCol])
+		}
+		if defCol < len(rec.fields) {
+			card.Def = strings.TrimSpace(rec.fields[defCol])
+		}
+
+		switch {
+		case len(rec.fields) == 1:
+			warnings = append(warnings, Warning{Line: rec.line, Message: "no term/definition separator, imported as a term without a definition"})
+		case card.Term == "":
+			warnings = append(warnings, Warning{Line: rec.line, Message: "term is blank"})
+		case card.Def == "":
+			warnings = append(warnings, Warning{Line: rec.line, Message: "definition is blank"})
+		}
+		if !opts.HeaderRow && len(rec.fields) > 2 {
+			warnings = append(warnings, Warning{
+				Line:    rec.line,
+				Message: fmt.Sprintf("%d extra columns were ignored", len(rec.fields)-2),
+			})
+		}
+
+		cards = append(cards, card)
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Line < warnings[j].Line
+	})
+	return cards, warnings, nil
+}
+
+// unescapeSeparator lets separators typed into a text box use `\t` and `\n`
+func unescapeSeparator(sep string) string {
+	return strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(sep)
+}
+
+type record struct {
+	fields []string
+	line   int
+}
+
+func isBlank(fields []string) bool {
+	for _, f := range fields {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// headerColumns finds the term & definition columns by their header names,
+// and falls back to the first two columns
+func headerColumns(header []string) (int, int) {
+	termCol, defCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "term", "front", "question", "word":
+			if termCol < 0 {
+				termCol = i
+			}
+		case "def", "definition", "back", "answer", "meaning":
+			if defCol < 0 {
+				defCol = i
+			}
+		}
+	}
+	if termCol < 0 || defCol < 0 || termCol == defCol {
+		return 0, 1
+	}
+	return termCol, defCol
+}
+
+// splitRecords splits text into records of fields,
+// it's like encoding/csv, but separators can be any string
+// and a quote that doesn't start a field is just text
+func splitRecords(text string, opts Options) ([]record, []Warning) {
+	var records []record
+	var warnings []Warning
+
+	line := 1
+	fields := []string{}
+	recordLine := 1
+	var field strings.Builder
+
+	endField := func() {
+		fields = append(fields, field.String())
+		field.Reset()
+	}
+	endRecord := func() {
+		endField()
+		records = append(records, record{fields: fields, line: recordLine})
+		fields = []string{}
+		recordLine = line
+	}
+
+	i := 0
+	atFieldStart := true
+	for i < len(text) {
+		if opts.Quote != "" && atFieldStart && strings.HasPrefix(strings.TrimLeft(text[i:], " "), opts.Quote) {
+			quoteStart := line
+			i += len(text[i:]) - len(strings.TrimLeft(text[i:], " ")) + len(opts.Quote)
+			closed := false
+			for i < len(text) {
+				if strings.HasPrefix(text[i:], opts.Quote+opts.Quote) {
+					field.WriteString(opts.Quote)
+					i += 2 * len(opts.Quote)
+					continue
+				}
+				if strings.HasPrefix(text[i:], opts.Quote) {
+					i += len(opts.Quote)
+					closed = true
+					break
+				}
+				if text[i] == '\n' {
+					line++
+				}
+				field.WriteByte(text[i])
+				i++
+			}
+			if !closed {
+				warnings = append(warnings, Warning{Line: quoteStart, Message: "quote was never closed"})
+			}
+			atFieldStart = false
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text[i:], opts.CardSeparator):
+			line += strings.Count(opts.CardSeparator, "\n")
+			i += len(opts.CardSeparator)
+			endRecord()
+			atFieldStart = true
+		case strings.HasPrefix(text[i:], opts.TermDefSeparator):
+			line += strings.Count(opts.TermDefSeparator, "\n")
+			i += len(opts.TermDefSeparator)
+			endField()
+			atFieldStart = true
+		default:
+			if text[i] == '\n' {
+				line++
+			}
+			field.WriteByte(text[i])
+			i++
+			atFieldStart = false
+		}
+	}
+	if field.Len() > 0 || len(fields) > 0 {
+		endRecord()
+	}
+
+	return records, warnings
+}
@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		opts         Options
+		wantCards    []Card
+		wantWarnings []Warning
+	}{
+		{
+			name: "tabs & lines by default",
+			text: "cat\tgato\ndog\tperro",
+			wantCards: []Card{
+				{Term: "cat", Def: "gato", Line: 1},
+				{Term: "dog", Def: "perro", Line: 2},
+			},
+		},
+		{
+			name: "blank lines are skipped",
+			text: "\ncat\tgato\n\n  \t  \ndog\tperro\n\n",
+			wantCards: []Card{
+				{Term: "cat", Def: "gato", Line: 2},
+				{Term: "dog", Def: "perro", Line: 5},
+			},
+		},
+		{
+			name:      "windows line endings & byte order mark",
+			text:      "\ufeffcat\tgato\r\ndog\tperro\r\n",
+			wantCards: []Card{{Term: "cat", Def: "gato", Line: 1}, {Term: "dog", Def: "perro", Line: 2}},
+		},
+		{
+			name:      "custom separators",
+			text:      "cat - gato; dog - perro",
+			opts:      Options{TermDefSeparator: " - ", CardSeparator: ";"},
+			wantCards: []Card{{Term: "cat", Def: "gato", Line: 1}, {Term: "dog", Def: "perro", Line: 1}},
+		},
+		{
+			name:      "escaped separators",
+			text:      "cat\tgato\ndog\tperro",
+			opts:      Options{TermDefSeparator: `\t`, CardSeparator: `\n`},
+			wantCards: []Card{{Term: "cat", Def: "gato", Line: 1}, {Term: "dog", Def: "perro", Line: 2}},
+		},
+		{
+			name: "csv quoting",
+			text: "\"one, two\",\"a \"\"quoted\"\" def\"\n\"multi\nline\",def\nlast,one",
+			opts: CSVOptions,
+			wantCards: []Card{
+				{Term: "one, two", Def: `a "quoted" def`, Line: 1},
+				{Term: "multi\nline", Def: "def", Line: 2},
+				{Term: "last", Def: "one", Line: 4},
+			},
+		},
+		{
+			name:      "quote in the middle of a field is text",
+			text:      `5'10",tall`,
+			opts:      CSVOptions,
+			wantCards: []Card{{Term: `5'10"`, Def: "tall", Line: 1}},
+		},
+		{
+			name:         "unclosed quote",
+			text:         "\"never closed,def\nnext,line",
+			opts:         CSVOptions,
+			wantCards:    []Card{{Term: "never closed,def\nnext,line", Line: 1}},
+			wantWarnings: []Warning{{Line: 1, Message: "quote was never closed"}, {Line: 1, Message: "no term/definition separator, imported as a term without a definition"}},
+		},
+		{
+			name:      "quoting is off without a quote",
+			text:      "\"cat\"\t\"gato\"",
+			wantCards: []Card{{Term: `"cat"`, Def: `"gato"`, Line: 1}},
+		},
+		{
+			name:      "header row picks columns by name",
+			text:      "notes,definition,term\nx,gato,cat",
+			opts:      Options{TermDefSeparator: ",", HeaderRow: true},
+			wantCards: []Card{{Term: "cat", Def: "gato", Line: 2}},
+		},
+		{
+			name: "line problems are warnings",
+			text: "no separator\n\tno term\nno def\t\ntoo\tmany\tcolumns",
+			wantCards: []Card{
+				{Term: "no separator", Line: 1},
+				{Def: "no term", Line: 2},
+				{Term: "no def", Line: 3},
+				{Term: "too", Def: "many", Line: 4},
+			},
+			wantWarnings: []Warning{
+				{Line: 1, Message: "no term/definition separator, imported as a term without a definition"},
+				{Line: 2, Message: "term is blank"},
+				{Line: 3, Message: "definition is blank"},
+				{Line: 4, Message: "1 extra columns were ignored"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, warnings, err := Parse(tt.text, tt.opts)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if fmt.Sprint(cards) != fmt.Sprint(tt.wantCards) {
+				t.Errorf("Parse() cards = %+v, want %+v", cards, tt.wantCards)
+			}
+			if fmt.Sprint(warnings) != fmt.Sprint(tt.wantWarnings) {
+				t.Errorf("Parse() warnings = %+v, want %+v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	if _, _, err := Parse(strings.Repeat("a", MaxTextBytes+1), Options{}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Parse() of too much text error = %v, want %v", err, ErrTooLarge)
+	}
+	if _, _, err := Parse("a,b", Options{TermDefSeparator: `\n`}); !errors.Is(err, ErrSameSeparators) {
+		t.Errorf("Parse() with the same separators error = %v, want %v", err, ErrSameSeparators)
+	}
+
+	cards, warnings, err := Parse(strings.Repeat("term\tdef\n", MaxTerms+10), Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(cards) != MaxTerms {
+		t.Errorf("Parse() = %d cards, want %d", len(cards), MaxTerms)
+	}
+	if len(warnings) != 1 || warnings[0].Line != MaxTerms+1 {
+		t.Errorf("Parse() warnings = %v, want one on line %d", warnings, MaxTerms+1)
+	}
+}
@@ -0,0 +1,144 @@
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minDetectLetters is how many letters Detect needs before it'll guess,
+// a couple of short words isn't enough to tell languages apart
+const minDetectLetters = 12
+
+/*
+common short words that are (mostly) only common in one language,
+used to tell apart languages that share the latin alphabet
+*/
+var latinStopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "are", "was", "this", "you"},
+	"es": {"el", "la", "los", "las", "y", "es", "que", "por", "para", "una", "con", "del", "está", "muy"},
+	"fr": {"le", "les", "et", "est", "une", "des", "du", "que", "pour", "avec", "dans", "pas", "je", "vous"},
+	"de": {"der", "die", "das", "und", "ist", "ein", "eine", "nicht", "mit", "ich", "sie", "zu", "auf", "den"},
+	"pt": {"o", "os", "as", "e", "é", "um", "uma", "não", "com", "do", "da", "em", "você", "são"},
+	"it": {"il", "lo", "gli", "e", "è", "di", "che", "non", "un", "una", "per", "con", "sono", "del"},
+}
+
+/* letters that only show up in one (or mostly one) of the latin languages above */
+var latinLetterHints = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ç': "fr", 'œ': "fr", 'ê': "fr", 'è': "fr", 'à': "fr",
+	'ß': "de", 'ä': "de", 'ö': "de", 'ü': "de",
+	'ã': "pt", 'õ': "pt",
+	'ò': "it", 'ì': "it",
+}
+
+// Detect guesses the BCP-47 language of some text, like a studyset's terms joined together.
+// It's only meant to suggest a language for the user to confirm,
+// so it returns "" instead of guessing when there isn't enough text or it's unsure.
+func Detect(text string) string {
+	var letters, han, kana, hangul, cyrillic, greek, arabic, hebrew, devanagari, thai, latin int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Greek, r):
+			greek++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Hebrew, r):
+			hebrew++
+		case unicode.Is(unicode.Devanagari, r):
+			devanagari++
+		case unicode.Is(unicode.Thai, r):
+			thai++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	/* CJK text packs a lot into a few characters, so it doesn't need minDetectLetters */
+	if letters == 0 {
+		return ""
+	}
+	mostly := func(n int) bool {
+		return n*2 > letters
+	}
+	switch {
+	case kana > 0 && mostly(kana+han):
+		/* japanese mixes kanji with kana, chinese never has kana */
+		return "ja"
+	case mostly(han):
+		return "zh"
+	case mostly(hangul):
+		return "ko"
+	}
+
+	if letters < minDetectLetters {
+		return ""
+	}
+	switch {
+	case mostly(cyrillic):
+		return "ru"
+	case mostly(greek):
+		return "el"
+	case mostly(arabic):
+		return "ar"
+	case mostly(hebrew):
+		return "he"
+	case mostly(devanagari):
+		return "hi"
+	case mostly(thai):
+		return "th"
+	case mostly(latin):
+		return detectLatin(text)
+	}
+	return ""
+}
+
+// detectLatin scores latin-alphabet text by stop words & distinctive letters
+func detectLatin(text string) string {
+	scores := make(map[string]int, len(latinStopWords))
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		for lang, stopWords := range latinStopWords {
+			for _, stopWord := range stopWords {
+				if word == stopWord {
+					scores[lang] += 2
+				}
+			}
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		if lang, ok := latinLetterHints[r]; ok {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, secondScore := "", 0, 0
+	/* iterate in a fixed order so ties always go the same way */
+	for _, lang := range []string{"en", "es", "fr", "de", "pt", "it"} {
+		score := scores[lang]
+		if score > bestScore {
+			best, bestScore, secondScore = lang, score, bestScore
+		} else if score > secondScore {
+			secondScore = score
+		}
+	}
+	/* a close call isn't a guess worth suggesting */
+	if bestScore < 2 || bestScore < secondScore+2 {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,40 @@
+package language
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"no letters", "123 456 !!!", ""},
+		{"too short to guess", "hello there", ""},
+		{"english", "The cell is the basic unit of life and it is in every living thing", "en"},
+		{"spanish", "El perro y la casa son muy grandes para los niños", "es"},
+		{"french", "Le chat est dans la maison avec les enfants et je suis là", "fr"},
+		{"german", "Der Hund und die Katze sind nicht in dem großen Haus", "de"},
+		{"portuguese", "Você não é o único que está com fome, são muitas pessoas", "pt"},
+		{"italian", "Il gatto non è con gli altri, sono più di una settimana", "it"},
+		{"latin without stop words is a close call", "photosynthesis chlorophyll mitochondria", ""},
+		{"japanese", "これはペンです", "ja"},
+		{"japanese with kanji", "東京は日本の首都です", "ja"},
+		{"chinese", "我是学生", "zh"},
+		{"korean", "안녕하세요", "ko"},
+		{"russian", "Привет, как у тебя дела сегодня", "ru"},
+		{"greek", "Καλημέρα σας, τι κάνετε σήμερα", "el"},
+		{"arabic", "مرحبا كيف حالك اليوم يا صديقي", "ar"},
+		{"hebrew", "שלום מה שלומך היום חבר שלי", "he"},
+		{"hindi", "नमस्ते आप कैसे हैं आज मेरे दोस्त", "hi"},
+		{"thai", "สวัสดีครับวันนี้เป็นอย่างไรบ้าง", "th"},
+		{"short cyrillic isn't enough", "Привет", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package language handles the BCP-47 language tags on studysets' terms & definitions:
+// validating them, guessing them from term content, picking the Postgres
+// text search config for them, and language-aware answer normalization.
+package language
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	xlanguage "golang.org/x/text/language"
+	"golang.org/x/text/width"
+)
+
+// DefaultSearchConfig is the text search config used when a language is unknown or unset,
+// it's what every studyset used before they had languages
+const DefaultSearchConfig = "english"
+
+var ErrInvalidTag = errors.New("invalid language tag")
+
+// Normalize validates a BCP-47 tag and returns its canonical form, like "en-US" or "zh-Hant".
+// A blank tag returns "" with no error, cause languages are optional.
+func Normalize(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", nil
+	}
+	if len(tag) > 35 {
+		return "", ErrInvalidTag
+	}
+	t, err := xlanguage.Parse(tag)
+	if err != nil || t == xlanguage.Und {
+		return "", ErrInvalidTag
+	}
+	return t.String(), nil
+}
+
+// Base returns the lowercase ISO 639 base language of a tag, like "pt" for "pt-BR",
+// or "" if the tag is blank or invalid
+func Base(tag string) string {
+	if tag == "" {
+		return ""
+	}
+	t, err := xlanguage.Parse(tag)
+	if err != nil {
+		return ""
+	}
+	base, _ := t.Base()
+	return base.String()
+}
+
+/*
+these are the snowball configs that ship with every postgres version we support,
+languages without one use "simple", which doesn't stem or drop stop words,
+but still works for any language that separates words with spaces
+*/
+var searchConfigs = map[string]string{
+	"ar": "arabic",
+	"da": "danish",
+	"de": "german",
+	"el": "greek",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"ga": "irish",
+	"hu": "hungarian",
+	"id": "indonesian",
+	"it": "italian",
+	"lt": "lithuanian",
+	"nb": "norwegian",
+	"ne": "nepali",
+	"nl": "dutch",
+	"nn": "norwegian",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"ta": "tamil",
+	"tr": "turkish",
+}
+
+// SearchConfigs returns every text search config SearchConfig can return, sorted,
+// so a search can build one query per config instead of one per row
+func SearchConfigs() []string {
+	seen := map[string]bool{DefaultSearchConfig: true, "simple": true}
+	configs := []string{DefaultSearchConfig, "simple"}
+	for _, config := range searchConfigs {
+		if !seen[config] {
+			seen[config] = true
+			configs = append(configs, config)
+		}
+	}
+	slices.Sort(configs)
+	return configs
+}
+
+// SearchConfig returns the Postgres text search config (regconfig) for a language tag
+func SearchConfig(tag string) string {
+	if tag == "" {
+		return DefaultSearchConfig
+	}
+	if config, ok := searchConfigs[Base(tag)]; ok {
+		return config
+	}
+	return "simple"
+}
+
+/*
+subjects in search_queries look like "lang/español",
+they're named in their own language, so they're mapped by hand
+*/
+var subjectLanguages = map[string]string{
+	"lang/中文":       "zh",
+	"lang/french":   "fr",
+	"lang/ελληνικά": "el",
+	"lang/deutsch":  "de",
+	"lang/italiano": "it",
+	"lang/english":  "en",
+	"lang/日本語":      "ja",
+	"lang/한국어":      "ko",
+	"lang/latin":    "la",
+	"lang/русский":  "ru",
+	"lang/español":  "es",
+	"lang/tagalog":  "tl",
+	"lang/türkçe":   "tr",
+}
+
+// FromSubject returns the language tag for a search query subject like "lang/español",
+// or "" if the subject isn't a language
+func FromSubject(subject string) string {
+	return subjectLanguages[strings.ToLower(subject)]
+}
+
+// spaceless languages don't put spaces between words,
+// so spaces in answers don't matter
+var spaceless = map[string]bool{
+	"ja": true,
+	"zh": true,
+	"th": true,
+}
+
+// Spaceless reports whether a tag's language doesn't put spaces between words
+func Spaceless(tag string) bool {
+	return spaceless[Base(tag)]
+}
+
+// NormalizeAnswer makes answers comparable by ignoring case (with the language's casing rules,
+// like Turkish dotted/dotless i), extra whitespace, and punctuation at the start/end.
+// Full-width and half-width characters are folded for CJK languages,
+// and spaces are ignored entirely for languages that don't use them between words.
+func NormalizeAnswer(s string, tag string) string {
+	base := Base(tag)
+
+	caser := cases.Lower(xlanguage.Und)
+	if base != "" {
+		caser = cases.Lower(xlanguage.Make(base))
+	}
+	s = caser.String(s)
+
+	switch base {
+	case "ja", "zh", "ko":
+		s = width.Fold.String(s)
+	case "de":
+		s = strings.ReplaceAll(s, "ß", "ss")
+	}
+
+	if spaceless[base] {
+		s = strings.Join(strings.Fields(s), "")
+	} else {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	return strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
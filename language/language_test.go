@@ -0,0 +1,119 @@
+package language
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"   ", "", false},
+		{"en", "en", false},
+		{"en-us", "en-US", false},
+		{" pt_BR ", "pt-BR", false},
+		{"zh-hant", "zh-Hant", false},
+		{"und", "", true},
+		{"not a language", "", true},
+		{"en-US-x-abcdefgh-abcdefgh-abcdefgh-abcdefgh", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.tag)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidTag) {
+				t.Errorf("Normalize(%q) error = %v, want %v", tt.tag, err, ErrInvalidTag)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tt.tag, got, err, tt.want)
+		}
+	}
+}
+
+func TestSearchConfig(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"", DefaultSearchConfig},
+		{"en-GB", "english"},
+		{"es", "spanish"},
+		{"pt-BR", "portuguese"},
+		{"nb", "norwegian"},
+		{"ja", "simple"},
+		{"xx-invalid", "simple"},
+	}
+	for _, tt := range tests {
+		if got := SearchConfig(tt.tag); got != tt.want {
+			t.Errorf("SearchConfig(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestSearchConfigs(t *testing.T) {
+	configs := SearchConfigs()
+	if !slices.IsSorted(configs) {
+		t.Errorf("SearchConfigs() = %q, isn't sorted", configs)
+	}
+	if len(slices.Compact(slices.Clone(configs))) != len(configs) {
+		t.Errorf("SearchConfigs() = %q, has duplicates", configs)
+	}
+	for _, tag := range []string{"", "ja", "en", "de", "nn", "tr"} {
+		if !slices.Contains(configs, SearchConfig(tag)) {
+			t.Errorf("SearchConfigs() is missing %q", SearchConfig(tag))
+		}
+	}
+}
+
+func TestFromSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"lang/español", "es"},
+		{"LANG/Deutsch", "de"},
+		{"lang/日本語", "ja"},
+		{"math/algebra", ""},
+	}
+	for _, tt := range tests {
+		if got := FromSubject(tt.subject); got != tt.want {
+			t.Errorf("FromSubject(%q) = %q, want %q", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		tag    string
+		want   string
+	}{
+		{"case & spacing", "  The   Mitochondria ", "en", "the mitochondria"},
+		{"punctuation at the ends", "¿Qué tal?", "es", "qué tal"},
+		{"punctuation in the middle stays", "rock-and-roll", "en", "rock-and-roll"},
+		{"turkish dotted capital i", "İstanbul", "tr", "istanbul"},
+		{"turkish dotless capital i", "ISPARTA", "tr", "ısparta"},
+		{"english capital i", "ISPARTA", "en", "isparta"},
+		{"german sharp s", "Straße", "de", "strasse"},
+		{"sharp s is only folded for german", "Straße", "en", "straße"},
+		{"japanese full width", "ＡＢＣ　１２３", "ja", "abc123"},
+		{"japanese spaces don't matter", "東京 タワー", "ja", "東京タワー"},
+		{"korean keeps spaces", "안녕 하세요", "ko", "안녕 하세요"},
+		{"no language", "  Hello, World! ", "", "hello, world"},
+		{"only punctuation", "...", "en", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeAnswer(tt.answer, tt.tag); got != tt.want {
+				t.Errorf("NormalizeAnswer(%q, %q) = %q, want %q", tt.answer, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
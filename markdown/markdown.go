@@ -0,0 +1,204 @@
+// Package markdown renders the small Markdown dialect allowed in terms & definitions.
+//
+// Supported:
+//
+//	**bold**  *italic*  _italic_  ~subscript~  ^superscript^  `code`
+//	- bulleted lists (also `*` or `+`)
+//	1. numbered lists (also `1)`)
+//	\* backslash escapes
+//
+// Anything else, including raw HTML, is kept as (escaped) text.
+// The HTML output is only ever built from escaped text and the tags above,
+// so it's safe to render without another sanitizing pass.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	bulletRegex   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberedRegex = regexp.MustCompile(`^\s*\d{1,9}[.)]\s+(.*)$`)
+)
+
+type listKind int
+
+const (
+	noList listKind = iota
+	bulletList
+	numberedList
+)
+
+// Render returns src as sanitized HTML and as plain text with the formatting removed.
+// The plain text is what search & answer checking should use.
+func Render(src string) (htmlOut string, plain string) {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSpace(src), "\n")
+
+	var h strings.Builder
+	plainLines := make([]string, 0, len(lines))
+	currentList := noList
+	/* whether the last thing written was a line of text,
+	so the next line of text needs a <br> before it */
+	afterText := false
+
+	closeList := func() {
+		switch currentList {
+		case bulletList:
+			h.WriteString("</ul>")
+		case numberedList:
+			h.WriteString("</ol>")
+		}
+		currentList = noList
+	}
+
+	for _, line := range lines {
+		kind := noList
+		content := line
+		if m := bulletRegex.FindStringSubmatch(line); m != nil {
+			kind = bulletList
+			content = m[1]
+		} else if m := numberedRegex.FindStringSubmatch(line); m != nil {
+			kind = numberedList
+			content = m[1]
+		}
+
+		lineHTML, linePlain := renderInline(strings.TrimSpace(content))
+
+		if kind == noList {
+			closeList()
+			if afterText {
+				h.WriteString("<br>")
+			}
+			h.WriteString(lineHTML)
+			afterText = true
+		} else {
+			if kind != currentList {
+				closeList()
+				if kind == bulletList {
+					h.WriteString("<ul>")
+				} else {
+					h.WriteString("<ol>")
+				}
+				currentList = kind
+			}
+			h.WriteString("<li>")
+			h.WriteString(lineHTML)
+			h.WriteString("</li>")
+			afterText = false
+		}
+		plainLines = append(plainLines, linePlain)
+	}
+	closeList()
+
+	return h.String(), strings.TrimSpace(strings.Join(plainLines, "\n"))
+}
+
+var inlineTags = map[string]string{
+	"**": "strong",
+	"*":  "em",
+	"_":  "em",
+	"~":  "sub",
+	"^":  "sup",
+}
+
+func renderInline(s string) (string, string) {
+	var h, p strings.Builder
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			h.WriteString(html.EscapeString(s[i+1 : i+2]))
+			p.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				code := s[i+1 : i+1+end]
+				h.WriteString("<code>")
+				h.WriteString(html.EscapeString(code))
+				h.WriteString("</code>")
+				p.WriteString(code)
+				i += end + 2
+				continue
+			}
+		case c == '*' || c == '_' || c == '~' || c == '^':
+			delim := s[i : i+1]
+			if c == '*' && strings.HasPrefix(s[i:], "**") {
+				delim = "**"
+			}
+			if inner, ok := delimited(s, i, delim); ok {
+				innerHTML, innerPlain := renderInline(inner)
+				tag := inlineTags[delim]
+				h.WriteString("<" + tag + ">")
+				h.WriteString(innerHTML)
+				h.WriteString("</" + tag + ">")
+				p.WriteString(innerPlain)
+				i += len(delim)*2 + len(inner)
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		h.WriteString(html.EscapeString(s[i : i+size]))
+		p.WriteString(s[i : i+size])
+		i += size
+	}
+	return h.String(), p.String()
+}
+
+// delimited finds the text between the delimiter at s[start:] and its closing delimiter.
+// Like CommonMark, the text can't start or end with a space,
+// and `_` only counts at word boundaries so snake_case stays as-is
+func delimited(s string, start int, delim string) (string, bool) {
+	if delim == "_" && start > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(s[:start])
+		if unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+			return "", false
+		}
+	}
+	rest := s[start+len(delim):]
+	if rest == "" || rest[0] == ' ' {
+		return "", false
+	}
+	searchFrom := 0
+	for {
+		end := strings.Index(rest[searchFrom:], delim)
+		if end < 0 {
+			return "", false
+		}
+		end += searchFrom
+		/* skip escaped delimiters and, for `*`, the first half of a `**` */
+		if (end > 0 && rest[end-1] == '\\') ||
+			(delim == "*" && strings.HasPrefix(rest[end:], "**")) {
+			searchFrom = end + len(delim)
+			if delim == "*" && strings.HasPrefix(rest[end:], "**") {
+				searchFrom = end + 2
+			}
+			continue
+		}
+		if end == 0 || rest[end-1] == ' ' {
+			return "", false
+		}
+		if delim == "_" && end+1 < len(rest) {
+			next, _ := utf8.DecodeRuneInString(rest[end+1:])
+			if unicode.IsLetter(next) || unicode.IsDigit(next) {
+				searchFrom = end + 1
+				continue
+			}
+		}
+		/* in `**bold *italic***`, the italic closes first, so the bold closes at the last two */
+		if delim == "**" && strings.HasPrefix(rest[end:], "***") && strings.Count(rest[:end], "*")%2 == 1 {
+			end++
+		}
+		return rest[:end], true
+	}
+}
+
+func isPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || c == '^' || c == '`' || c == '~' || c == '+'
+}
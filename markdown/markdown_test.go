@@ -0,0 +1,47 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		wantHTML  string
+		wantPlain string
+	}{
+		{"empty", "", "", ""},
+		{"text", "photosynthesis", "photosynthesis", "photosynthesis"},
+		{"lines", "line one\r\nline two", "line one<br>line two", "line one\nline two"},
+		{"bold & italic", "**bold** and *italic* and _italic_", "<strong>bold</strong> and <em>italic</em> and <em>italic</em>", "bold and italic and italic"},
+		{"sub & sup & code", "H~2~O x^2^ `a < b`", "H<sub>2</sub>O x<sup>2</sup> <code>a &lt; b</code>", "H2O x2 a < b"},
+		{"nested emphasis", "**bold *and italic***", "<strong>bold <em>and italic</em></strong>", "bold and italic"},
+		{"bold & italic at once", "***both***", "<strong><em>both</em></strong>", "both"},
+		{"bold then italic", "**bold***italic*", "<strong>bold</strong><em>italic</em>", "bolditalic"},
+		{"italic around bold", "*italic **bold** italic*", "<em>italic <strong>bold</strong> italic</em>", "italic bold italic"},
+		{"unclosed", "**not bold", "**not bold", "**not bold"},
+		{"spaces inside delimiters", "2 * 3 * 4", "2 * 3 * 4", "2 * 3 * 4"},
+		{"snake_case", "snake_case_name", "snake_case_name", "snake_case_name"},
+		{"escapes", `\*not italic\*`, "*not italic*", "*not italic*"},
+		{"raw html", "<script>alert(1)</script>", "&lt;script&gt;alert(1)&lt;/script&gt;", "<script>alert(1)</script>"},
+		{"html inside emphasis", "**<img src=x onerror=alert(1)>**", "<strong>&lt;img src=x onerror=alert(1)&gt;</strong>", "<img src=x onerror=alert(1)>"},
+		{"attribute injection", `*" onmouseover="alert(1)*`, "<em>&#34; onmouseover=&#34;alert(1)</em>", `" onmouseover="alert(1)`},
+		{"html inside code", "`<b>`", "<code>&lt;b&gt;</code>", "<b>"},
+		{"ampersand", "salt & pepper", "salt &amp; pepper", "salt & pepper"},
+		{"bullet list", "- one\n* two\n+ three", "<ul><li>one</li><li>two</li><li>three</li></ul>", "one\ntwo\nthree"},
+		{"numbered list", "1. one\n2) two", "<ol><li>one</li><li>two</li></ol>", "one\ntwo"},
+		{"list kinds switch", "- one\n1. two", "<ul><li>one</li></ul><ol><li>two</li></ol>", "one\ntwo"},
+		{"text around a list", "fruits:\n- **apple**\nend", "fruits:<ul><li><strong>apple</strong></li></ul>end", "fruits:\napple\nend"},
+		{"dash without a space isn't a list", "-5 degrees", "-5 degrees", "-5 degrees"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHTML, gotPlain := Render(tt.src)
+			if gotHTML != tt.wantHTML {
+				t.Errorf("Render(%q) html = %q, want %q", tt.src, gotHTML, tt.wantHTML)
+			}
+			if gotPlain != tt.wantPlain {
+				t.Errorf("Render(%q) plain = %q, want %q", tt.src, gotPlain, tt.wantPlain)
+			}
+		})
+	}
+}
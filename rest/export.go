@@ -0,0 +1,336 @@
+package rest
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"quizfreely/api/anki"
+	"quizfreely/api/auth"
+	"quizfreely/api/markdown"
+	"strings"
+	"unicode"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportFormatVersion is the version of the Quizfreely JSON export format,
+// it goes up when a change would break something reading older exports
+const ExportFormatVersion = 1
+
+type exportedStudyset struct {
+	ID           *string `json:"id"`
+	Title        *string `json:"title"`
+	TermLanguage *string `json:"termLanguage"`
+	DefLanguage  *string `json:"defLanguage"`
+	UpdatedAt    *string `json:"updatedAt"`
+}
+
+type exportedTerm struct {
+	ID             *string  `json:"id"`
+	Term           *string  `json:"term"`
+	Def            *string  `json:"def"`
+	TermHTML       *string  `json:"-"`
+	DefHTML        *string  `json:"-"`
+	SortOrder      *int32   `json:"sortOrder"`
+	TermAlternates []string `json:"termAlternates"`
+	DefAlternates  []string `json:"defAlternates"`
+	Hint           *string  `json:"hint"`
+	TermImageKey   *string  `json:"-"`
+	DefImageKey    *string  `json:"-"`
+	TermImageURL   *string  `json:"termImageUrl"`
+	DefImageURL    *string  `json:"defImageUrl"`
+}
+
+type exportFormat struct {
+	extension   string
+	contentType string
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":  {"csv", "text/csv; charset=utf-8"},
+	"tsv":  {"tsv", "text/tab-separated-values; charset=utf-8"},
+	"json": {"json", "application/json; charset=utf-8"},
+	"apkg": {"apkg", anki.ContentType},
+}
+
+// exportFilename makes a studyset's title safe to use as a filename
+func exportFilename(title *string, extension string) string {
+	name := ""
+	if title != nil {
+		name = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+				return '_'
+			}
+			return r
+		}, strings.TrimSpace(*title))
+	}
+	if name == "" {
+		name = "studyset"
+	}
+	return name + "." + extension
+}
+
+// ExportStudyset downloads a studyset's terms as `?format=` csv, tsv, json, or apkg (Anki).
+// Anyone who can see the studyset in the studyset query can export it.
+// For apkg, `?reversed=true` adds a definition->term card for each term.
+func (rh *RESTHandler) ExportStudyset(w http.ResponseWriter, r *http.Request) {
+	authedUser := auth.AuthedUserContext(r.Context())
+	var userID *string
+	if authedUser != nil {
+		userID = authedUser.ID
+	}
+
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := exportFormats[formatName]
+	if !ok {
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":       "INVALID_FORMAT",
+				"statusCode": 400,
+				"message":    "format must be csv, tsv, json, or apkg",
+			},
+		})
+		return
+	}
+
+	studysetID := chi.URLParam(r, "id")
+	var studyset exportedStudyset
+	err := uuid.Validate(studysetID)
+	if err == nil {
+		err = pgxscan.Get(
+			r.Context(),
+			rh.DB,
+			&studyset,
+			`SELECT id, title, term_language, def_language,
+	to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.MSTZH:TZM') as updated_at
+FROM public.studysets
+WHERE id = $1 AND deleted_at IS NULL AND (private = false OR user_id = $2)`,
+			studysetID,
+			userID,
+		)
+	} else {
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			render.Status(r, 404)
+			render.JSON(w, r, map[string]interface{}{
+				"error": map[string]interface{}{
+					"code":       "NOT_FOUND",
+					"statusCode": 404,
+					"message":    "Studyset not found",
+				},
+			})
+			return
+		}
+		log.Error().Err(err).Msg("Database error in ExportStudyset")
+		render.Status(r, 500)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"statusCode": 500,
+				"message":    "Database error in ExportStudyset",
+			},
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set(
+		"Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{
+			"filename": exportFilename(studyset.Title, format.extension),
+		}),
+	)
+
+	/* headers are already sent once we start writing,
+	so errors from here on can only be logged */
+	switch formatName {
+	case "csv", "tsv":
+		err = rh.exportDelimited(r.Context(), w, studysetID, formatName == "tsv")
+	case "json":
+		err = rh.exportJSON(r.Context(), w, studyset)
+	case "apkg":
+		err = rh.exportAPKG(r.Context(), w, studyset, r.URL.Query().Get("reversed") == "true")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("studysetId", studysetID).Msg("Error exporting studyset")
+	}
+}
+
+// eachExportedTerm calls fn for each of a studyset's terms in order,
+// without loading them all into memory first
+func (rh *RESTHandler) eachExportedTerm(ctx context.Context, studysetID string, fn func(t *exportedTerm) error) error {
+	rows, err := rh.DB.Query(
+		ctx,
+		`SELECT t.id, t.term, t.def, t.term_html, t.def_html, t.sort_order,
+	t.term_alternates, t.def_alternates, t.hint,
+	ti.storage_key AS term_image_key, di.storage_key AS def_image_key
+FROM terms t
+LEFT JOIN images ti ON ti.id = t.term_image_id
+LEFT JOIN images di ON di.id = t.def_image_id
+WHERE t.studyset_id = $1 AND t.deleted_at IS NULL
+ORDER BY t.sort_order`,
+		studysetID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch terms: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t exportedTerm
+		if err := pgxscan.ScanRow(&t, rows); err != nil {
+			return fmt.Errorf("failed to fetch terms: %w", err)
+		}
+		if t.TermImageKey != nil {
+			url := rh.Storage.URL(*t.TermImageKey)
+			t.TermImageURL = &url
+		}
+		if t.DefImageKey != nil {
+			url := rh.Storage.URL(*t.DefImageKey)
+			t.DefImageURL = &url
+		}
+		if err := fn(&t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// spreadsheetSafe prefixes cells that spreadsheet apps would run as formulas with a `'`,
+// so opening an export of someone else's studyset can't run anything
+func spreadsheetSafe(cell string) string {
+	if cell == "" {
+		return cell
+	}
+	switch cell[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + cell
+	}
+	return cell
+}
+
+// exportDelimited writes terms & defs as their markdown source,
+// so they can be imported again without losing formatting,
+// except cells that look like formulas, which start with a `'`
+func (rh *RESTHandler) exportDelimited(ctx context.Context, w http.ResponseWriter, studysetID string, tabs bool) error {
+	cw := csv.NewWriter(w)
+	if tabs {
+		cw.Comma = '\t'
+	}
+	if err := cw.Write([]string{"term", "definition"}); err != nil {
+		return err
+	}
+	err := rh.eachExportedTerm(ctx, studysetID, func(t *exportedTerm) error {
+		return cw.Write([]string{spreadsheetSafe(stringOrEmpty(t.Term)), spreadsheetSafe(stringOrEmpty(t.Def))})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportJSON writes the versioned Quizfreely JSON format, term by term
+func (rh *RESTHandler) exportJSON(ctx context.Context, w http.ResponseWriter, studyset exportedStudyset) error {
+	studysetJSON, err := json.Marshal(studyset)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(
+		w,
+		`{"format":"quizfreely-studyset","version":%d,"studyset":%s,"terms":[`,
+		ExportFormatVersion,
+		studysetJSON,
+	)
+	if err != nil {
+		return err
+	}
+
+	first := true
+	err = rh.eachExportedTerm(ctx, *studyset.ID, func(t *exportedTerm) error {
+		if t.TermAlternates == nil {
+			t.TermAlternates = []string{}
+		}
+		if t.DefAlternates == nil {
+			t.DefAlternates = []string{}
+		}
+		termJSON, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = w.Write(termJSON)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write([]byte("]}\n"))
+	return err
+}
+
+// ankiField is a term or def as the HTML anki shows, with its image linked by url
+func ankiField(src *string, renderedHTML *string, imageURL *string) string {
+	var field string
+	switch {
+	case renderedHTML != nil:
+		field = *renderedHTML
+	case src != nil:
+		field, _ = markdown.Render(*src)
+	}
+	if imageURL != nil {
+		if field != "" {
+			field += "<br>"
+		}
+		field += `<img src="` + html.EscapeString(*imageURL) + `">`
+	}
+	return field
+}
+
+func (rh *RESTHandler) exportAPKG(ctx context.Context, w http.ResponseWriter, studyset exportedStudyset, reversed bool) error {
+	deck := anki.Deck{
+		IDSource: *studyset.ID,
+		Name:     stringOrEmpty(studyset.Title),
+		Reversed: reversed,
+	}
+	err := rh.eachExportedTerm(ctx, *studyset.ID, func(t *exportedTerm) error {
+		deck.Notes = append(deck.Notes, anki.Note{
+			Front:      ankiField(t.Term, t.TermHTML, t.TermImageURL),
+			Back:       ankiField(t.Def, t.DefHTML, t.DefImageURL),
+			GUIDSource: *t.ID,
+			Tags:       []string{"quizfreely"},
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return anki.WriteAPKG(ctx, w, deck)
+}
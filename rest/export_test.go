@@ -0,0 +1,27 @@
+package rest
+
+import "testing"
+
+func TestSpreadsheetSafe(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"", ""},
+		{"photosynthesis", "photosynthesis"},
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"1 = 1", "1 = 1"},
+		{" =1+1", " =1+1"},
+		{"**bold**", "**bold**"},
+	}
+	for _, tt := range tests {
+		if got := spreadsheetSafe(tt.cell); got != tt.want {
+			t.Errorf("spreadsheetSafe(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"quizfreely/api/auth"
+	"quizfreely/api/images"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+// UploadImage takes a multipart form with the image file in the `image` field,
+// and responds with the new image's id, to use as termImageId/defImageId
+func (rh *RESTHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	authedUser := auth.AuthedUserContext(r.Context())
+	if authedUser == nil {
+		render.Status(r, 401)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":       "NOT_AUTHED",
+				"statusCode": 401,
+				"message":    "You are not signed in, so you cannot upload images",
+			},
+		})
+		return
+	}
+
+	processed := rh.processUpload(w, r)
+	if processed == nil {
+		return
+	}
+
+	image, err := images.Save(r.Context(), rh.DB, rh.Storage, authedUser.ID, processed)
+	if err != nil {
+		log.Error().Err(err).Msg("Error saving image in UploadImage")
+		render.Status(r, 500)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"statusCode": 500,
+				"message":    "Error saving image in UploadImage",
+			},
+		})
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"error": nil,
+		"data": map[string]interface{}{
+			"image": image,
+		},
+	})
+}
+
+// processUpload checks the size of the multipart form's `image` file, then sniffs & re-encodes it
+// with images.Process. If anything's wrong, it responds with the error and returns nil
+func (rh *RESTHandler) processUpload(w http.ResponseWriter, r *http.Request) *images.Processed {
+	maxBytes := rh.MaxImageUploadBytes
+	if maxBytes <= 0 {
+		maxBytes = images.DefaultMaxUploadBytes
+	}
+	/* extra room for the rest of the multipart form */
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+(1<<20))
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			imageTooLarge(w, r)
+			return nil
+		}
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"statusCode": 400,
+				"message":    "Missing `image` file in multipart form",
+			},
+		})
+		return nil
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"statusCode": 400,
+				"message":    "Error reading uploaded image",
+			},
+		})
+		return nil
+	}
+	if int64(len(data)) > maxBytes {
+		imageTooLarge(w, r)
+		return nil
+	}
+
+	processed, err := images.Process(data)
+	if err != nil {
+		if errors.Is(err, images.ErrUnsupportedType) || errors.Is(err, images.ErrTooLarge) {
+			render.Status(r, 400)
+			render.JSON(w, r, map[string]interface{}{
+				"error": map[string]interface{}{
+					"code":       "IMAGE_INVALID",
+					"statusCode": 400,
+					"message":    err.Error(),
+				},
+			})
+			return nil
+		}
+		log.Error().Err(err).Msg("Error processing image in UploadImage")
+		render.Status(r, 400)
+		render.JSON(w, r, map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":       "IMAGE_INVALID",
+				"statusCode": 400,
+				"message":    "Error processing image",
+			},
+		})
+		return nil
+	}
+	return processed
+}
+
+func imageTooLarge(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, 413)
+	render.JSON(w, r, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":       "IMAGE_TOO_LARGE",
+			"statusCode": 413,
+			"message":    "Image file is too large",
+		},
+	})
+}
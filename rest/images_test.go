@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatalf("failed to encode test png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// uploadRequest makes a multipart form request like clients send to UploadImage,
+// with data in the field named field, claiming to be contentType
+func uploadRequest(t *testing.T, field string, data []byte, contentType string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="`+field+`"; filename="upload.png"`)
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		t.Fatalf("failed to make multipart form: %v", err)
+	}
+	part.Write(data)
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/v0/images", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	return r
+}
+
+func TestProcessUpload(t *testing.T) {
+	validPNG := testPNG(t, 40, 30)
+
+	tests := []struct {
+		name       string
+		maxBytes   int64
+		request    *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{"valid png", 0, uploadRequest(t, "image", validPNG, "image/png"), http.StatusOK, ""},
+		{"missing image field", 0, uploadRequest(t, "file", validPNG, "image/png"), http.StatusBadRequest, ""},
+		{"file over the limit", int64(len(validPNG)) - 1, uploadRequest(t, "image", validPNG, "image/png"), http.StatusRequestEntityTooLarge, "IMAGE_TOO_LARGE"},
+		{"form over the limit", 16, uploadRequest(t, "image", bytes.Repeat([]byte{0}, 2<<20), "image/png"), http.StatusRequestEntityTooLarge, "IMAGE_TOO_LARGE"},
+		{"claimed type is ignored", 0, uploadRequest(t, "image", []byte("<html><script>alert(1)</script></html>"), "image/png"), http.StatusBadRequest, "IMAGE_INVALID"},
+		{"svg", 0, uploadRequest(t, "image", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), "image/svg+xml"), http.StatusBadRequest, "IMAGE_INVALID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rh := &RESTHandler{MaxImageUploadBytes: tt.maxBytes}
+			w := httptest.NewRecorder()
+			processed := rh.processUpload(w, tt.request)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("processUpload() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if processed == nil {
+					t.Fatal("processUpload() = nil for a valid image")
+				}
+				if processed.ContentType != "image/png" || processed.Width != 40 || processed.Height != 30 {
+					t.Errorf("processUpload() = %s %dx%d, want image/png 40x30",
+						processed.ContentType, processed.Width, processed.Height)
+				}
+				if bytes.Equal(processed.Image, validPNG) {
+					t.Error("processUpload() kept the uploaded bytes instead of re-encoding them")
+				}
+				return
+			}
+			if processed != nil {
+				t.Error("processUpload() returned an image along with an error response")
+			}
+
+			var response struct {
+				Error struct {
+					Code       string
+					StatusCode int
+				}
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode error response %q: %v", w.Body.String(), err)
+			}
+			if response.Error.StatusCode != tt.wantStatus || response.Error.Code != tt.wantCode {
+				t.Errorf("processUpload() error = %+v, want %d %s", response.Error, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
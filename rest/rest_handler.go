@@ -1,8 +1,14 @@
 package rest
 
-import "github.com/jackc/pgx/v5/pgxpool"
+import (
+	"quizfreely/api/storage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
 
 type RESTHandler struct {
-	DB *pgxpool.Pool
+	DB                  *pgxpool.Pool
+	Storage             storage.Storage
+	MaxImageUploadBytes int64
 }
 
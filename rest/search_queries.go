@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"quizfreely/api/graph/model"
+	"quizfreely/api/language"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/go-chi/render"
@@ -117,6 +118,17 @@ func (rh *RESTHandler) GetSearchQueries(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	/* language subjects like "lang/español" get a language tag,
+	so clients can pass it to searchStudysets */
+	for _, sq := range searchQueries {
+		if sq.Subject == nil {
+			continue
+		}
+		if tag := language.FromSubject(*sq.Subject); tag != "" {
+			sq.Language = &tag
+		}
+	}
+
 	render.JSON(w, r, map[string]interface{}{
 		"error": nil,
 		"data": map[string]interface{}{
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"crypto/rand"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"context"
+	"strconv"
+	"time"
+	"quizfreely/api/anki"
 	"quizfreely/api/auth"
+	"quizfreely/api/contentfilter"
 	"quizfreely/api/graph"
 	"quizfreely/api/graph/loader"
+	"quizfreely/api/images"
+	"quizfreely/api/importer"
 	"quizfreely/api/rest"
+	"quizfreely/api/storage"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
@@ -20,6 +30,7 @@ import (
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 const defaultPort = "8008"
@@ -61,10 +72,105 @@ check your environment variables`,
 	}
 	defer dbPool.Close()
 
+	trashRetention := graph.DefaultTrashRetention
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); days != "" {
+		daysInt, err := strconv.Atoi(days)
+		if err != nil || daysInt < 0 {
+			log.Fatal().Msgf("TRASH_RETENTION_DAYS must be a whole number of days")
+		}
+		trashRetention = time.Duration(daysInt) * 24 * time.Hour
+	}
+	graph.StartTrashPurger(
+		context.Background(),
+		dbPool,
+		trashRetention,
+		time.Hour,
+	)
+	graph.StartStudysetStatsRollup(
+		context.Background(),
+		dbPool,
+		10*time.Minute,
+	)
+
+	basePath := os.Getenv("BASE_PATH")
+	/* os.Getenv returns "" when not set,
+	that's great cause we want to default to "" (blank)
+	cause BASE_PATH is prepended/before relative paths */
+
+	if len(basePath) > 0 && basePath[len(basePath) - 1] == '/' {
+		/* if BASE_PATH ends with "/", remove it */
+		basePath = basePath[:(len(basePath) - 1)]
+	}
+
+	store, err := storage.NewFromEnv(basePath)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Error setting up file storage")
+	}
+	images.StartOrphanCollector(
+		context.Background(),
+		dbPool,
+		store,
+		time.Hour,
+	)
+
+	var maxImageUploadBytes int64 = images.DefaultMaxUploadBytes
+	if maxBytes := os.Getenv("MAX_IMAGE_UPLOAD_BYTES"); maxBytes != "" {
+		maxBytesInt, err := strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil || maxBytesInt <= 0 {
+			log.Fatal().Msgf("MAX_IMAGE_UPLOAD_BYTES must be a positive number")
+		}
+		maxImageUploadBytes = maxBytesInt
+	}
+
+	reportAutoHideThreshold := graph.DefaultReportAutoHideThreshold
+	if threshold := os.Getenv("REPORT_AUTO_HIDE_THRESHOLD"); threshold != "" {
+		thresholdInt, err := strconv.Atoi(threshold)
+		if err != nil || thresholdInt <= 0 {
+			log.Fatal().Msgf("REPORT_AUTO_HIDE_THRESHOLD must be a positive number")
+		}
+		reportAutoHideThreshold = thresholdInt
+	}
+
+	/* no CONTENT_FILTER_DIR means public studysets aren't filtered */
+	var contentFilter *contentfilter.Filter
+	if dir := os.Getenv("CONTENT_FILTER_DIR"); dir != "" {
+		contentFilter, err = contentfilter.Load(os.DirFS(dir))
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Error loading content filter lists")
+		}
+
+		/* lists can be changed without restarting, with `kill -HUP` */
+		hangups := make(chan os.Signal, 1)
+		signal.Notify(hangups, syscall.SIGHUP)
+		go func() {
+			for range hangups {
+				if err := contentFilter.Reload(); err != nil {
+					log.Error().Err(err).Msgf("Error reloading content filter lists")
+					continue
+				}
+				log.Info().Msgf("Reloaded content filter lists")
+			}
+		}()
+	}
+
+	/* signed-out visitors' hashes change when the secret does,
+	so a random one just means views can be counted again after a restart */
+	visitorSecret := []byte(os.Getenv("VISITOR_HASH_SECRET"))
+	if len(visitorSecret) == 0 {
+		visitorSecret = make([]byte, 32)
+		if _, err := rand.Read(visitorSecret); err != nil {
+			log.Fatal().Err(err).Msgf("Error generating visitor hash secret")
+		}
+	}
+
 	router := chi.NewRouter()
 
 	authHandler := &auth.AuthHandler{DB: dbPool}
-	restHandler := &rest.RESTHandler{DB: dbPool}
+	restHandler := &rest.RESTHandler{
+		DB:                  dbPool,
+		Storage:             store,
+		MaxImageUploadBytes: maxImageUploadBytes,
+	}
 
 	router.Post(
 		"/v0/auth/sign-up",
@@ -100,24 +206,30 @@ check your environment variables`,
 		)
 	}
 
-	basePath := os.Getenv("BASE_PATH")
-	/* os.Getenv returns "" when not set,
-	that's great cause we want to default to "" (blank)
-	cause BASE_PATH is prepended/before relative paths */
-
-	if len(basePath) > 0 && basePath[len(basePath) - 1] == '/' {
-		/* if BASE_PATH ends with "/", remove it */
-		basePath = basePath[:(len(basePath) - 1)]
-	}
-
 	router.Group(func(r chi.Router) {
+		if os.Getenv("TRUST_PROXY") == "true" {
+			/* only behind a reverse proxy, otherwise anyone can pick their own IP */
+			r.Use(middleware.RealIP)
+		}
 		r.Use(authHandler.AuthMiddleware)
+		r.Use(graph.VisitorMiddleware(visitorSecret))
 
-		h := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{DB: dbPool}}))
+		h := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{
+			DB:                      dbPool,
+			Storage:                 store,
+			ReportAutoHideThreshold: reportAutoHideThreshold,
+			ContentFilter:           contentFilter,
+		}}))
 
 		h.AddTransport(transport.Options{})
 		h.AddTransport(transport.GET{})
 		h.AddTransport(transport.POST{})
+		h.AddTransport(transport.MultipartForm{
+			/* for importStudyset's & importAnkiPackage's files,
+			files bigger than MaxMemory are kept in temporary files */
+			MaxUploadSize: anki.MaxPackageBytes + (1 << 20),
+			MaxMemory:     importer.MaxTextBytes + (1 << 20),
+		})
 
 		h.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
@@ -145,8 +257,27 @@ check your environment variables`,
 			"/v0/search-queries",
 			restHandler.GetSearchQueries,
 		)
+		r.Post(
+			"/v0/images",
+			restHandler.UploadImage,
+		)
+		r.Get(
+			"/v0/studysets/{id}/export",
+			restHandler.ExportStudyset,
+		)
 	})
 
+	if localStore, ok := store.(*storage.LocalStorage); ok {
+		/* s3 serves its own files, but local storage needs us to serve them */
+		router.Handle(
+			storage.LocalFilesPath + "/*",
+			http.StripPrefix(
+				storage.LocalFilesPath,
+				localStore.Handler(),
+			),
+		)
+	}
+
 	log.Info().Msg(
 		"http://localhost:" + port + "/graphiql for GraphiQL",
 	)
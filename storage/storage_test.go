@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// testStorage is a Storage that files can be read back from, to check what Put stored
+type testStorage interface {
+	Storage
+	get(t *testing.T, key string) ([]byte, bool)
+}
+
+type testLocalStorage struct{ *LocalStorage }
+
+func (ls testLocalStorage) get(t *testing.T, key string) ([]byte, bool) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(ls.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false
+	}
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", key, err)
+	}
+	return data, true
+}
+
+type testS3Storage struct{ *S3Storage }
+
+func (s testS3Storage) get(t *testing.T, key string) ([]byte, bool) {
+	t.Helper()
+	object, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", key, err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil, false
+	}
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", key, err)
+	}
+	return data, true
+}
+
+func testRoundTrip(t *testing.T, store testStorage, keyPrefix string) {
+	ctx := context.Background()
+	key := keyPrefix + "images/round-trip.png"
+	data := []byte("\x89PNG\r\n\x1a\nnot really a png")
+
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, ok := store.get(t, key)
+	if !ok || !bytes.Equal(got, data) {
+		t.Fatalf("after Put, %s = %q (exists: %v), want %q", key, got, ok, data)
+	}
+
+	/* putting the same key again replaces it */
+	replaced := []byte("replaced")
+	if err := store.Put(ctx, key, bytes.NewReader(replaced), int64(len(replaced)), "image/png"); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	if got, _ := store.get(t, key); !bytes.Equal(got, replaced) {
+		t.Errorf("after second Put, %s = %q, want %q", key, got, replaced)
+	}
+
+	if !strings.HasSuffix(store.URL(key), "/"+key) {
+		t.Errorf("URL(%q) = %q, doesn't end with the key", key, store.URL(key))
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := store.get(t, key); ok {
+		t.Errorf("%s still exists after Delete", key)
+	}
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	ls, err := NewLocalStorage(filepath.Join(t.TempDir(), "uploads"), "/v0/files")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	testRoundTrip(t, testLocalStorage{ls}, "")
+
+	if err := ls.Delete(context.Background(), "images/missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of a missing file = %v, want %v", err, ErrNotFound)
+	}
+
+	/* no temp files are left behind */
+	entries, err := os.ReadDir(filepath.Join(ls.Dir, "images"))
+	if err != nil {
+		t.Fatalf("failed to read storage dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("storage dir has %d leftover files", len(entries))
+	}
+}
+
+func TestLocalStorageRejectsEscapingKeys(t *testing.T) {
+	dir := t.TempDir()
+	ls, err := NewLocalStorage(filepath.Join(dir, "uploads"), "/v0/files")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	for _, key := range []string{"", "/", "../outside.png", "images/../../outside.png", "images/..", ".."} {
+		if err := ls.Put(context.Background(), key, strings.NewReader("x"), 1, "image/png"); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if err := ls.Delete(context.Background(), key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete(%q) = %v, want an invalid key error", key, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside.png")); err == nil {
+		t.Error("a file was written outside of the storage dir")
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		base, key, want string
+	}{
+		{"/v0/files", "images/a.png", "/v0/files/images/a.png"},
+		{"/api/v0/files/", "images/a.png", "/api/v0/files/images/a.png"},
+		{"https://cdn.example.org/", "/images/a.png", "https://cdn.example.org/images/a.png"},
+	}
+	for _, tt := range tests {
+		if got := joinURL(tt.base, tt.key); got != tt.want {
+			t.Errorf("joinURL(%q, %q) = %q, want %q", tt.base, tt.key, got, tt.want)
+		}
+	}
+}
+
+// TestS3StorageRoundTrip needs an S3-compatible server, like MinIO:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_TEST_ENDPOINT=localhost:9000 go test ./storage
+//
+// S3_TEST_BUCKET (default "quizfreely-test") is created if it doesn't exist,
+// and S3_TEST_ACCESS_KEY & S3_TEST_SECRET_KEY default to MinIO's "minioadmin"
+func TestS3StorageRoundTrip(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+	config := S3Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("S3_TEST_REGION"),
+		Bucket:    envOr("S3_TEST_BUCKET", "quizfreely-test"),
+		AccessKey: envOr("S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretKey: envOr("S3_TEST_SECRET_KEY", "minioadmin"),
+		UseSSL:    os.Getenv("S3_TEST_USE_SSL") == "true",
+	}
+
+	ctx := context.Background()
+	if _, err := NewS3Storage(ctx, S3Config{Endpoint: endpoint}); err == nil {
+		t.Error("NewS3Storage() without a bucket succeeded")
+	}
+
+	store, err := NewS3Storage(ctx, config)
+	if err != nil {
+		/* the bucket might just not exist yet */
+		client, clientErr := minio.New(endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+			Secure: config.UseSSL,
+			Region: config.Region,
+		})
+		if clientErr != nil {
+			t.Fatalf("NewS3Storage: %v", err)
+		}
+		if err := client.MakeBucket(ctx, config.Bucket, minio.MakeBucketOptions{Region: config.Region}); err != nil {
+			t.Fatalf("failed to create test bucket: %v", err)
+		}
+		if store, err = NewS3Storage(ctx, config); err != nil {
+			t.Fatalf("NewS3Storage: %v", err)
+		}
+	}
+
+	prefix := "test-" + strings.ReplaceAll(t.Name(), "/", "-") + "/"
+	testRoundTrip(t, testS3Storage{store}, prefix)
+
+	/* deleting a missing key isn't an error on S3 */
+	if err := store.Delete(ctx, prefix+"images/missing.png"); err != nil {
+		t.Errorf("Delete() of a missing key = %v, want nil", err)
+	}
+
+	wantURL := "http://" + endpoint + "/" + config.Bucket + "/images/a.png"
+	if config.UseSSL {
+		wantURL = "https://" + endpoint + "/" + config.Bucket + "/images/a.png"
+	}
+	if got := store.URL("images/a.png"); got != wantURL {
+		t.Errorf("URL() = %q, want %q", got, wantURL)
+	}
+}
+
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func TestLocalStorageHandler(t *testing.T) {
+	ls, err := NewLocalStorage(t.TempDir(), "/v0/files")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	if err := ls.Put(context.Background(), "images/a.png", strings.NewReader("image"), 5, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(ls.Dir, "images", ".upload-123"), []byte("half"), 0o644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/images/a.png", http.StatusOK},
+		{"/images/missing.png", http.StatusNotFound},
+		{"/", http.StatusNotFound},
+		{"/images", http.StatusNotFound},
+		{"/images/", http.StatusNotFound},
+		{"/images/.upload-123", http.StatusNotFound},
+	}
+	handler := ls.Handler()
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.wantStatus)
+		}
+		if tt.wantStatus == http.StatusOK && w.Body.String() != "image" {
+			t.Errorf("GET %s = %q, want %q", tt.path, w.Body.String(), "image")
+		}
+	}
+}